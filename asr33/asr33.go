@@ -0,0 +1,144 @@
+// Package asr33 prints like the Teletype Model 33 ASR the 1969 lander ran
+// on: upper case only, 72 columns, 10 characters per second.
+//
+// Writer wraps the io.Writer of a port. It folds lower case to upper case,
+// prints ? for what the type cylinder lacks and starts a new line after
+// column 72, where the real carriage would stop and strike every further
+// character into the last column. It paces the output at 10 characters
+// per second times a speed multiplier.
+//
+// The carriage return is what makes the teletype slow at the end of a
+// line: sending CR takes a character time like any other code, but
+// getting the carriage home from column 72 takes two. The line feed FOCAL
+// sends after the CR moves the paper meanwhile; a printing character has
+// to wait until the carriage is back. Writer times a newline as CR LF but
+// passes it on unchanged.
+//
+// Paper records what the teletype printed and renders the roll as an
+// image.
+package asr33
+
+import (
+	"io"
+	"time"
+	"unicode/utf8"
+
+	"gitlab.com/jhinrichsen/lunar-lander/bitfont"
+)
+
+// Columns is the width of the carriage.
+const Columns = 72
+
+// CharTime is the time the teletype takes per character, 10 per second.
+const CharTime = time.Second / 10
+
+// returnTime is how long the carriage takes back from column col.
+func returnTime(col int) time.Duration {
+	return 2 * CharTime * time.Duration(col) / Columns
+}
+
+// Writer is the printer of a teletype in front of another writer.
+type Writer struct {
+	// Sleep waits, time.Sleep unless set.
+	Sleep func(time.Duration)
+
+	w     io.Writer
+	speed float64
+	col   int
+	t     time.Duration // teletype time since the start
+	home  time.Duration // when the carriage is back at column 0
+	slept time.Duration // the part of t slept
+	buf   []byte
+	err   error
+}
+
+// NewWriter returns a teletype printing to w. A speed of 1 prints 10
+// characters per second, 2 twice as fast; 0 does not wait at all.
+func NewWriter(w io.Writer, speed float64) *Writer {
+	return &Writer{Sleep: time.Sleep, w: w, speed: speed}
+}
+
+// Elapsed returns how long the output so far took on a real teletype,
+// whatever the speed.
+func (t *Writer) Elapsed() time.Duration {
+	return t.t
+}
+
+// Column returns the position of the carriage, 0 to Columns.
+func (t *Writer) Column() int {
+	return t.col
+}
+
+// Write prints p and waits for the teletype.
+func (t *Writer) Write(p []byte) (int, error) {
+	if t.err != nil {
+		return 0, t.err
+	}
+	for s := p; len(s) > 0; {
+		r, n := utf8.DecodeRune(s)
+		s = s[n:]
+		t.char(r)
+		t.pace()
+	}
+	t.flush()
+	if t.err != nil {
+		return 0, t.err
+	}
+	return len(p), nil
+}
+
+// char sends r.
+func (t *Writer) char(r rune) {
+	switch {
+	case r == '\n':
+		t.carriageReturn()
+		t.t += CharTime // LF
+		t.buf = append(t.buf, '\n')
+	case r == '\r':
+		t.carriageReturn()
+		t.buf = append(t.buf, '\r')
+	case r == '\a':
+		// the bell rings without moving the carriage
+		t.t += CharTime
+		t.buf = append(t.buf, '\a')
+	case r < ' ':
+		// other control codes print nothing
+		t.t += CharTime
+	default:
+		if t.col == Columns {
+			t.char('\n')
+		}
+		t.t = max(t.t, t.home) + CharTime
+		t.col++
+		t.buf = utf8.AppendRune(t.buf, bitfont.Fold(r))
+	}
+}
+
+func (t *Writer) carriageReturn() {
+	t.t += CharTime
+	t.home = max(t.home, t.t+returnTime(t.col))
+	t.col = 0
+}
+
+// pace writes what is buffered and sleeps until the output catches up with
+// the teletype.
+func (t *Writer) pace() {
+	if t.speed <= 0 {
+		return
+	}
+	d := time.Duration(float64(t.t-t.slept) / t.speed)
+	if d <= 0 {
+		return
+	}
+	t.flush()
+	t.Sleep(d)
+	t.slept = t.t
+}
+
+func (t *Writer) flush() {
+	if len(t.buf) == 0 || t.err != nil {
+		return
+	}
+	_, t.err = t.w.Write(t.buf)
+	t.buf = t.buf[:0]
+}
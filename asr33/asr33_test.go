@@ -0,0 +1,136 @@
+package asr33
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func typeOut(t *testing.T, speed float64, s ...string) (*Writer, string, []time.Duration) {
+	t.Helper()
+	var (
+		buf   bytes.Buffer
+		naps  []time.Duration
+		tty   = NewWriter(&buf, speed)
+		wrote int
+	)
+	tty.Sleep = func(d time.Duration) {
+		if buf.Len() == wrote {
+			t.Errorf("sleep %v without output", d)
+		}
+		wrote = buf.Len()
+		naps = append(naps, d)
+	}
+	for _, s := range s {
+		if _, err := tty.Write([]byte(s)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return tty, buf.String(), naps
+}
+
+func TestFold(t *testing.T) {
+	_, got, _ := typeOut(t, 0, "Hello, World\r\n", "~é\a\tK=:")
+	if want := "HELLO, WORLD\r\n??\aK=:"; got != want {
+		t.Fatalf("want %q, got %q", want, got)
+	}
+}
+
+func TestWrap(t *testing.T) {
+	tty, got, _ := typeOut(t, 0, strings.Repeat("X", 80))
+	want := strings.Repeat("X", Columns) + "\n" + strings.Repeat("X", 8)
+	if got != want {
+		t.Fatalf("want %q, got %q", want, got)
+	}
+	if tty.Column() != 8 {
+		t.Fatalf("want column 8, got %d", tty.Column())
+	}
+	// a full line followed by a newline does not wrap
+	_, got, _ = typeOut(t, 0, strings.Repeat("X", Columns)+"\nY")
+	if strings.Count(got, "\n") != 1 {
+		t.Fatalf("want one newline, got %q", got)
+	}
+}
+
+func TestElapsed(t *testing.T) {
+	ms := time.Millisecond
+	for _, tt := range []struct {
+		s    string
+		want time.Duration
+	}{
+		{"", 0},
+		{"AB", 200 * ms},
+		// CR, LF, then C waits for nothing: the carriage took 5.6 ms
+		{"AB\nC", 500 * ms},
+		// from column 72 the carriage needs 200 ms, the LF covers 100
+		{strings.Repeat("X", Columns) + "\nY", 7600 * ms},
+		// without the LF Y waits the whole 200 ms
+		{strings.Repeat("X", Columns) + "\rY", 7600 * ms},
+		{strings.Repeat("X", Columns) + "\r\r\r\rY", 7700 * ms},
+		{"\a\x00", 200 * ms},
+	} {
+		tty, _, _ := typeOut(t, 0, tt.s)
+		if got := tty.Elapsed(); got != tt.want {
+			t.Errorf("%q: want %v, got %v", tt.s, tt.want, got)
+		}
+	}
+}
+
+func TestPace(t *testing.T) {
+	s := "PERFECT LANDING !-(LUCKY)\n"
+	_, _, naps := typeOut(t, 0, s)
+	if len(naps) != 0 {
+		t.Fatalf("speed 0: want no sleep, got %v", naps)
+	}
+	tty, got, naps := typeOut(t, 4, s, s)
+	if got != s+s {
+		t.Fatalf("want %q, got %q", s+s, got)
+	}
+	var slept time.Duration
+	for _, d := range naps {
+		slept += d
+	}
+	if want := tty.Elapsed() / 4; slept != want {
+		t.Fatalf("want %v asleep, got %v", want, slept)
+	}
+	if len(naps) != 2*len(s) {
+		t.Fatalf("want a sleep per character, got %d", len(naps))
+	}
+}
+
+func TestTranscript(t *testing.T) {
+	// Transcripts do not echo the answers, so status lines run together
+	// past column 72. The teletype breaks them and changes nothing else.
+	b, err := os.ReadFile("../lunar/testdata/good.out")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tty, got, _ := typeOut(t, 0, string(b))
+	for i, l := range strings.Split(got, "\n") {
+		if len(l) > Columns {
+			t.Fatalf("line %d has %d columns", i+1, len(l))
+		}
+	}
+	if strings.ReplaceAll(got, "\n", "") != strings.ReplaceAll(string(b), "\n", "") {
+		t.Fatal("want the same characters")
+	}
+	if tty.Elapsed() < time.Duration(len(b))*CharTime {
+		t.Fatalf("want at least %d character times, got %v", len(b), tty.Elapsed())
+	}
+}
+
+type errWriter struct{}
+
+func (errWriter) Write([]byte) (int, error) { return 0, os.ErrClosed }
+
+func TestError(t *testing.T) {
+	tty := NewWriter(errWriter{}, 0)
+	if _, err := tty.Write([]byte("X")); err != os.ErrClosed {
+		t.Fatalf("want %v, got %v", os.ErrClosed, err)
+	}
+	if _, err := tty.Write([]byte("X")); err != os.ErrClosed {
+		t.Fatalf("want sticky %v, got %v", os.ErrClosed, err)
+	}
+}
@@ -0,0 +1,108 @@
+package asr33
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	"gitlab.com/jhinrichsen/lunar-lander/bitfont"
+)
+
+// Colours of the roll: yellowish teletype paper and the purple-black of a
+// worn ribbon. The ink is not opaque, so overstruck characters print
+// darker.
+var (
+	paperColor = color.RGBA{0xf4, 0xe9, 0xc8, 0xff}
+	inkColor   = color.NRGBA{0x2a, 0x22, 0x3a, 0xc0}
+)
+
+// Margin is the blank paper around the text in dots, before scaling.
+const Margin = 2 * bitfont.Advance
+
+// Paper is the roll of paper the teletype prints on. It takes the same
+// output as Writer, CR LF or LF for a new line and CR alone to overprint;
+// on a full line the carriage stays in the last column and every further
+// character strikes there.
+type Paper struct {
+	lines [][][]rune // the characters struck into each column of each line
+	col   int
+}
+
+// Write prints p on the paper.
+func (p *Paper) Write(b []byte) (int, error) {
+	if p.lines == nil {
+		p.lines = [][][]rune{nil}
+	}
+	for s := b; len(s) > 0; {
+		r, n := utf8.DecodeRune(s)
+		s = s[n:]
+		switch {
+		case r == '\n':
+			p.lines = append(p.lines, nil)
+			p.col = 0
+		case r == '\r':
+			p.col = 0
+		case r < ' ':
+		default:
+			l := &p.lines[len(p.lines)-1]
+			col := min(p.col, Columns-1)
+			for len(*l) <= col {
+				*l = append(*l, nil)
+			}
+			if r != ' ' {
+				(*l)[col] = append((*l)[col], bitfont.Fold(r))
+			}
+			p.col++
+		}
+	}
+	return len(b), nil
+}
+
+// String returns the text on the paper, the last character struck in
+// each column.
+func (p *Paper) String() string {
+	var sb strings.Builder
+	for i, l := range p.lines {
+		if i > 0 {
+			sb.WriteByte('\n')
+		}
+		line := make([]rune, len(l))
+		for j, c := range l {
+			line[j] = ' '
+			if len(c) > 0 {
+				line[j] = c[len(c)-1]
+			}
+		}
+		sb.WriteString(strings.TrimRight(string(line), " "))
+	}
+	return sb.String()
+}
+
+// Image renders the roll, all 72 columns wide and as long as the session,
+// each dot scale x scale pixels.
+func (p *Paper) Image(scale int) *image.RGBA {
+	scale = max(scale, 1)
+	w := (2*Margin + Columns*bitfont.Advance) * scale
+	h := (2*Margin + max(len(p.lines), 1)*bitfont.LineHeight) * scale
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	draw.Draw(img, img.Bounds(), image.NewUniform(paperColor), image.Point{}, draw.Src)
+	for i, l := range p.lines {
+		y := (Margin + i*bitfont.LineHeight) * scale
+		for j, c := range l {
+			x := (Margin + j*bitfont.Advance) * scale
+			for _, r := range c {
+				bitfont.Draw(img, x, y, string(r), inkColor, scale)
+			}
+		}
+	}
+	return img
+}
+
+// PNG writes the roll as a PNG image.
+func (p *Paper) PNG(w io.Writer, scale int) error {
+	return png.Encode(w, p.Image(scale))
+}
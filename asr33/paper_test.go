@@ -0,0 +1,60 @@
+package asr33
+
+import (
+	"bytes"
+	"fmt"
+	"image/png"
+	"strings"
+	"testing"
+
+	"gitlab.com/jhinrichsen/lunar-lander/bitfont"
+)
+
+func TestPaper(t *testing.T) {
+	var p Paper
+	fmt.Fprint(&p, "k=:10\r\nabc\r___\n", strings.Repeat("X", 75))
+	want := "K=:10\n___\n" + strings.Repeat("X", Columns)
+	if got := p.String(); got != want {
+		t.Fatalf("want %q, got %q", want, got)
+	}
+	// the overflow strikes the last column
+	if got := len(p.lines[2][Columns-1]); got != 4 {
+		t.Fatalf("want 4 strikes in column 72, got %d", got)
+	}
+}
+
+func TestPaperImage(t *testing.T) {
+	var p Paper
+	fmt.Fprint(&p, "A\nB\n")
+	img := p.Image(2)
+	w := (2*Margin + Columns*bitfont.Advance) * 2
+	h := (2*Margin + 3*bitfont.LineHeight) * 2
+	if b := img.Bounds(); b.Dx() != w || b.Dy() != h {
+		t.Fatalf("want %dx%d, got %v", w, h, b)
+	}
+	// top of the A at column 2 of its cell
+	if img.RGBAAt(0, 0) != paperColor {
+		t.Fatal("want paper in the margin")
+	}
+	if img.RGBAAt((Margin+2)*2, Margin*2) == paperColor {
+		t.Fatal("want ink on top of the A")
+	}
+
+	// overprinting darkens
+	var q Paper
+	fmt.Fprint(&q, "I\rI")
+	var r Paper
+	fmt.Fprint(&r, "I")
+	x, y := Margin+2, Margin
+	if a, b := r.Image(1).RGBAAt(x, y), q.Image(1).RGBAAt(x, y); b.R >= a.R {
+		t.Fatalf("want overstrike %v darker than %v", b, a)
+	}
+
+	var buf bytes.Buffer
+	if err := p.PNG(&buf, 1); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := png.Decode(&buf); err != nil {
+		t.Fatal(err)
+	}
+}
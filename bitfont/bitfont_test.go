@@ -0,0 +1,38 @@
+package bitfont
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestFold(t *testing.T) {
+	for in, want := range map[rune]rune{'a': 'A', 'Z': 'Z', '_': '_', '~': '?', '\t': '?', 'é': '?'} {
+		if got := Fold(in); got != want {
+			t.Errorf("Fold(%q): want %q, got %q", in, want, got)
+		}
+	}
+}
+
+func TestBounds(t *testing.T) {
+	if got, want := Bounds("K=:", 2), (image.Point{X: 34, Y: 14}); got != want {
+		t.Errorf("want %v, got %v", want, got)
+	}
+	if got, want := Bounds("AB\nC", 1), (image.Point{X: 11, Y: 17}); got != want {
+		t.Errorf("want %v, got %v", want, got)
+	}
+}
+
+func TestDraw(t *testing.T) {
+	img := image.NewGray(image.Rect(0, 0, Width, Height))
+	Draw(img, 0, 0, "l", color.White, 1)
+	// L is a vertical bar on the left with a foot.
+	for y := range Height {
+		if img.GrayAt(0, y).Y != 0xff {
+			t.Errorf("want dot at 0,%d", y)
+		}
+	}
+	if img.GrayAt(4, 6).Y != 0xff || img.GrayAt(4, 0).Y != 0 {
+		t.Error("want foot of L only at the bottom")
+	}
+}
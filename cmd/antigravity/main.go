@@ -6,8 +6,9 @@ import (
 	"io"
 	"math"
 	"os"
-	"strconv"
-	"strings"
+
+	"gitlab.com/jhinrichsen/lunar-lander/focal"
+	"gitlab.com/jhinrichsen/lunar-lander/typefmt"
 )
 
 // Global State Variables mimicking FOCAL vars
@@ -26,43 +27,37 @@ var (
 	J float64 // Temp Velocity
 	Q float64 // Temp var (S*K/M)
 	W float64 // Impact Velocity (MPH)
+	P float64 // Answer to TRY AGAIN
+	X float64 // Dot counter of NOT POSSIBLE
 )
 
+// vars returns the FOCAL variables, in which ASK evaluates answers.
+func vars() map[string]float64 {
+	return map[string]float64{
+		"A": A, "V": V, "M": M, "N": N, "G": G, "Z": Z, "K": K, "L": L,
+		"S": S, "T": T, "I": I, "J": J, "Q": Q, "W": W, "P": P, "X": X,
+	}
+}
+
 func main() {
 	run(os.Stdin, os.Stdout)
 }
 
 func run(in io.Reader, out io.Writer) {
-	var ans string
-
 	// Wrap in bufio scanner for line-oriented input
 	scanner := bufio.NewScanner(in)
+	// TYPE numbers with FOCAL's sticky format
+	tty := typefmt.NewWriter(out)
+	// FRAN() in answers
+	random := focal.NewRandom()
 
-	// Helper to read K
+	// Helper to read K. FOCAL's ASK evaluates the answer as an expression
+	// in the program's variables, so K*2 or FSQT(K) are fine too.
 	readK := func() bool {
 		if !scanner.Scan() {
 			return false
 		}
-		text := strings.TrimSpace(scanner.Text())
-		if text == "" {
-			// FOCAL ASK treats empty line as 0 (or previous? In this sim context, seemingly 0 or updated manual fuel rate is 0)
-			// Based on repro logs where Fuel didn't drop, K became 0 (or stayed 0).
-			K = 0
-			return true
-		}
-		val, err := strconv.ParseFloat(text, 64)
-		if err != nil {
-			// If parse error, FOCAL typically reprompts.
-			// Ideally we replicate that, but for now let's assume valid float or 0 to pass fuzzing.
-			// Or log debug?
-			// Let's treat valid garbage as 0 to avoid crash loop during fuzzing?
-			// No, better to match FOCAL. Does FOCAL crash? No, it asks again.
-			// Since we don't have infinite logic for invalid syntax repl, let's just error/return or set 0.
-			// Let's set 0 for robustness.
-			K = 0
-		} else {
-			K = val
-		}
+		K = focal.Answer(scanner.Text(), vars(), random)
 		return true
 	}
 
@@ -87,7 +82,7 @@ func run(in io.Reader, out io.Writer) {
 
 	// 02.10 Group
 Label2_10:
-	printStatus(out)
+	printStatus(tty)
 
 	// 02.70 Input Loop
 Label2_70:
@@ -96,6 +91,8 @@ Label2_70:
 		return
 	}
 	T = 10
+	// 02.70 T %7.02 - sticky, used by the landing report
+	tty.Type(typefmt.Format{Width: 7, Digits: 2})
 
 	// Logic for I (200-K)2.72;I (8-K)3.1,3.1;I (K)2.72,3.1
 	// If K > 200 -> Error
@@ -117,7 +114,7 @@ Label2_70:
 
 Label2_72:
 	fmt.Fprintln(out, "NOT POSSIBLE")
-	for x := 1; x <= 51; x++ {
+	for X = 1; X <= 51; X++ {
 		fmt.Fprint(out, ".")
 	}
 	fmt.Fprintln(out)
@@ -164,7 +161,7 @@ Label3_80:
 
 Label4_10: // Fuel Out
 	// 04.10 T "FUEL OUT AT",L," SECS"!
-	fmt.Fprintf(out, "FUEL OUT AT %6.2f SECS\n", L) // FOCAL default formatting?
+	tty.Type("FUEL OUT AT", L, " SECS\n")
 	// 04.40 S S=(FSQT(V*V+2*A*G)-V)/G;S V=V+G*S;S L=L+S
 	S = (math.Sqrt(V*V+2*A*G) - V) / G
 	V = V + G*S
@@ -172,11 +169,10 @@ Label4_10: // Fuel Out
 
 Label5_10: // Landing sequence
 	// 05.10 T "ON THE MOON AT",L," SECS"!;S W=3600*V
-	fmt.Fprintf(out, "ON THE MOON AT %8.2f SECS\n", L)
+	tty.Type("ON THE MOON AT", L, " SECS\n")
 	W = 3600 * V
 	// 05.20 T "IMPACT VELOCITY OF",W,"M.P.H."!,"FUEL LEFT:"M-N," LBS"!
-	fmt.Fprintf(out, "IMPACT VELOCITY OF %8.2fM.P.H.\n", W)
-	fmt.Fprintf(out, "FUEL LEFT:  %8.2f LBS\n", M-N)
+	tty.Type("IMPACT VELOCITY OF", W, "M.P.H.\n", "FUEL LEFT:", M-N, " LBS\n")
 
 	// 05.40 I (1-W)5.5,5.5;T "PERFECT LANDING !-(LUCKY)"!;G 5.9
 	if 1-W < 0 { // W > 1
@@ -222,22 +218,27 @@ Label5_82:
 	fmt.Fprintln(out, "SORRY,BUT THERE WERE NO SURVIVORS-YOU BLEW IT!")
 	fmt.Fprint(out, "IN ")
 	// 05.83 T "FACT YOU BLASTED A NEW LUNAR CRATER",W*.277777," FT.DEEP"!
-	fmt.Fprintf(out, "FACT YOU BLASTED A NEW LUNAR CRATER %8.2f FT.DEEP\n", W*0.277777)
+	tty.Type("FACT YOU BLASTED A NEW LUNAR CRATER", W*0.277777, " FT.DEEP\n")
 
 Label5_90:
 	// 05.90 T !!!!"TRY AGAIN?"!
 	fmt.Fprintln(out, "\n\n\n\nTRY AGAIN?")
 	// 05.92 A "(ANS. YES OR NO)"P;I (P-0NO)5.94,5.98
-	// Since we handle text input for "YES" or "NO", this FOCAL numeric hack for strings needs translation.
+	// ASK reads letters as digits, so YES and NO compare equal to 0YES and 0NO.
+Label5_92:
 	fmt.Fprint(out, "(ANS. YES OR NO):")
 
 	if !scanner.Scan() {
 		return
 	}
-	ans = strings.TrimSpace(scanner.Text())
+	P = focal.Answer(scanner.Text(), vars(), random)
 
-	if ans == "YES" || ans == "yes" {
-		goto Label1_50 // Restart (Technically 1.2, but 1.5 is init)
+	// 05.94 I (P-0YES)5.92,1.2,5.92
+	if P != no {
+		if P == yes {
+			goto Label1_50 // Restart (Technically 1.2, but 1.5 is init)
+		}
+		goto Label5_92
 	}
 	// 05.98 T "CONTROL OUT"!!!;Q
 	fmt.Fprintln(out, "CONTROL OUT")
@@ -295,6 +296,12 @@ Label8_10:
 	goto Label8_10
 }
 
+// yes and no are the FOCAL constants 0YES and 0NO.
+var (
+	yes, _ = focal.ReadNumber("0YES")
+	no, _  = focal.ReadNumber("0NO")
+)
+
 func doGroup6() {
 	// 06.10 S L=L+S;S T=T-S;S M=M-S*K;S A=I;S V=J
 	L = L + S
@@ -313,18 +320,15 @@ func doGroup9() {
 	I = A - G*S*S/2 - V*S + Z*S*(Q/2+math.Pow(Q, 2)/6+math.Pow(Q, 3)/12+math.Pow(Q, 4)/20+math.Pow(Q, 5)/30)
 }
 
-func printStatus(out io.Writer) {
+func printStatus(tty *typefmt.Writer) {
 	// 02.10 T "    ",%3,L,"       ",FITR(A),"  ",%4,5280*(A-FITR(A))
 	// 02.20 T %6.02,"       ",3600*V,"    ",%6.01,M-N,"      K=";A K;S T=10
-	// FOCAL Formatting is tricky.
-	// L is Seconds (Integer approx usually?)
 	// A: FITR(A) is integer part (Miles). 5280*(A-FITR(A)) is Feet.
-	miles := math.Floor(A)
+	miles := math.Trunc(A)
 	feet := 5280 * (A - miles)
 
-	// Line 02.10: "    " %3 L "       " FITR(A) "  " %4 5280*...
-	// %3 means 3 digits?
-	// Line 02.20: %6.02 (Width 6, 2 decimal?) "       " 3600*V "    " %6.01 M-NK
-
-	fmt.Fprintf(out, "%9.0f         %3.0f    %4.0f         %6.2f      %6.1f      ", L, miles, feet, 3600*V, M-N)
+	tty.Type("    ", typefmt.Format{Width: 3}, L, "       ", miles,
+		"  ", typefmt.Format{Width: 4}, feet)
+	tty.Type(typefmt.Format{Width: 6, Digits: 2}, "       ", 3600*V,
+		"    ", typefmt.Format{Width: 6, Digits: 1}, M-N, "      ")
 }
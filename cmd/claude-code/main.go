@@ -5,11 +5,15 @@ package main
 
 import (
 	"bufio"
+	"flag"
 	"fmt"
 	"io"
 	"math"
 	"os"
-	"strings"
+
+	"gitlab.com/jhinrichsen/lunar-lander/focal"
+	"gitlab.com/jhinrichsen/lunar-lander/trace"
+	"gitlab.com/jhinrichsen/lunar-lander/typefmt"
 )
 
 // Sim holds the simulation state
@@ -27,9 +31,17 @@ type Sim struct {
 	I float64 // New altitude (from subroutine 9)
 	J float64 // New velocity (from subroutine 9)
 	W float64 // Velocity in MPH (for landing)
+	Q float64 // S*K/M (from subroutine 9)
+	X float64 // Dot counter of the NOT POSSIBLE message
+	P float64 // Answer to TRY AGAIN
+
+	// Trace, if set, records the variables after each FOCAL line.
+	Trace *trace.Recorder
 
 	in  *bufio.Scanner
 	out io.Writer
+	tty *typefmt.Writer // TYPE with FOCAL's sticky number format
+	rnd func() float64  // FRAN() in answers
 }
 
 // NewSim creates a new simulation with the given input/output
@@ -37,11 +49,40 @@ func NewSim(in io.Reader, out io.Writer) *Sim {
 	return &Sim{
 		in:  bufio.NewScanner(in),
 		out: out,
+		tty: typefmt.NewWriter(out),
+		rnd: focal.NewRandom(),
 	}
 }
 
+// vars returns the FOCAL variables
+func (s *Sim) vars() map[string]float64 {
+	return map[string]float64{
+		"A": s.A, "V": s.V, "M": s.M, "N": s.N, "G": s.G, "Z": s.Z, "L": s.L,
+		"T": s.T, "K": s.K, "S": s.S, "I": s.I, "J": s.J, "W": s.W,
+		"Q": s.Q, "X": s.X, "P": s.P,
+	}
+}
+
+// answer evaluates an ASK answer like FOCAL: an expression that may use
+// the variables, YES and NO read as the numbers 0YES and 0NO
+func (s *Sim) answer() float64 {
+	return focal.Answer(s.in.Text(), s.vars(), s.rnd)
+}
+
+// trace records the variables for FOCAL line n
+func (s *Sim) trace(n focal.Num, what string) {
+	if s.Trace == nil {
+		return
+	}
+	vars := s.vars()
+	// the dots of 02.72 are not traced one by one
+	delete(vars, "X")
+	s.Trace.Record(n, what, vars)
+}
+
 // Run executes the simulation
 func (s *Sim) Run() {
+	s.briefing()
 	for {
 		s.intro()
 		s.mainLoop()
@@ -51,15 +92,23 @@ func (s *Sim) Run() {
 	}
 }
 
-// intro prints the introduction (lines 01.04-01.40)
-func (s *Sim) intro() {
+// briefing prints the instructions (lines 01.04-01.11)
+func (s *Sim) briefing() {
 	fmt.Fprintln(s.out, "CONTROL CALLING LUNAR MODULE. MANUAL CONTROL IS NECESSARY")
 	fmt.Fprintln(s.out, "YOU MAY RESET FUEL RATE K EACH 10 SECS TO 0 OR ANY VALUE")
 	fmt.Fprintln(s.out, "BETWEEN 8 & 200 LBS/SEC. YOU'VE 16000 LBS FUEL. ESTIMATED")
 	fmt.Fprintln(s.out, "FREE FALL IMPACT TIME-120 SECS. CAPSULE WEIGHT-32500 LBS")
+}
+
+// intro starts a descent (lines 01.20-01.50); a YES to TRY AGAIN
+// continues here
+func (s *Sim) intro() {
 	fmt.Fprintln(s.out, "FIRST RADAR CHECK COMING UP")
 	fmt.Fprintln(s.out)
 	fmt.Fprintln(s.out)
+	// Line 01.20: E erases all variables
+	*s = Sim{Trace: s.Trace, in: s.in, out: s.out, tty: s.tty, rnd: s.rnd}
+	s.Trace.Erase(120)
 	fmt.Fprintln(s.out, "COMMENCE LANDING PROCEDURE")
 	fmt.Fprintln(s.out, "TIME,SECS   ALTITUDE,MILES+FEET   VELOCITY,MPH   FUEL,LBS   FUEL RATE")
 
@@ -71,6 +120,7 @@ func (s *Sim) intro() {
 	s.G = 0.001
 	s.Z = 1.8
 	s.L = 0
+	s.trace(150, "intro")
 }
 
 // fitr returns the integer part of x (FOCAL's FITR function)
@@ -85,12 +135,14 @@ func (s *Sim) printStatus() {
 	velocity := 3600 * s.V
 	fuel := s.M - s.N
 
-	// FOCAL format from lines 02.10-02.20, empirically matched to retrofocal output:
-	// "        0         120       0         3600.00      16000.0      K=:"
-	// Time, Miles, Feet are fixed width
-	// Velocity uses 9 spaces + %6.02 format, Fuel uses 6 spaces + %6.01 format
-	fmt.Fprintf(s.out, "%9.0f%12.0f%8.0f         %6.2f      %6.1f      K=:",
-		s.L, miles, feet, velocity, fuel)
+	// 02.10 T "    ",%3,L,"       ",FITR(A),"  ",%4,5280*(A-FITR(A))
+	// 02.20 T %6.02,"       ",3600*V,"    ",%6.01,M-N,"      K=";A K
+	s.tty.Type("    ", typefmt.Format{Width: 3}, s.L, "       ", miles,
+		"  ", typefmt.Format{Width: 4}, feet)
+	s.tty.Type(typefmt.Format{Width: 6, Digits: 2}, "       ", velocity,
+		"    ", typefmt.Format{Width: 6, Digits: 1}, fuel, "      K=:")
+	// 02.70 T %7.02 sets the format of the times and velocities at landing
+	s.tty.Type(typefmt.Format{Width: 7, Digits: 2})
 }
 
 // askK prompts for and validates the fuel rate K (lines 02.70-02.73)
@@ -99,15 +151,9 @@ func (s *Sim) askK() bool {
 		if !s.in.Scan() {
 			return false
 		}
-		line := strings.TrimSpace(s.in.Text())
-		var k float64
-		_, err := fmt.Sscanf(line, "%f", &k)
-		if err != nil {
-			// FOCAL interprets non-numeric input as 0
-			k = 0
-		}
-		s.K = k
+		s.K = s.answer()
 		s.T = 10
+		s.trace(220, "askK")
 
 		// Line 02.70: Validate K
 		// I (200-K)2.72 - if K > 200, goto 2.72
@@ -133,7 +179,8 @@ func (s *Sim) askK() bool {
 // printNotPossible prints the "NOT POSSIBLE" message with dots (line 02.72-02.73)
 func (s *Sim) printNotPossible() {
 	fmt.Fprint(s.out, "NOT POSSIBLE")
-	for x := 1; x <= 51; x++ {
+	// F X=1,51;T "." leaves X one past the end
+	for s.X = 1; s.X <= 51; s.X++ {
 		fmt.Fprint(s.out, ".")
 	}
 	fmt.Fprint(s.out, "K=:")
@@ -141,15 +188,18 @@ func (s *Sim) printNotPossible() {
 
 // subroutine9 calculates new velocity (J) and altitude (I) (lines 09.10-09.40)
 func (s *Sim) subroutine9() {
-	Q := s.S * s.K / s.M
+	s.Q = s.S * s.K / s.M
+	Q := s.Q
 	Q2 := Q * Q
 	Q3 := Q2 * Q
 	Q4 := Q3 * Q
 	Q5 := Q4 * Q
 	// J = V + G*S + Z*(-Q - Q^2/2 - Q^3/3 - Q^4/4 - Q^5/5)
 	s.J = s.V + s.G*s.S + s.Z*(-Q-Q2/2-Q3/3-Q4/4-Q5/5)
+	s.trace(910, "subroutine9")
 	// I = A - G*S*S/2 - V*S + Z*S*(Q/2 + Q^2/6 + Q^3/12 + Q^4/20 + Q^5/30)
 	s.I = s.A - s.G*s.S*s.S/2 - s.V*s.S + s.Z*s.S*(Q/2+Q2/6+Q3/12+Q4/20+Q5/30)
+	s.trace(940, "subroutine9")
 }
 
 // subroutine6 updates state variables (line 06.10)
@@ -159,6 +209,7 @@ func (s *Sim) subroutine6() {
 	s.M = s.M - s.S*s.K
 	s.A = s.I
 	s.V = s.J
+	s.trace(610, "subroutine6")
 }
 
 // State constants for control flow
@@ -198,10 +249,12 @@ func (s *Sim) mainLoop() {
 			}
 
 			s.S = s.T
+			s.trace(310, "stateLoop31")
 
 			// Line 03.40: Check if enough fuel for burn
 			if s.N+s.S*s.K > s.M {
 				s.S = (s.M - s.N) / s.K
+				s.trace(340, "stateLoop31")
 			}
 
 			// Line 03.50: D 9 (call subroutine 9)
@@ -239,6 +292,7 @@ func (s *Sim) mainLoop() {
 			}
 			// S = 2*A / (V + FSQT(V*V + 2*A*(G - Z*K/M)))
 			s.S = 2 * s.A / (s.V + math.Sqrt(s.V*s.V+2*s.A*(s.G-s.Z*s.K/s.M)))
+			s.trace(710, "stateLoop71")
 			// Line 07.30: D 9; D 6; G 7.1
 			s.subroutine9()
 			s.subroutine6()
@@ -248,6 +302,7 @@ func (s *Sim) mainLoop() {
 			// Line 08.10
 			s.W = (1 - s.M*s.G/(s.Z*s.K)) / 2
 			s.S = s.M*s.V/(s.Z*s.K*(s.W+math.Sqrt(s.W*s.W+s.V/s.Z))) + 0.05
+			s.trace(810, "stateLoop81")
 			s.subroutine9()
 
 			// Line 08.30: I (I)7.1,7.1
@@ -273,27 +328,21 @@ func (s *Sim) mainLoop() {
 			// V > 0: stay in loop81
 
 		case stateFuelOut:
-			// Line 04.10 - FOCAL format matches %9.2f for time
-			fmt.Fprintf(s.out, "FUEL OUT AT%9.2f SECS\n", s.L)
+			// Line 04.10
+			s.tty.Type("FUEL OUT AT", s.L, " SECS\n")
 			// Line 04.40: Free fall calculation
 			s.S = (math.Sqrt(s.V*s.V+2*s.A*s.G) - s.V) / s.G
 			s.V = s.V + s.G*s.S
 			s.L = s.L + s.S
+			s.trace(440, "stateFuelOut")
 			state = stateLanding
 
 		case stateLanding:
 			// Lines 05.10-05.83
-			// FOCAL line 05.20 format - uses the last active format spec
-			fmt.Fprintf(s.out, "ON THE MOON AT%9.2f SECS\n", s.L)
+			s.tty.Type("ON THE MOON AT", s.L, " SECS\n")
 			s.W = 3600 * s.V
-			fmt.Fprintf(s.out, "IMPACT VELOCITY OF%9.2fM.P.H.\n", s.W)
-			// FOCAL adds a leading space for positive numbers: min width 9, or 2+numLen if >= 8 chars
-			fuelStr := fmt.Sprintf("%.2f", s.M-s.N)
-			padWidth := 9
-			if len(fuelStr) >= 8 {
-				padWidth = 2 + len(fuelStr)
-			}
-			fmt.Fprintf(s.out, "FUEL LEFT:%*s LBS\n", padWidth, fuelStr)
+			s.trace(510, "stateLanding")
+			s.tty.Type("IMPACT VELOCITY OF", s.W, "M.P.H.\n", "FUEL LEFT:", s.M-s.N, " LBS\n")
 
 			if s.W <= 1 {
 				fmt.Fprintln(s.out, "PERFECT LANDING !-(LUCKY)")
@@ -307,13 +356,19 @@ func (s *Sim) mainLoop() {
 				fmt.Fprintln(s.out, "CRASH LANDING-YOU'VE 5 HRS OXYGEN")
 			} else {
 				fmt.Fprintln(s.out, "SORRY,BUT THERE WERE NO SURVIVORS-YOU BLEW IT!")
-				fmt.Fprintf(s.out, "IN FACT YOU BLASTED A NEW LUNAR CRATER%9.2f FT.DEEP\n", s.W*0.277777)
+				s.tty.Type("IN FACT YOU BLASTED A NEW LUNAR CRATER", s.W*0.277777, " FT.DEEP\n")
 			}
 			state = stateDone
 		}
 	}
 }
 
+// yes and no are the constants 0YES and 0NO, letters counting as digits
+var (
+	yes, _ = focal.ReadNumber("0YES")
+	no, _  = focal.ReadNumber("0NO")
+)
+
 // tryAgain prompts for retry (lines 05.90-05.98)
 func (s *Sim) tryAgain() bool {
 	// FOCAL line 05.90: T !!!!"TRY AGAIN?"! = 4 newlines then TRY AGAIN? then newline
@@ -331,21 +386,35 @@ func (s *Sim) tryAgain() bool {
 			fmt.Fprintln(s.out)
 			return false
 		}
-		line := strings.ToUpper(strings.TrimSpace(s.in.Text()))
-		if line == "NO" {
+		// 05.92 I (P-0NO)5.94,5.98
+		// 05.94 I (P-0YES)5.92,1.2,5.92
+		s.P = s.answer()
+		if s.P == yes {
+			return true
+		}
+		if s.P == no {
 			fmt.Fprintln(s.out, "CONTROL OUT")
 			fmt.Fprintln(s.out)
 			fmt.Fprintln(s.out)
 			return false
 		}
-		if line == "YES" {
-			return true
-		}
-		// Invalid answer, ask again
+		// Any other answer, ask again
 	}
 }
 
 func main() {
+	traceFile := flag.String("trace", "", "record the variables after each FOCAL line to `file`")
+	flag.Parse()
 	sim := NewSim(os.Stdin, os.Stdout)
+	if *traceFile != "" {
+		f, err := os.Create(*traceFile)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		defer f.Close()
+		sim.Trace = trace.NewRecorder(f)
+		defer sim.Trace.Flush()
+	}
 	sim.Run()
 }
@@ -2,10 +2,17 @@ package main
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
+	"io"
+	"os"
 	"os/exec"
 	"strings"
 	"testing"
+
+	"gitlab.com/jhinrichsen/lunar-lander/expect"
+	"gitlab.com/jhinrichsen/lunar-lander/focal"
+	"gitlab.com/jhinrichsen/lunar-lander/trace"
 )
 
 // Test cases with input sequences and descriptions
@@ -187,6 +194,25 @@ func TestPerfectLanding(t *testing.T) {
 	}
 }
 
+// TestInteractive answers each prompt only after it appears instead of
+// feeding all answers up front
+func TestInteractive(t *testing.T) {
+	s := expect.Func(func(in io.Reader, out io.Writer) error {
+		NewSim(in, out).Run()
+		return nil
+	})
+	ks := strings.Fields("0 0 0 0 0 0 200 200 200 200 200 0 0 100 200 200 0 0 71 37")
+	err := s.Run(expect.Rule{Prompt: "K=:", Answers: ks},
+		expect.Rule{Prompt: "(ANS. YES OR NO)", Answers: []string{"NO"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := expect.Telemetry(s.Transcript())
+	if len(f.Rows) != len(ks) || f.Verdict != "PERFECT LANDING !-(LUCKY)" || f.Velocity != 0.66 {
+		t.Errorf("want %d rows and a perfect landing at 0.66 mph, got %+v", len(ks), f)
+	}
+}
+
 // TestMaxBurnCrash tests the immediate crash scenario
 func TestMaxBurnCrash(t *testing.T) {
 	inputs := "200\n200\n200\n200\n200\n200\n200\n200\n200\n200\n200\n200\nNO\n"
@@ -421,3 +447,96 @@ func runGoFuzz(inputs string) string {
 	sim.Run()
 	return out.String()
 }
+
+// lander parses lunar-lander.fc.
+func lander(t *testing.T) *focal.Program {
+	t.Helper()
+	f, err := os.Open("../../lunar-lander.fc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	p, err := focal.Parse(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+// TestTrace compares the variables after each FOCAL line with the FOCAL
+// program run by package focal; it needs no retrofocal.
+func TestTrace(t *testing.T) {
+	p := lander(t)
+	cases := append(testCases, struct{ name, inputs string }{
+		"retry", "5\n" + strings.Repeat("200\n", 12) + "YES\n" + strings.Repeat("0\n", 12) + "NO\n",
+	})
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var fb bytes.Buffer
+			err := trace.Focal(p, focal.NewTerminal(strings.NewReader(tc.inputs), io.Discard), &fb)
+			if err != nil && !errors.Is(err, io.EOF) {
+				t.Fatal(err)
+			}
+			want, err := trace.Read(&fb)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var gb bytes.Buffer
+			sim := NewSim(strings.NewReader(tc.inputs), io.Discard)
+			sim.Trace = trace.NewRecorder(&gb)
+			sim.Run()
+			if err := sim.Trace.Flush(); err != nil {
+				t.Fatal(err)
+			}
+			got, err := trace.Read(&gb)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if m := trace.Diff(want, got, 1e-9); m != nil {
+				t.Error(m.Format(want, got))
+			}
+		})
+	}
+}
+
+// TestTypeOutput compares the output with the FOCAL program run by package
+// focal, which types numbers through package typefmt like this port.
+func TestTypeOutput(t *testing.T) {
+	p := lander(t)
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var want bytes.Buffer
+			err := focal.NewInterp(p, focal.NewTerminal(strings.NewReader(tc.inputs), &want)).Run()
+			if err != nil && !errors.Is(err, io.EOF) {
+				t.Fatal(err)
+			}
+			if got := runGo(t, tc.inputs); got != want.String() {
+				t.Errorf("want\n%s\ngot\n%s", want.String(), got)
+			}
+		})
+	}
+}
+
+// TestAskExpressions answers with FOCAL expressions and compares the output
+// with the FOCAL program run by package focal.
+func TestAskExpressions(t *testing.T) {
+	p := lander(t)
+	for name, inputs := range map[string]string{
+		"arithmetic": "2*100\n(150+50)\n" + strings.Repeat("K\n", 10) + "no\n",
+		"functions":  "FSQT(400)*10\nFABS(-200)\nFITR(200.7)\n" + strings.Repeat("0\n", 14) + "NO\n",
+		"variables":  "Q\nX\nX\nX-42\nM/N\nA\nK+1\n" + strings.Repeat("0\n", 10) + "NO\n",
+		"letters":    "K=3\nYES\n" + strings.Repeat("200\n", 12) + "MAYBE\nP\n0YES\n" + strings.Repeat("0\n", 12) + "N.O.\nNO;\n",
+		"random":     "FRAN()*200\n" + strings.Repeat("0\n", 12) + "FRAN()\nNO\n",
+	} {
+		t.Run(name, func(t *testing.T) {
+			var want bytes.Buffer
+			err := focal.NewInterp(p, focal.NewTerminal(strings.NewReader(inputs), &want)).Run()
+			if err != nil && !errors.Is(err, io.EOF) {
+				t.Fatal(err)
+			}
+			if got := runGo(t, inputs); got != want.String() {
+				t.Errorf("want\n%s\ngot\n%s", want.String(), got)
+			}
+		})
+	}
+}
@@ -0,0 +1,62 @@
+// Command fidelity grades the output of a port against a transcript of the
+// original: text must agree, numbers within a tolerance.
+//
+//	claude-code < lunar/testdata/good.in > port.out
+//	fidelity -digits 3 lunar/testdata/good.out port.out
+//
+// It writes the lines that are not the same side by side, the transcript
+// left, and a summary with the score, the fraction of faithful lines.
+// fidelity exits with status 1 if a line differs.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"gitlab.com/jhinrichsen/lunar-lander/fidelity"
+)
+
+func run(w io.Writer, want, got string, tol fidelity.Tolerance, width int, all bool) (bool, error) {
+	a, err := os.ReadFile(want)
+	if err != nil {
+		return false, err
+	}
+	b, err := os.ReadFile(got)
+	if err != nil {
+		return false, err
+	}
+	r := fidelity.Compare(string(a), string(b), tol)
+	if err := r.SideBySide(w, width, all); err != nil {
+		return false, err
+	}
+	_, err = fmt.Fprintln(w, r.Summary())
+	return r.Faithful(), err
+}
+
+func main() {
+	var tol fidelity.Tolerance
+	flag.IntVar(&tol.Digits, "digits", 3, "significant figures numbers must agree to, 0 for none")
+	flag.Float64Var(&tol.Abs, "abs", 0, "absolute tolerance of numbers")
+	flag.Float64Var(&tol.Rel, "rel", 0, "relative tolerance of numbers")
+	width := flag.Int("width", 60, "columns per side")
+	all := flag.Bool("all", false, "write all lines, not only those that are not the same")
+	flag.Usage = func() {
+		fmt.Fprintln(flag.CommandLine.Output(), "usage: fidelity [flags] transcript port-output")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 2 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	ok, err := run(os.Stdout, flag.Arg(0), flag.Arg(1), tol, *width, *all)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if !ok {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gitlab.com/jhinrichsen/lunar-lander/fidelity"
+)
+
+const good = "../../lunar/testdata/good.out"
+
+func TestRunSame(t *testing.T) {
+	var out strings.Builder
+	ok, err := run(&out, good, good, fidelity.Tolerance{}, 40, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ok || !strings.HasPrefix(out.String(), "score 1.000: 37 lines, 37 same") {
+		t.Fatalf("want a faithful copy, got\n%s", out.String())
+	}
+}
+
+func TestRunDiffer(t *testing.T) {
+	b, err := os.ReadFile(good)
+	if err != nil {
+		t.Fatal(err)
+	}
+	port := filepath.Join(t.TempDir(), "port.out")
+	s := strings.Replace(string(b), "21.35M.P.H.", "21.36M.P.H.", 1)
+	s = strings.Replace(s, "CONGRATULATIONS", "WELL DONE", 1)
+	if err := os.WriteFile(port, []byte(s), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	var out strings.Builder
+	ok, err := run(&out, good, port, fidelity.Tolerance{Digits: 3}, 40, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok {
+		t.Fatal("want a difference")
+	}
+	for _, s := range []string{
+		"IMPACT VELOCITY OF    21.35M.P.H.        ~ IMPACT VELOCITY OF    21.36M.P.H.\n",
+		"CONGRATULATIONS ON A POOR LANDING        | WELL DONE ON A POOR LANDING\n",
+		"35 same, 1 close, 1 differ",
+	} {
+		if !strings.Contains(out.String(), s) {
+			t.Errorf("missing %q in\n%s", s, out.String())
+		}
+	}
+}
+
+func TestRunMissing(t *testing.T) {
+	if _, err := run(&strings.Builder{}, good, "nonexistent.out", fidelity.Tolerance{}, 40, false); err == nil {
+		t.Fatal("want an error")
+	}
+}
@@ -0,0 +1,95 @@
+// Command focal is a FOCAL-69 prompt for experimenting with the 1969
+// program the way it was written: type numbered lines, list them with W,
+// change them with M, erase them with E and run the program with GO.
+//
+//	focal lunar-lander.fc
+//	*M 8.1
+//	08.10 S W=(1-M*G/(Z*K))/2;S S=M*V/(Z*K*(W+FSQT(W*W+V/Z)))+.05;D 9
+//	/(Z*K)/Z*K/
+//	*L S lander-prompt.fc
+//	*GO
+//
+// The optional argument is loaded as the starting program, L S without a
+// name saves back to it. See focal.Session for all commands. -tty prints at
+// teletype speed, -paper keeps the session as a page image.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"gitlab.com/jhinrichsen/lunar-lander/asr33"
+	"gitlab.com/jhinrichsen/lunar-lander/focal"
+)
+
+func run(filename string, in io.Reader, out io.Writer) error {
+	prog := &focal.Program{}
+	if filename != "" {
+		f, err := os.Open(filename)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		prog, err = focal.Parse(f)
+		if err != nil {
+			return fmt.Errorf("%s:%w", filename, err)
+		}
+	}
+	s := focal.NewSession(prog, focal.NewTerminal(in, out))
+	s.File = filename
+	return s.Run()
+}
+
+func writePaper(filename string, p *asr33.Paper) error {
+	f, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	if err := p.PNG(f, 2); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func main() {
+	tty := flag.Float64("tty", 0, "print like an ASR-33 at `speed` times 10 characters per second, 0 at once")
+	paperFile := flag.String("paper", "", "write the session as teletype paper to PNG `file`")
+	flag.Usage = func() {
+		fmt.Fprintln(flag.CommandLine.Output(), "usage: focal [-tty speed] [-paper file] [program.fc]")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() > 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	var (
+		in  io.Reader = os.Stdin
+		out io.Writer = os.Stdout
+		p   asr33.Paper
+	)
+	if *paperFile != "" {
+		out = io.MultiWriter(out, &p)
+		// answers typed at a terminal echo onto the paper, piped ones
+		// are read ahead and would land in the wrong place
+		if fi, err := os.Stdin.Stat(); err == nil && fi.Mode()&os.ModeCharDevice != 0 {
+			in = io.TeeReader(in, &p)
+		}
+	}
+	if *tty > 0 || *paperFile != "" {
+		out = asr33.NewWriter(out, *tty)
+	}
+	err := run(flag.Arg(0), in, out)
+	if *paperFile != "" {
+		if perr := writePaper(*paperFile, &p); err == nil {
+			err = perr
+		}
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
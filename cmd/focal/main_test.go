@@ -0,0 +1,53 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRun(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "lander.fc")
+	b, err := os.ReadFile("../../lunar-lander.fc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(name, b, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	var out strings.Builder
+	in := "M 8.1\n/(Z*K)/Z*K/\nW 8\nL S\n"
+	if err := run(name, strings.NewReader(in), &out); err != nil {
+		t.Fatal(err)
+	}
+	want := "*08.10 S W=(1-M*G/(Z*K))/2;S S=M*V/(Z*K*(W+FSQT(W*W+V/Z)))+.05;D 9\n" +
+		"*08.10 S W=(1-M*G/Z*K)/2;S S=M*V/(Z*K*(W+FSQT(W*W+V/Z)))+.05;D 9\n" +
+		"08.30 I (I)7.1,7.1;D 6;I (-J)3.1,3.1;I (V)3.1,3.1,8.1\n**\n"
+	if out.String() != want {
+		t.Errorf("want %q, got %q", want, out.String())
+	}
+	saved, err := os.ReadFile(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(saved), "\n08.10 S W=(1-M*G/Z*K)/2;") {
+		t.Errorf("want the change saved, got\n%s", saved)
+	}
+}
+
+func TestRunEmpty(t *testing.T) {
+	var out strings.Builder
+	if err := run("", strings.NewReader("1.1 T \"HI\"!\nGO\n"), &out); err != nil {
+		t.Fatal(err)
+	}
+	if want := "**HI\n*\n"; out.String() != want {
+		t.Errorf("want %q, got %q", want, out.String())
+	}
+}
+
+func TestRunErrors(t *testing.T) {
+	if err := run("missing.fc", strings.NewReader(""), new(strings.Builder)); err == nil {
+		t.Error("want an error for a missing program")
+	}
+}
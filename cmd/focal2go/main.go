@@ -0,0 +1,526 @@
+// Command focal2go translates a FOCAL-69 program into a Go program.
+//
+// The generated code mirrors the FOCAL control flow instead of restructuring
+// it: every line becomes a labelled block of one run method, so GOTO and the
+// three-way I statement turn into goto, and the rest of a line after F
+// becomes the body of a for loop. Groups and lines called by DO become
+// methods of their own. Variables are float64 fields of a program struct,
+// the TYPE format state and ASK input live in a focal.Terminal.
+//
+// A GOTO out of a group that is called by DO has no structured
+// equivalent and is reported as an error.
+//
+//	focal2go [-o file] program.fc
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/format"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"gitlab.com/jhinrichsen/lunar-lander/focal"
+)
+
+// gen writes Go source for one program.
+type gen struct {
+	prog   *focal.Program
+	buf    bytes.Buffer
+	scalar map[string]bool
+	array  map[string]bool
+	ask    bool // the program asks, run needs vars
+	math   bool
+	err    error
+}
+
+// scope is the part of the program a Go function covers.
+type scope struct {
+	lines  []*focal.Line
+	labels map[focal.Num]bool // jump targets within the scope
+}
+
+func (s scope) has(n focal.Num) bool {
+	_, ok := slices.BinarySearchFunc(s.lines, n, func(l *focal.Line, n focal.Num) int {
+		return int(l.Num - n)
+	})
+	return ok
+}
+
+func (g *gen) printf(format string, args ...any) {
+	fmt.Fprintf(&g.buf, format, args...)
+}
+
+func (g *gen) fail(l *focal.Line, format string, args ...any) {
+	if g.err == nil {
+		g.err = fmt.Errorf("%s: %s", l.Num, fmt.Sprintf(format, args...))
+	}
+}
+
+// Generate translates prog, read from the file called source, into a Go
+// main package.
+func Generate(prog *focal.Program, source string) ([]byte, error) {
+	g := &gen{prog: prog, scalar: map[string]bool{}, array: map[string]bool{}}
+	calls := map[focal.Num]bool{}
+	focal.Inspect(prog, func(n focal.Node) bool {
+		switch n := n.(type) {
+		case *focal.Var:
+			if n.Index == nil {
+				g.scalar[n.Name] = true
+			} else {
+				g.array[n.Name] = true
+			}
+		case *focal.Do:
+			calls[n.Target] = true
+		case *focal.Ask:
+			g.ask = true
+		}
+		return true
+	})
+
+	g.printf("// Code generated by focal2go from %s. DO NOT EDIT.\n\n", filepath.Base(source))
+	g.printf("package main\n\n")
+	body := g.body(calls)
+	g.printf("import (\n\"errors\"\n\"fmt\"\n\"io\"\n")
+	if g.math {
+		g.printf("\"math\"\n")
+	}
+	g.printf("\"os\"\n\n\"gitlab.com/jhinrichsen/lunar-lander/focal\"\n)\n\n")
+	g.buf.Write(body)
+	if g.err != nil {
+		return nil, g.err
+	}
+	src, err := format.Source(g.buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("generated code: %w", err)
+	}
+	return src, nil
+}
+
+// body generates everything after the imports, noting whether math is used.
+func (g *gen) body(calls map[focal.Num]bool) []byte {
+	head := g.buf
+	g.buf = bytes.Buffer{}
+
+	g.printf("// program holds the variables.\ntype program struct {\ntty *focal.Terminal\n\n")
+	for _, v := range slices.Sorted(maps.Keys(g.scalar)) {
+		g.printf("%s float64\n", v)
+	}
+	for _, v := range slices.Sorted(maps.Keys(g.array)) {
+		g.printf("%s_ map[float64]float64\n", v)
+	}
+	g.printf("}\n\n")
+
+	g.printf("// erase clears all variables (E).\nfunc (p *program) erase() {\n*p = program{tty: p.tty")
+	for _, v := range slices.Sorted(maps.Keys(g.array)) {
+		g.printf(", %s_: map[float64]float64{}", v)
+	}
+	g.printf("}\n}\n\n")
+
+	if g.ask {
+		g.printf("// vars returns the variables, in which ASK answers are evaluated.\nfunc (p *program) vars() map[string]float64 {\nreturn map[string]float64{")
+		for i, v := range slices.Sorted(maps.Keys(g.scalar)) {
+			if i > 0 {
+				g.printf(", ")
+			}
+			g.printf("%q: p.%s", v, v)
+		}
+		g.printf("}\n}\n\n")
+	}
+
+	g.printf(`// execute runs the program on a terminal reading in and printing to out.
+func execute(in io.Reader, out io.Writer) error {
+	p := &program{tty: focal.NewTerminal(in, out)}
+	p.erase()
+	return p.tty.Run(p.run)
+}
+
+func main() {
+	if err := execute(os.Stdin, os.Stdout); err != nil && !errors.Is(err, io.EOF) {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+`)
+	g.printf("// run executes the program from its first line.\nfunc (p *program) run() {\n")
+	g.lines(g.scope(g.prog.Lines))
+	g.printf("}\n")
+
+	for _, n := range slices.Sorted(maps.Keys(calls)) {
+		if g.prog.Resolve(n) == nil {
+			if n.IsGroup() {
+				g.err = fmt.Errorf("DO %d: no such group", n.Group())
+			} else {
+				g.err = fmt.Errorf("DO %s: no such line", n)
+			}
+			continue
+		}
+		if n.IsGroup() {
+			g.printf("\n// group%d is group %d, called by DO.\nfunc (p *program) group%d() {\n", n.Group(), n.Group(), n.Group())
+			g.lines(g.scope(g.prog.Group(n.Group())))
+		} else {
+			g.printf("\n// %s is line %s, called by DO.\nfunc (p *program) %s() {\n", method(n), n, method(n))
+			g.lines(g.scope([]*focal.Line{g.prog.Line(n)}))
+		}
+		g.printf("}\n")
+	}
+
+	body := g.buf.Bytes()
+	g.buf = head
+	return body
+}
+
+// method names the function of a line called by DO.
+func method(n focal.Num) string {
+	if n.IsGroup() {
+		return fmt.Sprintf("group%d", n.Group())
+	}
+	return fmt.Sprintf("line%02d%02d", n.Group(), n.Step())
+}
+
+// label names the goto label of a line.
+func label(n focal.Num) string {
+	return fmt.Sprintf("l%02d%02d", n.Group(), n.Step())
+}
+
+// scope collects the jump targets within lines; only those get labels
+// because Go rejects unused labels.
+func (g *gen) scope(lines []*focal.Line) scope {
+	s := scope{lines: lines, labels: map[focal.Num]bool{}}
+	for _, l := range lines {
+		focal.Inspect(l, func(n focal.Node) bool {
+			var ts []focal.Num
+			switch n := n.(type) {
+			case *focal.Goto:
+				ts = []focal.Num{n.Target}
+			case *focal.If:
+				ts = n.Targets
+			}
+			for _, t := range ts {
+				if r := g.prog.Resolve(t); r != nil {
+					s.labels[r.Num] = true
+				}
+			}
+			return true
+		})
+	}
+	return s
+}
+
+// lines translates the lines of a scope. Lines that only a jump from
+// elsewhere could reach, like group 9 after the G of 08.30, are left out:
+// Go vet rejects unreachable code.
+func (g *gen) lines(s scope) {
+	dead := false
+	for i, l := range s.lines {
+		if i > 0 {
+			g.printf("\n")
+		}
+		if dead && !s.labels[l.Num] {
+			g.printf("// %s (unreachable here)\n", l)
+			continue
+		}
+		g.printf("// %s\n", l)
+		if s.labels[l.Num] {
+			g.printf("%s:\n", label(l.Num))
+		}
+		dead = g.stmts(s, l, l.Stmts)
+	}
+}
+
+// stmts translates ss up to the first statement that always jumps and
+// reports whether there was one.
+func (g *gen) stmts(s scope, l *focal.Line, ss []focal.Stmt) bool {
+	for _, st := range ss {
+		g.stmt(s, l, st)
+		switch st := st.(type) {
+		case *focal.Goto, *focal.Return:
+			return true
+		case *focal.If:
+			if len(st.Targets) == 3 && !slices.Contains(st.Targets, 0) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// jump returns the goto to target n, which must lie in the scope.
+func (g *gen) jump(s scope, l *focal.Line, n focal.Num) string {
+	r := g.prog.Resolve(n)
+	if r == nil {
+		g.fail(l, "no line %s", n)
+		return ""
+	}
+	if !s.has(r.Num) {
+		g.fail(l, "jump to %s leaves the group called by DO", r.Num)
+		return ""
+	}
+	return "goto " + label(r.Num)
+}
+
+func (g *gen) stmt(s scope, l *focal.Line, st focal.Stmt) {
+	switch st := st.(type) {
+	case *focal.Type:
+		for _, it := range st.Items {
+			g.item(it)
+		}
+	case *focal.Ask:
+		for _, it := range st.Items {
+			if v, ok := it.(*focal.Var); ok {
+				g.printf("%s = p.tty.Ask(p.vars())\n", g.expr(v))
+				continue
+			}
+			g.item(it)
+		}
+	case *focal.Set:
+		g.printf("%s = %s\n", g.expr(st.Var), g.expr(st.X))
+	case *focal.Goto:
+		g.printf("%s\n", g.jump(s, l, st.Target))
+	case *focal.Do:
+		g.printf("p.%s()\n", method(st.Target))
+	case *focal.If:
+		x := g.expr(st.Cond)
+		if len(st.Targets) == 1 {
+			g.printf("if %s < 0 {\n%s\n}\n", x, g.jump(s, l, st.Targets[0]))
+			return
+		}
+		g.printf("switch x := %s; {\n", x)
+		for i, c := range []string{"case x < 0:", "case x == 0:", "default:"}[:len(st.Targets)] {
+			if st.Targets[i] != 0 {
+				g.printf("%s\n%s\n", c, g.jump(s, l, st.Targets[i]))
+			}
+		}
+		g.printf("}\n")
+	case *focal.For:
+		// FOCAL evaluates step, start and end once, in this order
+		v := g.expr(st.Var)
+		c, constStep := 1.0, true
+		cmp, step := "<=", "++"
+		if st.Step != nil {
+			c, constStep = constant(st.Step)
+			step = " += " + g.expr(st.Step)
+		}
+		if c < 0 {
+			cmp = ">="
+		}
+		_, constEnd := constant(st.End)
+		switch {
+		case constStep && constEnd:
+			g.printf("for %s = %s; %s %s %s; %s%s {\n", v, g.expr(st.Start), v, cmp, g.expr(st.End), v, step)
+		case constStep:
+			g.printf("{\nvar start, end float64 = %s, %s\n", g.expr(st.Start), g.expr(st.End))
+			g.printf("for %s = start; %s %s end; %s%s {\n", v, v, cmp, v, step)
+		default:
+			// the sign of the step decides the comparison
+			g.printf("{\nvar step, start, end float64 = %s, %s, %s\n", g.expr(st.Step), g.expr(st.Start), g.expr(st.End))
+			g.printf("for %s = start; step < 0 && %s >= end || step >= 0 && %s <= end; %s += step {\n", v, v, v, v)
+		}
+		g.stmts(s, l, st.Body)
+		g.printf("}\n")
+		if !constStep || !constEnd {
+			g.printf("}\n")
+		}
+	case *focal.Erase:
+		g.printf("p.erase()\n")
+	case *focal.Quit:
+		g.printf("p.tty.Quit()\n")
+	case *focal.Return:
+		g.printf("return\n")
+	case *focal.Comment:
+	default:
+		g.fail(l, "cannot translate %s", st)
+	}
+}
+
+func (g *gen) item(it focal.Item) {
+	switch it := it.(type) {
+	case focal.Text:
+		g.printf("p.tty.Text(%s)\n", strconv.Quote(string(it)))
+	case focal.Newline:
+		g.printf("p.tty.Newline()\n")
+	case focal.CR:
+		g.printf("p.tty.CR()\n")
+	case focal.Format:
+		if it.E {
+			g.printf("p.tty.Format(0, 0)\n")
+		} else {
+			g.printf("p.tty.Format(%d, %d)\n", it.Width, it.Digits)
+		}
+	case focal.Expr:
+		g.printf("p.tty.Number(%s)\n", g.expr(it))
+	}
+}
+
+// funcs maps FOCAL functions to Go.
+var funcs = map[string]string{
+	"FABS": "math.Abs",
+	"FATN": "math.Atan",
+	"FCOS": "math.Cos",
+	"FEXP": "math.Exp",
+	"FITR": "math.Trunc",
+	"FLOG": "math.Log",
+	"FSGN": "focal.Sgn",
+	"FSIN": "math.Sin",
+	"FSQT": "math.Sqrt",
+}
+
+// precedence of Go operators, for parenthesising; calls such as math.Pow
+// bind tightest.
+func precedence(x focal.Expr) int {
+	switch x := x.(type) {
+	case *focal.Binary:
+		switch x.Op {
+		case '+', '-':
+			return 4
+		case '*', '/':
+			return 5
+		}
+	case *focal.Unary:
+		return 6
+	}
+	return 7
+}
+
+// expr translates x into a float64 Go expression. Constant subexpressions
+// are folded so Go never sees an untyped integer division such as 1/2.
+func (g *gen) expr(x focal.Expr) string {
+	if c, ok := constant(x); ok {
+		return number(c)
+	}
+	switch x := x.(type) {
+	case *focal.Var:
+		if x.Index == nil {
+			return "p." + x.Name
+		}
+		return "p." + x.Name + "_[" + g.expr(x.Index) + "]"
+	case *focal.Unary:
+		if x.Op == '+' {
+			return g.expr(x.X)
+		}
+		return "-" + g.operand(x.X, 6, true)
+	case *focal.Binary:
+		if x.Op == '^' {
+			g.math = true
+			return "math.Pow(" + g.expr(x.X) + ", " + g.expr(x.Y) + ")"
+		}
+		p := precedence(x)
+		return g.operand(x.X, p, false) + " " + string(x.Op) + " " + g.operand(x.Y, p, true)
+	case *focal.Call:
+		if x.Name == "FRAN" {
+			return "p.tty.Random()"
+		}
+		f := funcs[x.Name]
+		if strings.HasPrefix(f, "math.") {
+			g.math = true
+		}
+		return f + "(" + g.expr(x.Arg) + ")"
+	}
+	panic(fmt.Sprintf("focal2go: unexpected expression %T", x))
+}
+
+// operand translates x as an operand of an operator of precedence p.
+func (g *gen) operand(x focal.Expr, p int, right bool) string {
+	s := g.expr(x)
+	q := precedence(x)
+	if c, ok := constant(x); ok {
+		// a negative constant reads like a unary minus
+		q = 7
+		if c < 0 {
+			q = 6
+		}
+	}
+	if q < p || (q == p && right) {
+		return "(" + s + ")"
+	}
+	return s
+}
+
+// constant evaluates x if it contains no variables or function calls.
+func constant(x focal.Expr) (float64, bool) {
+	switch x := x.(type) {
+	case *focal.Number:
+		return x.Value, true
+	case *focal.Unary:
+		v, ok := constant(x.X)
+		if x.Op == '-' {
+			v = -v
+		}
+		return v, ok
+	case *focal.Binary:
+		a, ok := constant(x.X)
+		b, ok2 := constant(x.Y)
+		if !ok || !ok2 || x.Op == '^' {
+			return 0, false
+		}
+		switch x.Op {
+		case '+':
+			return a + b, true
+		case '-':
+			return a - b, true
+		case '*':
+			return a * b, true
+		case '/':
+			return a / b, b != 0
+		}
+	}
+	return 0, false
+}
+
+// number prints a constant so Go reads back the same float64.
+func number(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+func run(w io.Writer, filename string) error {
+	f, err := os.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	prog, err := focal.Parse(f)
+	if err != nil {
+		return fmt.Errorf("%s:%w", filename, err)
+	}
+	src, err := Generate(prog, filename)
+	if err != nil {
+		return fmt.Errorf("%s: %w", filename, err)
+	}
+	_, err = w.Write(src)
+	return err
+}
+
+func main() {
+	out := flag.String("o", "", "write Go source to `file` instead of standard output")
+	flag.Usage = func() {
+		fmt.Fprintln(flag.CommandLine.Output(), "usage: focal2go [-o file] program.fc")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	var buf bytes.Buffer
+	if err := run(&buf, flag.Arg(0)); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	var err error
+	if *out == "" {
+		_, err = buf.WriteTo(os.Stdout)
+	} else {
+		err = os.WriteFile(*out, buf.Bytes(), 0o644)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
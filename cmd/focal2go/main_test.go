@@ -0,0 +1,92 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+
+	"gitlab.com/jhinrichsen/lunar-lander/focal"
+)
+
+func parse(t *testing.T, src string) *focal.Program {
+	t.Helper()
+	p, err := focal.Parse(strings.NewReader(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+// The reference port must be what focal2go generates today.
+func TestReferenceUpToDate(t *testing.T) {
+	var got bytes.Buffer
+	if err := run(&got, "../../lunar-lander.fc"); err != nil {
+		t.Fatal(err)
+	}
+	want, err := os.ReadFile("../reference/main.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(want, got.Bytes()) {
+		t.Error("cmd/reference/main.go is stale, run go generate ./cmd/reference")
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	src, err := Generate(parse(t, `
+01.05 D 2
+01.10 S X=1/2;S Y=-3*2;S Z=A-(B-C);S W=-(-A);S R=A/(B*C);S Q=A^2
+01.20 F I=10,-1,1;T %5.01,I(I),!
+01.25 F J=1,X;F K=X,-Y,1;T J,K
+01.30 I (X)1.1,,1.1;G 1.3
+01.40 C never reached
+02.10 S I(1)=FRAN()+FSGN(X);R
+`), "test.fc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := string(src)
+	for _, want := range []string{
+		"// Code generated by focal2go from test.fc. DO NOT EDIT.",
+		"p.X = 0.5\n",
+		"p.Y = -6\n",
+		"p.Z = p.A - (p.B - p.C)\n",
+		"p.W = -(-p.A)\n",
+		"p.R = p.A / (p.B * p.C)\n",
+		"p.Q = math.Pow(p.A, 2)\n",
+		"for p.I = 10; p.I >= 1; p.I += -1 {",
+		"{\n\t\tvar start, end float64 = 1, p.X\n\t\tfor p.J = start; p.J <= end; p.J++ {",
+		"{\n\t\t\t\tvar step, start, end float64 = -p.Y, p.X, 1\n\t\t\t\tfor p.K = start; step < 0 && p.K >= end || step >= 0 && p.K <= end; p.K += step {",
+		"p.tty.Format(5, 1)",
+		"p.tty.Number(p.I_[p.I])",
+		"I_ map[float64]float64",
+		"*p = program{tty: p.tty, I_: map[float64]float64{}}",
+		"l0110:",
+		"case x < 0:\n\t\tgoto l0110\n\tdefault:\n\t\tgoto l0110",
+		"goto l0130",
+		"// 01.40 C never reached (unreachable here)",
+		"p.group2()",
+		"func (p *program) group2() {\n\t// 02.10 S I(1)=FRAN()+FSGN(X);R\n\tp.I_[1] = p.tty.Random() + focal.Sgn(p.X)\n\treturn\n}",
+	} {
+		if !strings.Contains(s, want) {
+			t.Errorf("want %q in\n%s", want, s)
+		}
+	}
+}
+
+func TestGenerateErrors(t *testing.T) {
+	tests := []struct {
+		src, want string
+	}{
+		{"01.10 D 2\n02.10 G 1.1\n", "02.10: jump to 01.10 leaves the group called by DO"},
+		{"01.10 G 1.2\n", "01.10: no line 01.20"},
+		{"01.10 D 3\n", "DO 3: no such group"},
+		{"01.10 D 1.2\n", "DO 01.20: no such line"},
+	}
+	for _, tt := range tests {
+		if _, err := Generate(parse(t, tt.src), "x.fc"); err == nil || err.Error() != tt.want {
+			t.Errorf("%q: want %q, got %v", tt.src, tt.want, err)
+		}
+	}
+}
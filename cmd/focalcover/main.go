@@ -0,0 +1,181 @@
+// Command focalcover runs a FOCAL-69 program on a corpus of input files,
+// one session per file, and reports how often each line ran and which arms,
+// negative, zero or positive, each IF took.
+//
+//	focalcover lunar-lander.fc lunar/testdata/*.in
+//	focalcover -html cover.html lunar-lander.fc cmd/antigravity/testdata/*.txt
+//
+// Counts of zero show as ##### like in gcov.
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"html/template"
+	"io"
+	"os"
+	"strconv"
+
+	"gitlab.com/jhinrichsen/lunar-lander/focal"
+)
+
+// cover runs prog on each input file.
+func cover(prog *focal.Program, inputs []string) (*focal.Coverage, error) {
+	c := focal.NewCoverage(prog)
+	for _, name := range inputs {
+		f, err := os.Open(name)
+		if err != nil {
+			return nil, err
+		}
+		err = c.Run(focal.NewTerminal(f, io.Discard))
+		f.Close()
+		// Running out of answers ends a session like typing nothing more.
+		if err != nil && !errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+	}
+	return c, nil
+}
+
+func count(n int) string {
+	if n == 0 {
+		return "#####"
+	}
+	return strconv.Itoa(n)
+}
+
+func percent(n, of int) float64 {
+	if of == 0 {
+		return 100
+	}
+	return 100 * float64(n) / float64(of)
+}
+
+func summary(name string, c *focal.Coverage) string {
+	lines, allLines, arms, allArms := c.Summary()
+	return fmt.Sprintf("%s: %d runs, lines %d/%d (%.1f%%), IF arms %d/%d (%.1f%%)",
+		name, c.Runs, lines, allLines, percent(lines, allLines), arms, allArms, percent(arms, allArms))
+}
+
+// branches groups the IF statements by line.
+func branches(c *focal.Coverage) map[focal.Num][]focal.Branch {
+	m := map[focal.Num][]focal.Branch{}
+	for _, b := range c.Branches() {
+		m[b.Line] = append(m[b.Line], b)
+	}
+	return m
+}
+
+// text writes the listing with counts, each line followed by its IF
+// statements and the hits of their arms.
+func text(w io.Writer, name string, c *focal.Coverage) error {
+	b := bufio.NewWriter(w)
+	fmt.Fprintln(b, summary(name, c))
+	fmt.Fprintln(b)
+	bs := branches(c)
+	for _, l := range c.Prog.Lines {
+		fmt.Fprintf(b, "%7s  %s\n", count(c.Lines[l.Num]), l)
+		for _, br := range bs[l.Num] {
+			fmt.Fprintf(b, "%7s  %5s %s  <0 %s  =0 %s  >0 %s\n", "", "", br.If,
+				count(br.Hits[0]), count(br.Hits[1]), count(br.Hits[2]))
+		}
+	}
+	return b.Flush()
+}
+
+var page = template.Must(template.New("cover").Funcs(template.FuncMap{"count": count}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Name}} coverage</title>
+<style>
+body { font-family: monospace; }
+table { border-collapse: collapse; }
+td { padding: 0 0.6em; white-space: pre; }
+td.n { text-align: right; }
+tr.hit { background: #dfd; }
+tr.miss { background: #fdd; }
+span.arm { padding: 0 0.3em; margin-right: 0.3em; }
+span.hit { background: #8d8; }
+span.miss { background: #f88; }
+</style>
+</head>
+<body>
+<p>{{.Summary}}</p>
+<table>
+{{range .Lines}}<tr class="{{if .Count}}hit{{else}}miss{{end}}"><td class="n">{{count .Count}}</td><td>{{.Line.Num}}</td><td>{{.Line.Source}}</td></tr>
+{{range .Branches}}<tr><td></td><td></td><td>{{.If}} {{range $i, $h := .Hits}}<span class="arm {{if $h}}hit{{else}}miss{{end}}">{{index $.Arms $i}} {{count $h}}</span>{{end}}</td></tr>
+{{end}}{{end}}</table>
+</body>
+</html>
+`))
+
+// html writes the listing as a page, lines and arms that never ran in red.
+func html(w io.Writer, name string, c *focal.Coverage) error {
+	type line struct {
+		Line     *focal.Line
+		Count    int
+		Branches []focal.Branch
+	}
+	data := struct {
+		Name, Summary string
+		Arms          []string
+		Lines         []line
+	}{Name: name, Summary: summary(name, c), Arms: []string{"<0", "=0", ">0"}}
+	bs := branches(c)
+	for _, l := range c.Prog.Lines {
+		data.Lines = append(data.Lines, line{l, c.Lines[l.Num], bs[l.Num]})
+	}
+	return page.Execute(w, data)
+}
+
+func run(w io.Writer, htmlFile, filename string, inputs []string) error {
+	f, err := os.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	prog, err := focal.Parse(f)
+	if err != nil {
+		return fmt.Errorf("%s:%w", filename, err)
+	}
+	c, err := cover(prog, inputs)
+	if err != nil {
+		return err
+	}
+	if htmlFile == "" {
+		return text(w, filename, c)
+	}
+	out, err := os.Create(htmlFile)
+	if err != nil {
+		return err
+	}
+	if err := html(out, filename, c); err != nil {
+		out.Close()
+		return err
+	}
+	if err := out.Close(); err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(w, summary(filename, c))
+	return err
+}
+
+func main() {
+	htmlFile := flag.String("html", "", "write the report as HTML to `file`")
+	flag.Usage = func() {
+		fmt.Fprintln(flag.CommandLine.Output(), "usage: focalcover [-html file] program.fc input...")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() < 2 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if err := run(os.Stdout, *htmlFile, flag.Arg(0), flag.Args()[1:]); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
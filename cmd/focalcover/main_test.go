@@ -0,0 +1,65 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var corpus = []string{
+	"../../lunar/testdata/perfect.in",
+	"../../lunar/testdata/good.in",
+	"../../lunar/testdata/crash.in",
+}
+
+func TestText(t *testing.T) {
+	var out strings.Builder
+	if err := run(&out, "", "../../lunar-lander.fc", corpus); err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{
+		"../../lunar-lander.fc: 3 runs, lines 39/39 (100.0%), IF arms 36/60 (60.0%)",
+		"\n      1  02.72 T \"NOT POSSIBLE\"",
+		"\n      3  05.10 T \"ON THE MOON AT\"",
+		"\n               I (V)3.1,3.1,8.1  <0 3  =0 #####  >0 #####\n",
+	} {
+		if !strings.Contains(out.String(), s) {
+			t.Errorf("missing %q in\n%s", s, out.String())
+		}
+	}
+}
+
+func TestHTML(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "cover.html")
+	var out strings.Builder
+	if err := run(&out, name, "../../lunar-lander.fc", corpus); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(out.String(), "../../lunar-lander.fc: 3 runs") {
+		t.Errorf("want the summary, got %q", out.String())
+	}
+	b, err := os.ReadFile(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{
+		`<tr class="hit"><td class="n">1</td><td>02.72</td>`,
+		`<td>I (V)3.1,3.1,8.1 <span class="arm`,
+		`<span class="arm miss">&gt;0 #####</span>`,
+		`T &#34;ON THE MOON AT&#34;`,
+	} {
+		if !strings.Contains(string(b), s) {
+			t.Errorf("missing %q in\n%s", s, b)
+		}
+	}
+}
+
+func TestRunErrors(t *testing.T) {
+	if err := run(new(strings.Builder), "", "../../lunar-lander.fc", []string{"missing.in"}); err == nil {
+		t.Error("want an error for a missing input")
+	}
+	if err := run(new(strings.Builder), "", "missing.fc", corpus); err == nil {
+		t.Error("want an error for a missing program")
+	}
+}
@@ -0,0 +1,207 @@
+// Command focaldb runs a FOCAL-69 program under a debugger. It stops before
+// the first line and reads commands from standard input, the same input
+// the program's ASK statements read:
+//
+//	b 8.1    set a breakpoint at line 08.10
+//	d 8.1    delete the breakpoint at 08.10
+//	w A      watch an expression, stop when it changes
+//	s        run one statement
+//	n        run one statement, a DO to its return
+//	c        continue to a breakpoint, a watch change or the end
+//	p M-N    print an expression
+//	bt       print the DO stack
+//	l        list the current line
+//	q        quit
+//
+// An empty command repeats the last one. -b and -w set breakpoints and
+// watches up front:
+//
+//	focaldb -b 8.1,3.5 -w A,V,M lunar-lander.fc
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"gitlab.com/jhinrichsen/lunar-lander/focal"
+)
+
+const help = `b N    set a breakpoint at line N
+d N    delete the breakpoint at line N
+w X    watch expression X
+s      step one statement
+n      step over a DO
+c      continue
+p X    print expression X
+bt     print the DO stack
+l      list the current line
+q      quit`
+
+func number(x float64) string {
+	return strconv.FormatFloat(x, 'g', -1, 64)
+}
+
+// where prints the next statement and the watches.
+func where(w io.Writer, d *focal.Debugger, s focal.Stop) {
+	n, st, ok := d.Pos()
+	if !ok {
+		fmt.Fprintln(w, "program ended")
+		return
+	}
+	if s != focal.Stepped {
+		fmt.Fprintf(w, "%s ", s)
+	}
+	fmt.Fprintf(w, "at %s: %s\n", n, st)
+	for _, x := range d.Watches() {
+		mark := " "
+		if x.Changed {
+			mark = "*"
+		}
+		fmt.Fprintf(w, "%s %s = %s\n", mark, x.Expr, number(x.Value))
+	}
+}
+
+// debug reads commands from r until q, the end of the program or the end
+// of input. Program output and ASK input share w and r.
+func debug(d *focal.Debugger, r *bufio.Reader, w io.Writer) error {
+	where(w, d, focal.Stepped)
+	last := ""
+	for !d.Done() {
+		fmt.Fprint(w, "(fdb) ")
+		line, err := r.ReadString('\n')
+		if err != nil && line == "" {
+			if err == io.EOF {
+				fmt.Fprintln(w)
+				return nil
+			}
+			return err
+		}
+		line = strings.TrimSpace(line)
+		if line == "" {
+			line = last
+		}
+		last = line
+		cmd, arg, _ := strings.Cut(line, " ")
+		arg = strings.TrimSpace(arg)
+		var s focal.Stop
+		switch cmd {
+		case "":
+			continue
+		case "b", "d":
+			n, err := focal.ParseNum(arg)
+			if err != nil {
+				fmt.Fprintln(w, err)
+				continue
+			}
+			if cmd == "d" {
+				d.Clear(n)
+				continue
+			}
+			if err := d.Break(n); err != nil {
+				fmt.Fprintln(w, err)
+			}
+			continue
+		case "w":
+			if _, err := d.Watch(arg); err != nil {
+				fmt.Fprintln(w, err)
+			}
+			continue
+		case "p":
+			v, err := d.Print(arg)
+			if err != nil {
+				fmt.Fprintln(w, err)
+				continue
+			}
+			fmt.Fprintf(w, "%s = %s\n", arg, number(v))
+			continue
+		case "bt":
+			for _, f := range d.Stack() {
+				fmt.Fprintf(w, "%s: %s\n", f.Line, &focal.Do{Target: f.Target})
+			}
+			continue
+		case "l":
+			n, _, _ := d.Pos()
+			fmt.Fprintln(w, d.Prog.Line(n))
+			continue
+		case "q":
+			return nil
+		case "s":
+			err = d.Step()
+		case "n":
+			s, err = d.Next()
+		case "c":
+			s, err = d.Continue()
+		default:
+			fmt.Fprintln(w, help)
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		where(w, d, s)
+	}
+	return nil
+}
+
+func run(filename, breaks, watches string, in io.Reader, out io.Writer) error {
+	f, err := os.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	prog, err := focal.Parse(f)
+	if err != nil {
+		return fmt.Errorf("%s:%w", filename, err)
+	}
+	// The terminal wraps r without buffering again, so commands and ASK
+	// answers come from the same buffer.
+	r := bufio.NewReader(in)
+	d := focal.NewDebugger(prog, focal.NewTerminal(r, out))
+	for _, s := range strings.FieldsFunc(breaks, comma) {
+		n, err := focal.ParseNum(s)
+		if err != nil {
+			return err
+		}
+		if err := d.Break(n); err != nil {
+			return err
+		}
+	}
+	for _, s := range strings.FieldsFunc(watches, comma) {
+		if _, err := d.Watch(s); err != nil {
+			return err
+		}
+	}
+	err = debug(d, r, out)
+	if errors.Is(err, io.EOF) {
+		return nil
+	}
+	return err
+}
+
+func main() {
+	breaks := flag.String("b", "", "comma separated `lines` to break at")
+	watches := flag.String("w", "", "comma separated `expressions` to watch")
+	flag.Usage = func() {
+		fmt.Fprintln(flag.CommandLine.Output(), "usage: focaldb [-b lines] [-w expressions] program.fc")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if err := run(flag.Arg(0), *breaks, *watches, os.Stdin, os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func comma(r rune) bool {
+	return r == ','
+}
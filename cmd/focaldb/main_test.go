@@ -0,0 +1,58 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSession(t *testing.T) {
+	// Commands and ASK answers share the input: the 0 after c answers
+	// the first K=.
+	in := strings.Join([]string{
+		"s", "", "l", "c", "0", "bt", "p M-N", "p FRAN()", "d 6.1", "b 1.7", "w", "x", "q",
+	}, "\n") + "\n"
+	var out strings.Builder
+	if err := run("../../lunar-lander.fc", "6.1", "K", strings.NewReader(in), &out); err != nil {
+		t.Fatal(err)
+	}
+	got := out.String()
+	for _, s := range []string{
+		"at 01.04: T \"CONTROL CALLING",
+		"NECESSARY\nat 01.06:",
+		"VALUE\nat 01.08:",
+		"(fdb) 01.08 T \"BETWEEN 8",
+		"K=:breakpoint at 06.10: S L=L+S\n  K = 0\n",
+		"(fdb) 03.80: D 6\n",
+		"(fdb) M-N = 16000\n",
+		"FRAN() would change",
+		"no line 01.70",
+		"(fdb) col 1: want expression",
+		"step over a DO",
+	} {
+		if !strings.Contains(got, s) {
+			t.Errorf("missing %q in\n%s", s, got)
+		}
+	}
+}
+
+func TestRunToEnd(t *testing.T) {
+	in, out := "c\n"+strings.Repeat("200\n", 20)+"NO\n", new(strings.Builder)
+	if err := run("../../lunar-lander.fc", "", "", strings.NewReader(in), out); err != nil {
+		t.Fatal(err)
+	}
+	if s := out.String(); !strings.HasSuffix(s, "CONTROL OUT\n\n\nprogram ended\n") {
+		t.Errorf("want the end of the game, got %q", s[max(0, len(s)-80):])
+	}
+}
+
+func TestRunErrors(t *testing.T) {
+	for _, tt := range []struct{ breaks, watches string }{
+		{"6.11", ""},
+		{"40", ""},
+		{"", "A+"},
+	} {
+		if err := run("../../lunar-lander.fc", tt.breaks, tt.watches, strings.NewReader(""), new(strings.Builder)); err == nil {
+			t.Errorf("-b %q -w %q: want an error", tt.breaks, tt.watches)
+		}
+	}
+}
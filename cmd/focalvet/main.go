@@ -0,0 +1,81 @@
+// Command focalvet checks FOCAL-69 programs. It reports GOTO, IF and DO
+// targets that do not exist, lines that cannot be reached and variables that
+// may be used before they are set, and draws the control-flow graph as
+// Graphviz DOT.
+//
+//	focalvet [-dot file] program.fc...
+//
+// It exits with status 1 if it reported anything.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"gitlab.com/jhinrichsen/lunar-lander/focal"
+)
+
+// vet writes the diagnostics for filename to w, and the control-flow graph
+// in DOT to dot unless it is nil. It returns the number of diagnostics.
+func vet(w, dot io.Writer, filename string) (int, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+	prog, err := focal.Parse(f)
+	if err != nil {
+		return 0, fmt.Errorf("%s:%w", filename, err)
+	}
+	ds := focal.Check(prog)
+	for _, d := range ds {
+		if _, err := fmt.Fprintf(w, "%s:%s\n", filename, d); err != nil {
+			return 0, err
+		}
+	}
+	if dot != nil {
+		if err := focal.Flow(prog).WriteDot(dot, filename); err != nil {
+			return 0, err
+		}
+	}
+	return len(ds), nil
+}
+
+func main() {
+	dotfile := flag.String("dot", "", "write the control-flow graph in Graphviz DOT to `file`")
+	flag.Usage = func() {
+		fmt.Fprintln(flag.CommandLine.Output(), "usage: focalvet [-dot file] program.fc...")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() == 0 || (*dotfile != "" && flag.NArg() != 1) {
+		flag.Usage()
+		os.Exit(2)
+	}
+	var buf bytes.Buffer
+	var dot io.Writer
+	if *dotfile != "" {
+		dot = &buf
+	}
+	status := 0
+	for _, name := range flag.Args() {
+		n, err := vet(os.Stdout, dot, name)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		if n > 0 {
+			status = 1
+		}
+	}
+	if dot != nil {
+		if err := os.WriteFile(*dotfile, buf.Bytes(), 0o644); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	}
+	os.Exit(status)
+}
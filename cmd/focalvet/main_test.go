@@ -0,0 +1,77 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gitlab.com/jhinrichsen/lunar-lander/focal"
+)
+
+func TestVetLunarLander(t *testing.T) {
+	var out, dot bytes.Buffer
+	n, err := vet(&out, &dot, "../../lunar-lander.fc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "../../lunar-lander.fc:02.10: L may be used before it is set\n"
+	if n != 1 || out.String() != want {
+		t.Errorf("got %d diagnostics\n%s\nwant\n%s", n, out.String(), want)
+	}
+	if !strings.Contains(dot.String(), `"05.94" -> "01.20" [label="=0"];`) {
+		t.Error("DOT output lacks the G 1.2 of 05.94")
+	}
+}
+
+// doc/lunar-lander.dot must be what focalvet draws today.
+func TestDotUpToDate(t *testing.T) {
+	f, err := os.Open("../../lunar-lander.fc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	prog, err := focal.Parse(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got bytes.Buffer
+	if err := focal.Flow(prog).WriteDot(&got, "lunar-lander.fc"); err != nil {
+		t.Fatal(err)
+	}
+	want, err := os.ReadFile("../../doc/lunar-lander.dot")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(want, got.Bytes()) {
+		t.Error("doc/lunar-lander.dot is stale, run focalvet -dot doc/lunar-lander.dot lunar-lander.fc")
+	}
+}
+
+func TestVetErrors(t *testing.T) {
+	dir := t.TempDir()
+	bad := filepath.Join(dir, "bad.fc")
+	src := "01.10 G 2.1\n01.20 T X\n01.30 I (X) 1.1,3.1;D 5\n"
+	if err := os.WriteFile(bad, []byte(src), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	n, err := vet(&out, nil, bad)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{
+		":01.10: GOTO 2.1: no line 02.10",
+		":01.20: unreachable",
+		":01.30: IF 3.1: no line 03.10",
+		":01.30: DO 5: no group 5",
+	} {
+		if !strings.Contains(out.String(), s) {
+			t.Errorf("missing %q in\n%s", s, out.String())
+		}
+	}
+	if n != 5 {
+		t.Errorf("got %d diagnostics, want 5:\n%s", n, out.String())
+	}
+}
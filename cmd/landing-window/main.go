@@ -0,0 +1,187 @@
+// Command landing-window explores single-ignition burn schedules: coast for
+// n intervals, burn one fractional K, then keep a trailing K until touchdown.
+// For every ignition interval it solves for the fractional K that gives the
+// softest landing and prints achievable impact velocities and fuel margins.
+//
+// The softest landing sits on the border between flights that touch down
+// with fuel left and flights that brake too hard, hover and run dry.
+// That border is found by bisection on the exact subroutine 9 / line 07.10
+// physics of package lunar.
+//
+// -interval changes the decision interval of the scenario, -sweep compares
+// the softest landing of several intervals, every ignition until free fall
+// impact considered, to show what finer control buys.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"gitlab.com/jhinrichsen/lunar-lander/lunar"
+)
+
+// Window is the best landing for one ignition interval.
+type Window struct {
+	Ignition int     // number of coasting intervals before the burn
+	K        float64 // fractional burn rate of the ignition interval
+	Result   lunar.Result
+}
+
+// fly runs the schedule: n times 0, k, then trailing.
+func fly(sc lunar.Scenario, n int, k, trailing float64) lunar.Result {
+	ks := make([]float64, n+1)
+	ks[n] = k
+	return lunar.Simulate(sc, lunar.Schedule(ks, trailing))
+}
+
+// Solve finds the fractional K in the scenario's K range that minimises the
+// impact velocity when igniting after n coasting intervals.
+func Solve(sc lunar.Scenario, n int, trailing float64) Window {
+	best := Window{Ignition: n, K: sc.MinK, Result: fly(sc, n, sc.MinK, trailing)}
+	consider := func(k float64, r lunar.Result) {
+		if r.Impact < best.Result.Impact {
+			best.K, best.Result = k, r
+		}
+	}
+
+	// Coarse scan for the first K that runs the tanks dry.
+	prev := best.Result
+	for k := sc.MinK + 1; k <= sc.MaxK; k++ {
+		r := fly(sc, n, k, trailing)
+		consider(k, r)
+		if r.FuelOut && !prev.FuelOut {
+			// Bisect between k-1 (fuel left) and k (fuel out).
+			lo, hi := k-1, k
+			for range 60 {
+				mid := (lo + hi) / 2
+				r := fly(sc, n, mid, trailing)
+				consider(mid, r)
+				if r.FuelOut {
+					hi = mid
+				} else {
+					lo = mid
+				}
+			}
+			break
+		}
+		prev = r
+	}
+	return best
+}
+
+// Best solves every ignition interval before free fall impact and returns
+// the softest landing.
+func Best(sc lunar.Scenario, trailing float64) Window {
+	best := Solve(sc, 0, trailing)
+	for n := 1; float64(n)*sc.Interval < sc.FreeFallTime(); n++ {
+		if w := Solve(sc, n, trailing); w.Result.Impact < best.Result.Impact {
+			best = w
+		}
+	}
+	return best
+}
+
+func main() {
+	from := flag.Int("from", 0, "first ignition interval")
+	to := flag.Int("to", 15, "last ignition interval")
+	trailingK := flag.String("trailing", "", "constant K after the ignition interval (default the scenario's maxK)")
+	scenario := flag.String("scenario", "", "preset or JSON scenario file (default classic)")
+	interval := flag.Float64("interval", 0, "decision interval in secs (default the scenario's)")
+	sweep := flag.String("sweep", "", "comma separated decision intervals to compare the softest landings of")
+	flag.Parse()
+
+	sc, err := lunar.Open(*scenario)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if *interval != 0 {
+		sc.Interval = *interval
+		if err := sc.Validate(); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+	}
+	trailing, err := parseTrailing(sc, *trailingK)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	if *sweep != "" {
+		ts, err := parseIntervals(sc, *sweep)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+		reportSweep(os.Stdout, sc, ts, trailing)
+		return
+	}
+	report(os.Stdout, sc, *from, *to, trailing)
+}
+
+// parseTrailing parses the trailing K, the largest of sc if s is empty.
+func parseTrailing(sc lunar.Scenario, s string) (float64, error) {
+	if s == "" {
+		return sc.MaxK, nil
+	}
+	k, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, fmt.Errorf("trailing K: %w", err)
+	}
+	if !sc.Valid(k) {
+		return 0, fmt.Errorf("trailing K %g NOT POSSIBLE", k)
+	}
+	return k, nil
+}
+
+// parseIntervals parses a comma separated list of decision intervals
+// possible in sc.
+func parseIntervals(sc lunar.Scenario, s string) ([]float64, error) {
+	var ts []float64
+	for _, f := range strings.Split(s, ",") {
+		t, err := strconv.ParseFloat(strings.TrimSpace(f), 64)
+		if err != nil {
+			return nil, fmt.Errorf("interval: %w", err)
+		}
+		sc.Interval = t
+		if err := sc.Validate(); err != nil {
+			return nil, err
+		}
+		ts = append(ts, t)
+	}
+	return ts, nil
+}
+
+// reportSweep prints the softest landing of each decision interval.
+func reportSweep(w io.Writer, sc lunar.Scenario, intervals []float64, trailing float64) {
+	fmt.Fprintf(w, "TRAILING K=%g\n", trailing)
+	fmt.Fprintln(w, "INTERVAL,SECS   IGNITION,SECS   K,LBS/SEC      IMPACT,MPH   FUEL LEFT,LBS   ON MOON,SECS   VERDICT")
+	for _, t := range intervals {
+		sc.Interval = t
+		b := Best(sc, trailing)
+		fmt.Fprintf(w, "%13g   %13g   %12.8f   %10.2f   %13.2f   %12.2f   %s\n",
+			t, float64(b.Ignition)*t, b.K, b.Result.Impact, b.Result.Fuel,
+			b.Result.Time, b.Result.Verdict)
+	}
+}
+
+// report prints one table row per ignition interval.
+func report(w io.Writer, sc lunar.Scenario, from, to int, trailing float64) {
+	fmt.Fprintf(w, "TRAILING K=%g\n", trailing)
+	fmt.Fprintln(w, "IGNITION,SECS   K,LBS/SEC      IMPACT,MPH   FUEL LEFT,LBS   ON MOON,SECS   VERDICT")
+	for n := from; n <= to; n++ {
+		b := Solve(sc, n, trailing)
+		k := fmt.Sprintf("%12.8f", b.K)
+		if b.Result.Intervals <= n {
+			// On the moon before the engine ever fired.
+			k = fmt.Sprintf("%12s", "-")
+		}
+		fmt.Fprintf(w, "%13g   %s   %10.2f   %13.2f   %12.2f   %s\n",
+			float64(n)*sc.Interval, k, b.Result.Impact, b.Result.Fuel,
+			b.Result.Time, b.Result.Verdict)
+	}
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"bytes"
+	"math"
+	"strings"
+	"testing"
+
+	"gitlab.com/jhinrichsen/lunar-lander/lunar"
+)
+
+// TestSolveMartin recovers Martin C. Martin's single-ignition schedule:
+// coast 70 seconds, burn 164.31426784 lbs/sec, then full burn.
+func TestSolveMartin(t *testing.T) {
+	w := Solve(lunar.Classic(), 7, 200)
+	if math.Abs(w.K-164.31426784) > 1e-6 {
+		t.Errorf("want K=164.31426784, got %.8f", w.K)
+	}
+	if w.Result.Impact > 3.6 {
+		t.Errorf("want impact below 3.6 MPH, got %.2f", w.Result.Impact)
+	}
+	if w.Result.Verdict != lunar.Good {
+		t.Errorf("want %q, got %q", lunar.Good, w.Result.Verdict)
+	}
+}
+
+// TestSolveNoBracket picks an endpoint when every K lands the same way.
+func TestSolveNoBracket(t *testing.T) {
+	w := Solve(lunar.Classic(), 8, 200)
+	if w.K != 200 {
+		t.Errorf("igniting too late wants maximum burn, got K=%g", w.K)
+	}
+	if w.Result.FuelOut {
+		t.Error("want fuel left when igniting too late")
+	}
+}
+
+func TestReport(t *testing.T) {
+	var buf bytes.Buffer
+	report(&buf, lunar.Classic(), 7, 12, 200)
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2+6 {
+		t.Fatalf("want 8 lines, got %d:\n%s", len(lines), buf.String())
+	}
+	if !strings.Contains(lines[2], "164.31426785") {
+		t.Errorf("want ignition at 70 secs to burn 164.31426785, got %q", lines[2])
+	}
+	if f := strings.Fields(lines[7]); f[1] != "-" {
+		t.Errorf("want no K when landing before ignition, got %q", lines[7])
+	}
+}
+
+func TestReportSweep(t *testing.T) {
+	ts, err := parseIntervals(lunar.Classic(), "5, 10")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	reportSweep(&buf, lunar.Classic(), ts, 200)
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2+2 {
+		t.Fatalf("want 4 lines, got %d:\n%s", len(lines), buf.String())
+	}
+	// Martin's schedule is the best at 10 secs
+	if f := strings.Fields(lines[3]); f[0] != "10" || f[1] != "70" || f[2] != "164.31426785" {
+		t.Errorf("want ignition at 70 secs to burn 164.31426785, got %q", lines[3])
+	}
+	if f := strings.Fields(lines[2]); f[0] != "5" || f[6] != "PERFECT" {
+		t.Errorf("want a perfect landing at 5 secs, got %q", lines[2])
+	}
+	for _, s := range []string{"0", "61", "2.5", "x"} {
+		if _, err := parseIntervals(lunar.Classic(), s); err == nil {
+			t.Errorf("%s: want error", s)
+		}
+	}
+}
+
+func TestParseTrailing(t *testing.T) {
+	asteroid, ok := lunar.Preset("asteroid")
+	if !ok {
+		t.Fatal("no asteroid preset")
+	}
+	if k, err := parseTrailing(asteroid, ""); err != nil || k != asteroid.MaxK {
+		t.Errorf("want the max K %g of the asteroid, got %g, %v", asteroid.MaxK, k, err)
+	}
+	if k, err := parseTrailing(asteroid, "0"); err != nil || k != 0 {
+		t.Errorf("want 0, got %g, %v", k, err)
+	}
+	for _, s := range []string{"200", "x"} {
+		if _, err := parseTrailing(asteroid, s); err == nil {
+			t.Errorf("%q: want error", s)
+		}
+	}
+}
@@ -0,0 +1,123 @@
+// Command lunar plays lunar-lander.fc on the terminal, either the classic
+// flight, a preset for another gravitational body or a scenario loaded from
+// a JSON file, optionally randomized for practice runs. -seed also draws
+// the malfunctions of the scenario. -tty prints at teletype speed, -paper
+// keeps the session as a page image.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"gitlab.com/jhinrichsen/lunar-lander/asr33"
+	"gitlab.com/jhinrichsen/lunar-lander/lunar"
+	"gitlab.com/jhinrichsen/lunar-lander/trace"
+)
+
+func main() {
+	scenario := flag.String("scenario", "", "preset ("+strings.Join(lunar.Presets(), ", ")+") or JSON scenario file (default classic)")
+	interval := flag.Float64("interval", 0, "decision interval in secs (default the scenario's)")
+	planar := flag.Bool("planar", false, "fly in two dimensions: drift sideways and pitch the engine (P) against it")
+	random := flag.Bool("random", false, "randomize initial altitude, velocity and fuel")
+	seed := flag.Uint64("seed", 0, "seed for -random (default current time) and the malfunctions of the scenario")
+	units := flag.String("units", "imperial", "display units: imperial, metric or si")
+	traceFile := flag.String("trace", "", "record the variables after each FOCAL line to `file`")
+	tty := flag.Float64("tty", 0, "print like an ASR-33 at `speed` times 10 characters per second, 0 at once")
+	paperFile := flag.String("paper", "", "write the session as teletype paper to PNG `file`")
+	flag.Parse()
+
+	u, err := lunar.ParseUnits(*units)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	sc, err := lunar.Open(*scenario)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if *interval != 0 {
+		sc.Interval = *interval
+		if err := sc.Validate(); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+	}
+	if *planar && sc.Horizontal == nil {
+		h := lunar.ClassicHorizontal()
+		sc.Horizontal = &h
+	}
+	if *random {
+		if *seed == 0 {
+			*seed = uint64(time.Now().UnixNano())
+		}
+		fmt.Fprintf(os.Stderr, "seed %d\n", *seed)
+		sc = lunar.Randomize(sc, *seed)
+	}
+	if *seed != 0 && sc.Malfunctions != nil {
+		m := *sc.Malfunctions
+		m.Seed = *seed
+		sc.Malfunctions = &m
+	}
+	var (
+		in  io.Reader = os.Stdin
+		out io.Writer = os.Stdout
+		p   asr33.Paper
+	)
+	if *paperFile != "" {
+		out = io.MultiWriter(out, &p)
+		// answers typed at a terminal echo onto the paper, piped ones
+		// are read ahead and would land in the wrong place
+		if fi, err := os.Stdin.Stat(); err == nil && fi.Mode()&os.ModeCharDevice != 0 {
+			in = io.TeeReader(in, &p)
+		}
+	}
+	if *tty > 0 || *paperFile != "" {
+		out = asr33.NewWriter(out, *tty)
+	}
+	g := lunar.NewGame(sc, in, out)
+	g.Units = u
+	var tf *os.File
+	if *traceFile != "" {
+		if tf, err = os.Create(*traceFile); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		g.Trace = trace.NewRecorder(tf)
+	}
+	g.Run()
+	// flushed here rather than deferred, os.Exit below skips deferred calls
+	if tf != nil {
+		err := g.Trace.Flush()
+		if cerr := tf.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	}
+	if *paperFile != "" {
+		if err := writePaper(*paperFile, &p); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	}
+}
+
+func writePaper(filename string, p *asr33.Paper) error {
+	f, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	if err := p.PNG(f, 2); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,129 @@
+// Command pal8 inspects and builds PDP-8 paper tapes.
+//
+//	pal8 info simh/focal69.bin          blocks and their addresses
+//	pal8 dis simh/focal69.bin > f.pal   disassemble into PAL-8
+//	pal8 asm f.pal focal.rim            assemble a program into a tape
+//	pal8 conv simh/focal69.bin f.rim    convert between BIN and RIM
+//
+// Tapes named .rim are in RIM format, all others in BIN. The listing of
+// dis assembles back into the same words.
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gitlab.com/jhinrichsen/lunar-lander/pdp8"
+)
+
+// leader is the number of leader and trailer frames written, as on
+// focal69.bin.
+const leader = 20
+
+func rim(name string) bool {
+	return strings.EqualFold(filepath.Ext(name), ".rim")
+}
+
+func read(name string) ([]pdp8.Block, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var bs []pdp8.Block
+	if rim(name) {
+		bs, err = pdp8.ReadRIM(f)
+	} else {
+		bs, err = pdp8.ReadBIN(f)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	return bs, nil
+}
+
+func write(name string, bs []pdp8.Block) error {
+	var b bytes.Buffer
+	var err error
+	if rim(name) {
+		err = pdp8.WriteRIM(&b, bs, leader)
+	} else {
+		err = pdp8.WriteBIN(&b, bs, leader)
+	}
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(name, b.Bytes(), 0o644)
+}
+
+func info(w io.Writer, bs []pdp8.Block) error {
+	n := 0
+	for _, b := range bs {
+		last := b.Addr(len(b.Words) - 1)
+		if len(b.Words) == 0 {
+			last = b.Addr(0)
+		}
+		fmt.Fprintf(w, "%05o-%05o %5d words\n", b.Addr(0), last, len(b.Words))
+		n += len(b.Words)
+	}
+	_, err := fmt.Fprintf(w, "%d blocks, %d words\n", len(bs), n)
+	return err
+}
+
+func assemble(src, dst string) error {
+	f, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	bs, err := pdp8.Assemble(f)
+	if err != nil {
+		return fmt.Errorf("%s: %w", src, err)
+	}
+	return write(dst, bs)
+}
+
+var errUsage = errors.New("usage: pal8 info tape | dis tape | asm program.pal tape | conv tape tape")
+
+func cmd(w io.Writer, args []string) error {
+	switch {
+	case len(args) == 2 && (args[0] == "info" || args[0] == "dis"):
+		bs, err := read(args[1])
+		if err != nil {
+			return err
+		}
+		if args[0] == "info" {
+			return info(w, bs)
+		}
+		return pdp8.Listing(w, bs)
+	case len(args) == 3 && args[0] == "asm":
+		return assemble(args[1], args[2])
+	case len(args) == 3 && args[0] == "conv":
+		bs, err := read(args[1])
+		if err != nil {
+			return err
+		}
+		return write(args[2], bs)
+	}
+	return errUsage
+}
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintln(flag.CommandLine.Output(), errUsage)
+	}
+	flag.Parse()
+	if err := cmd(os.Stdout, flag.Args()); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		if err == errUsage {
+			os.Exit(2)
+		}
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const focal = "../../simh/focal69.bin"
+
+func TestInfo(t *testing.T) {
+	var out strings.Builder
+	if err := cmd(&out, []string{"info", focal}); err != nil {
+		t.Fatal(err)
+	}
+	want := `00000-03117  1616 words
+03206-03217    10 words
+04300-07577  1728 words
+3 blocks, 3354 words
+`
+	if out.String() != want {
+		t.Errorf("want\n%s\ngot\n%s", want, out.String())
+	}
+}
+
+// TestRoundTrip converts focal69.bin to RIM and back, and assembles its
+// listing into the same tape.
+func TestRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	rim, bin := filepath.Join(dir, "focal.rim"), filepath.Join(dir, "focal.bin")
+	if err := cmd(nil, []string{"conv", focal, rim}); err != nil {
+		t.Fatal(err)
+	}
+	if err := cmd(nil, []string{"conv", rim, bin}); err != nil {
+		t.Fatal(err)
+	}
+	same(t, focal, bin)
+
+	var listing bytes.Buffer
+	if err := cmd(&listing, []string{"dis", focal}); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(listing.String(), "\tJMP I 0003      /00001 5403\n") {
+		t.Errorf("unexpected listing\n%.300s", listing.String())
+	}
+	pal := filepath.Join(dir, "focal.pal")
+	if err := os.WriteFile(pal, listing.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := cmd(nil, []string{"asm", pal, bin}); err != nil {
+		t.Fatal(err)
+	}
+	same(t, focal, bin)
+}
+
+func same(t *testing.T, a, b string) {
+	t.Helper()
+	x, err := os.ReadFile(a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	y, err := os.ReadFile(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(x, y) {
+		t.Errorf("%s and %s differ", a, b)
+	}
+}
+
+func TestErrors(t *testing.T) {
+	dir := t.TempDir()
+	bad := filepath.Join(dir, "bad.pal")
+	if err := os.WriteFile(bad, []byte("TAD NOWHERE\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	for _, args := range [][]string{
+		{"info", "main.go"},
+		{"dis", "missing.bin"},
+		{"asm", bad, filepath.Join(dir, "bad.bin")},
+		{"asm", "missing.pal", filepath.Join(dir, "bad.bin")},
+		{"conv", "missing.bin", filepath.Join(dir, "x.rim")},
+	} {
+		if err := cmd(new(strings.Builder), args); err == nil || err == errUsage {
+			t.Errorf("%q: want an error, got %v", args, err)
+		}
+	}
+	for _, args := range [][]string{nil, {"info"}, {"asm", "x"}, {"dump", "x"}} {
+		if err := cmd(nil, args); err != errUsage {
+			t.Errorf("%q: want the usage, got %v", args, err)
+		}
+	}
+}
@@ -0,0 +1,148 @@
+// Command plot charts a landing: it flies a K schedule, or reads a telemetry
+// file, and writes altitude, velocity and fuel over time as well as the
+// altitude/velocity phase plane as SVG and PNG files.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"gitlab.com/jhinrichsen/lunar-lander/lunar"
+	"gitlab.com/jhinrichsen/lunar-lander/plot"
+)
+
+func main() {
+	ks := flag.String("k", "", "comma separated K schedule, one value per interval")
+	trailing := flag.Float64("trailing", 0, "K after the schedule is exhausted")
+	scenario := flag.String("scenario", "", "preset or JSON scenario file (default classic)")
+	interval := flag.Float64("interval", 0, "decision interval in secs (default the scenario's)")
+	telemetry := flag.String("telemetry", "", "read samples from this CSV file instead of flying")
+	csvOut := flag.String("csv", "", "also write the samples to this CSV file")
+	dir := flag.String("o", ".", "output directory")
+	formats := flag.String("format", "svg,png", "comma separated output formats")
+	flag.Parse()
+
+	ss, err := samples(*telemetry, *scenario, *interval, *ks, *trailing)
+	if err == nil && *csvOut != "" {
+		err = writeCSV(*csvOut, ss)
+	}
+	if err == nil {
+		err = write(*dir, strings.Split(*formats, ","), Charts(ss))
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// samples reads a telemetry file or flies the schedule, every interval
+// secs unless 0.
+func samples(telemetry, scenario string, interval float64, ks string, trailing float64) ([]lunar.Sample, error) {
+	if telemetry != "" {
+		f, err := os.Open(telemetry)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		return lunar.ReadTelemetry(f)
+	}
+	sc, err := lunar.Open(scenario)
+	if err != nil {
+		return nil, err
+	}
+	if interval != 0 {
+		sc.Interval = interval
+		if err := sc.Validate(); err != nil {
+			return nil, err
+		}
+	}
+	schedule, err := parseK(ks)
+	if err != nil {
+		return nil, err
+	}
+	ss, _ := lunar.Record(sc, lunar.Schedule(schedule, trailing))
+	return ss, nil
+}
+
+// parseK parses a comma separated list of burn rates.
+func parseK(s string) ([]float64, error) {
+	var ks []float64
+	for _, f := range strings.FieldsFunc(s, func(r rune) bool { return r == ',' || r == ' ' }) {
+		k, err := strconv.ParseFloat(f, 64)
+		if err != nil {
+			return nil, fmt.Errorf("K schedule: %w", err)
+		}
+		ks = append(ks, k)
+	}
+	return ks, nil
+}
+
+func writeCSV(filename string, ss []lunar.Sample) error {
+	f, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	if err := lunar.WriteTelemetry(f, ss); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// Charts returns the four charts of a landing, keyed by file base name.
+func Charts(ss []lunar.Sample) map[string]*plot.Chart {
+	var t, a, v, fuel []float64
+	for _, s := range ss {
+		t = append(t, s.Time)
+		a = append(a, s.Altitude)
+		v = append(v, s.Velocity)
+		fuel = append(fuel, s.Fuel)
+	}
+	chart := func(title, xl, yl string, x, y []float64) *plot.Chart {
+		return &plot.Chart{
+			Title:  title,
+			XLabel: xl,
+			YLabel: yl,
+			Series: []plot.Series{{X: x, Y: y}},
+		}
+	}
+	return map[string]*plot.Chart{
+		"altitude": chart("ALTITUDE", "TIME,SECS", "ALTITUDE,MILES", t, a),
+		"velocity": chart("VELOCITY", "TIME,SECS", "VELOCITY,MPH", t, v),
+		"fuel":     chart("FUEL", "TIME,SECS", "FUEL,LBS", t, fuel),
+		"phase":    chart("PHASE PLANE", "VELOCITY,MPH", "ALTITUDE,MILES", v, a),
+	}
+}
+
+// write renders every chart in every format into dir.
+func write(dir string, formats []string, charts map[string]*plot.Chart) error {
+	for _, format := range formats {
+		if format != "svg" && format != "png" {
+			return fmt.Errorf("unknown format %q, want svg or png", format)
+		}
+	}
+	for name, c := range charts {
+		for _, format := range formats {
+			f, err := os.Create(filepath.Join(dir, name+"."+format))
+			if err != nil {
+				return err
+			}
+			if format == "svg" {
+				err = c.SVG(f)
+			} else {
+				err = c.PNG(f)
+			}
+			if cerr := f.Close(); err == nil {
+				err = cerr
+			}
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestParseK(t *testing.T) {
+	got, err := parseK("0, 0,164.31426784,200")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []float64{0, 0, 164.31426784, 200}; !slices.Equal(got, want) {
+		t.Errorf("want %v, got %v", want, got)
+	}
+	if _, err := parseK("0,x"); err == nil {
+		t.Error("want error")
+	}
+}
+
+func TestWriteCharts(t *testing.T) {
+	dir := t.TempDir()
+	ss, err := samples("", "", 0, "0,0,0,0,0,0,0,164.31426784", 200)
+	if err != nil {
+		t.Fatal(err)
+	}
+	csv := filepath.Join(dir, "telemetry.csv")
+	if err := writeCSV(csv, ss); err != nil {
+		t.Fatal(err)
+	}
+	// Plot from the telemetry file written above.
+	if ss, err = samples(csv, "", 0, "", 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := write(dir, []string{"svg", "png"}, Charts(ss)); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"altitude", "velocity", "fuel", "phase"} {
+		for _, ext := range []string{".svg", ".png"} {
+			if fi, err := os.Stat(filepath.Join(dir, name+ext)); err != nil || fi.Size() == 0 {
+				t.Errorf("%s%s: missing or empty", name, ext)
+			}
+		}
+	}
+	if err := write(dir, []string{"svg", "gif"}, Charts(ss)); err == nil {
+		t.Error("want error for unknown format")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "altitude.gif")); err == nil {
+		t.Error("want no file for an unknown format")
+	}
+}
+
+func TestSamplesInterval(t *testing.T) {
+	ss, err := samples("", "", 60, "0", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ss[1].Time != 60 {
+		t.Errorf("want the first step to take 60 secs, got %g", ss[1].Time)
+	}
+	if _, err := samples("", "", 61, "0", 0); err == nil {
+		t.Error("want error for a 61 secs interval")
+	}
+}
@@ -0,0 +1,7 @@
+// Command reference is lunar-lander.fc translated to Go by focal2go: a port
+// derived mechanically from the listing instead of by hand, to diff the
+// hand ports against. Regenerate it with go generate after changing the
+// listing or the translator.
+package main
+
+//go:generate go run ../focal2go -o main.go ../../lunar-lander.fc
@@ -0,0 +1,411 @@
+// Code generated by focal2go from lunar-lander.fc. DO NOT EDIT.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+
+	"gitlab.com/jhinrichsen/lunar-lander/focal"
+)
+
+// program holds the variables.
+type program struct {
+	tty *focal.Terminal
+
+	A float64
+	G float64
+	I float64
+	J float64
+	K float64
+	L float64
+	M float64
+	N float64
+	P float64
+	Q float64
+	S float64
+	T float64
+	V float64
+	W float64
+	X float64
+	Z float64
+}
+
+// erase clears all variables (E).
+func (p *program) erase() {
+	*p = program{tty: p.tty}
+}
+
+// vars returns the variables, in which ASK answers are evaluated.
+func (p *program) vars() map[string]float64 {
+	return map[string]float64{"A": p.A, "G": p.G, "I": p.I, "J": p.J, "K": p.K, "L": p.L, "M": p.M, "N": p.N, "P": p.P, "Q": p.Q, "S": p.S, "T": p.T, "V": p.V, "W": p.W, "X": p.X, "Z": p.Z}
+}
+
+// execute runs the program on a terminal reading in and printing to out.
+func execute(in io.Reader, out io.Writer) error {
+	p := &program{tty: focal.NewTerminal(in, out)}
+	p.erase()
+	return p.tty.Run(p.run)
+}
+
+func main() {
+	if err := execute(os.Stdin, os.Stdout); err != nil && !errors.Is(err, io.EOF) {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// run executes the program from its first line.
+func (p *program) run() {
+	// 01.04 T "CONTROL CALLING LUNAR MODULE. MANUAL CONTROL IS NECESSARY"!
+	p.tty.Text("CONTROL CALLING LUNAR MODULE. MANUAL CONTROL IS NECESSARY")
+	p.tty.Newline()
+
+	// 01.06 T "YOU MAY RESET FUEL RATE K EACH 10 SECS TO 0 OR ANY VALUE"!
+	p.tty.Text("YOU MAY RESET FUEL RATE K EACH 10 SECS TO 0 OR ANY VALUE")
+	p.tty.Newline()
+
+	// 01.08 T "BETWEEN 8 & 200 LBS/SEC. YOU'VE 16000 LBS FUEL. ESTIMATED"!
+	p.tty.Text("BETWEEN 8 & 200 LBS/SEC. YOU'VE 16000 LBS FUEL. ESTIMATED")
+	p.tty.Newline()
+
+	// 01.11 T "FREE FALL IMPACT TIME-120 SECS. CAPSULE WEIGHT-32500 LBS"!
+	p.tty.Text("FREE FALL IMPACT TIME-120 SECS. CAPSULE WEIGHT-32500 LBS")
+	p.tty.Newline()
+
+	// 01.20 T "FIRST RADAR CHECK COMING UP"!!!;E
+l0120:
+	p.tty.Text("FIRST RADAR CHECK COMING UP")
+	p.tty.Newline()
+	p.tty.Newline()
+	p.tty.Newline()
+	p.erase()
+
+	// 01.30 T "COMMENCE LANDING PROCEDURE"!"TIME,SECS   ALTITUDE,"
+	p.tty.Text("COMMENCE LANDING PROCEDURE")
+	p.tty.Newline()
+	p.tty.Text("TIME,SECS   ALTITUDE,")
+
+	// 01.40 T "MILES+FEET   VELOCITY,MPH   FUEL,LBS   FUEL RATE"!
+	p.tty.Text("MILES+FEET   VELOCITY,MPH   FUEL,LBS   FUEL RATE")
+	p.tty.Newline()
+
+	// 01.50 S A=120;S V=1;S M=32500;S N=16500;S G=.001;S Z=1.8
+	p.A = 120
+	p.V = 1
+	p.M = 32500
+	p.N = 16500
+	p.G = 0.001
+	p.Z = 1.8
+
+	// 02.10 T "    ",%3,L,"       ",FITR(A),"  ",%4,5280*(A-FITR(A))
+l0210:
+	p.tty.Text("    ")
+	p.tty.Format(3, 0)
+	p.tty.Number(p.L)
+	p.tty.Text("       ")
+	p.tty.Number(math.Trunc(p.A))
+	p.tty.Text("  ")
+	p.tty.Format(4, 0)
+	p.tty.Number(5280 * (p.A - math.Trunc(p.A)))
+
+	// 02.20 T %6.02,"       ",3600*V,"    ",%6.01,M-N,"      K=";A K;S T=10
+	p.tty.Format(6, 2)
+	p.tty.Text("       ")
+	p.tty.Number(3600 * p.V)
+	p.tty.Text("    ")
+	p.tty.Format(6, 1)
+	p.tty.Number(p.M - p.N)
+	p.tty.Text("      K=")
+	p.K = p.tty.Ask(p.vars())
+	p.T = 10
+
+	// 02.70 T %7.02;I (200-K)2.72;I (8-K)3.1,3.1;I (K)2.72,3.1
+l0270:
+	p.tty.Format(7, 2)
+	if 200-p.K < 0 {
+		goto l0272
+	}
+	switch x := 8 - p.K; {
+	case x < 0:
+		goto l0310
+	case x == 0:
+		goto l0310
+	}
+	switch x := p.K; {
+	case x < 0:
+		goto l0272
+	case x == 0:
+		goto l0310
+	}
+
+	// 02.72 T "NOT POSSIBLE";F X=1,51;T "."
+l0272:
+	p.tty.Text("NOT POSSIBLE")
+	for p.X = 1; p.X <= 51; p.X++ {
+		p.tty.Text(".")
+	}
+
+	// 02.73 T "K=";A K;G 2.7
+	p.tty.Text("K=")
+	p.K = p.tty.Ask(p.vars())
+	goto l0270
+
+	// 03.10 I (M-N-.001)4.1;I (T-.001)2.1;S S=T
+l0310:
+	if p.M-p.N-0.001 < 0 {
+		goto l0410
+	}
+	if p.T-0.001 < 0 {
+		goto l0210
+	}
+	p.S = p.T
+
+	// 03.40 I ((N+S*K)-M)3.5,3.5;S S=(M-N)/K
+	switch x := p.N + p.S*p.K - p.M; {
+	case x < 0:
+		goto l0350
+	case x == 0:
+		goto l0350
+	}
+	p.S = (p.M - p.N) / p.K
+
+	// 03.50 D 9;I (I)7.1,7.1;I (V)3.8,3.8;I (J)8.1
+l0350:
+	p.group9()
+	switch x := p.I; {
+	case x < 0:
+		goto l0710
+	case x == 0:
+		goto l0710
+	}
+	switch x := p.V; {
+	case x < 0:
+		goto l0380
+	case x == 0:
+		goto l0380
+	}
+	if p.J < 0 {
+		goto l0810
+	}
+
+	// 03.80 D 6;G 3.1
+l0380:
+	p.group6()
+	goto l0310
+
+	// 04.10 T "FUEL OUT AT",L," SECS"!
+l0410:
+	p.tty.Text("FUEL OUT AT")
+	p.tty.Number(p.L)
+	p.tty.Text(" SECS")
+	p.tty.Newline()
+
+	// 04.40 S S=(FSQT(V*V+2*A*G)-V)/G;S V=V+G*S;S L=L+S
+	p.S = (math.Sqrt(p.V*p.V+2*p.A*p.G) - p.V) / p.G
+	p.V = p.V + p.G*p.S
+	p.L = p.L + p.S
+
+	// 05.10 T "ON THE MOON AT",L," SECS"!;S W=3600*V
+l0510:
+	p.tty.Text("ON THE MOON AT")
+	p.tty.Number(p.L)
+	p.tty.Text(" SECS")
+	p.tty.Newline()
+	p.W = 3600 * p.V
+
+	// 05.20 T "IMPACT VELOCITY OF",W,"M.P.H."!,"FUEL LEFT:"M-N," LBS"!
+	p.tty.Text("IMPACT VELOCITY OF")
+	p.tty.Number(p.W)
+	p.tty.Text("M.P.H.")
+	p.tty.Newline()
+	p.tty.Text("FUEL LEFT:")
+	p.tty.Number(p.M - p.N)
+	p.tty.Text(" LBS")
+	p.tty.Newline()
+
+	// 05.40 I (1-W)5.5,5.5;T "PERFECT LANDING !-(LUCKY)"!;G 5.9
+	switch x := 1 - p.W; {
+	case x < 0:
+		goto l0550
+	case x == 0:
+		goto l0550
+	}
+	p.tty.Text("PERFECT LANDING !-(LUCKY)")
+	p.tty.Newline()
+	goto l0590
+
+	// 05.50 I (10-W)5.6,5.6;T "GOOD LANDING-(COULD BE BETTER)"!;G 5.9
+l0550:
+	switch x := 10 - p.W; {
+	case x < 0:
+		goto l0560
+	case x == 0:
+		goto l0560
+	}
+	p.tty.Text("GOOD LANDING-(COULD BE BETTER)")
+	p.tty.Newline()
+	goto l0590
+
+	// 05.60 I (22-W)5.7,5.7;T "CONGRATULATIONS ON A POOR LANDING"!;G 5.9
+l0560:
+	switch x := 22 - p.W; {
+	case x < 0:
+		goto l0570
+	case x == 0:
+		goto l0570
+	}
+	p.tty.Text("CONGRATULATIONS ON A POOR LANDING")
+	p.tty.Newline()
+	goto l0590
+
+	// 05.70 I (40-W)5.81,5.81;T "CRAFT DAMAGE. GOOD LUCK"!;G 5.9
+l0570:
+	switch x := 40 - p.W; {
+	case x < 0:
+		goto l0581
+	case x == 0:
+		goto l0581
+	}
+	p.tty.Text("CRAFT DAMAGE. GOOD LUCK")
+	p.tty.Newline()
+	goto l0590
+
+	// 05.81 I (60-W)5.82,5.82;T "CRASH LANDING-YOU'VE 5 HRS OXYGEN"!;G 5.9
+l0581:
+	switch x := 60 - p.W; {
+	case x < 0:
+		goto l0582
+	case x == 0:
+		goto l0582
+	}
+	p.tty.Text("CRASH LANDING-YOU'VE 5 HRS OXYGEN")
+	p.tty.Newline()
+	goto l0590
+
+	// 05.82 T "SORRY,BUT THERE WERE NO SURVIVORS-YOU BLEW IT!"!"IN "
+l0582:
+	p.tty.Text("SORRY,BUT THERE WERE NO SURVIVORS-YOU BLEW IT!")
+	p.tty.Newline()
+	p.tty.Text("IN ")
+
+	// 05.83 T "FACT YOU BLASTED A NEW LUNAR CRATER",W*.277777," FT.DEEP"!
+	p.tty.Text("FACT YOU BLASTED A NEW LUNAR CRATER")
+	p.tty.Number(p.W * 0.277777)
+	p.tty.Text(" FT.DEEP")
+	p.tty.Newline()
+
+	// 05.90 T !!!!"TRY AGAIN?"!
+l0590:
+	p.tty.Newline()
+	p.tty.Newline()
+	p.tty.Newline()
+	p.tty.Newline()
+	p.tty.Text("TRY AGAIN?")
+	p.tty.Newline()
+
+	// 05.92 A "(ANS. YES OR NO)"P;I (P-0NO)5.94,5.98
+l0592:
+	p.tty.Text("(ANS. YES OR NO)")
+	p.P = p.tty.Ask(p.vars())
+	switch x := p.P - 155; {
+	case x < 0:
+		goto l0594
+	case x == 0:
+		goto l0598
+	}
+
+	// 05.94 I (P-0YES)5.92,1.2,5.92
+l0594:
+	switch x := p.P - 2569; {
+	case x < 0:
+		goto l0592
+	case x == 0:
+		goto l0120
+	default:
+		goto l0592
+	}
+
+	// 05.98 T "CONTROL OUT"!!!;Q
+l0598:
+	p.tty.Text("CONTROL OUT")
+	p.tty.Newline()
+	p.tty.Newline()
+	p.tty.Newline()
+	p.tty.Quit()
+
+	// 06.10 S L=L+S;S T=T-S;S M=M-S*K;S A=I;S V=J
+	p.L = p.L + p.S
+	p.T = p.T - p.S
+	p.M = p.M - p.S*p.K
+	p.A = p.I
+	p.V = p.J
+
+	// 07.10 I (S-.005)5.1;S S=2*A/(V+FSQT(V*V+2*A*(G-Z*K/M)))
+l0710:
+	if p.S-0.005 < 0 {
+		goto l0510
+	}
+	p.S = 2 * p.A / (p.V + math.Sqrt(p.V*p.V+2*p.A*(p.G-p.Z*p.K/p.M)))
+
+	// 07.30 D 9;D 6;G 7.1
+	p.group9()
+	p.group6()
+	goto l0710
+
+	// 08.10 S W=(1-M*G/(Z*K))/2;S S=M*V/(Z*K*(W+FSQT(W*W+V/Z)))+.05;D 9
+l0810:
+	p.W = (1 - p.M*p.G/(p.Z*p.K)) / 2
+	p.S = p.M*p.V/(p.Z*p.K*(p.W+math.Sqrt(p.W*p.W+p.V/p.Z))) + 0.05
+	p.group9()
+
+	// 08.30 I (I)7.1,7.1;D 6;I (-J)3.1,3.1;I (V)3.1,3.1,8.1
+	switch x := p.I; {
+	case x < 0:
+		goto l0710
+	case x == 0:
+		goto l0710
+	}
+	p.group6()
+	switch x := -p.J; {
+	case x < 0:
+		goto l0310
+	case x == 0:
+		goto l0310
+	}
+	switch x := p.V; {
+	case x < 0:
+		goto l0310
+	case x == 0:
+		goto l0310
+	default:
+		goto l0810
+	}
+
+	// 09.10 S Q=S*K/M;S J=V+G*S+Z*(-Q-Q^2/2-Q^3/3-Q^4/4-Q^5/5) (unreachable here)
+
+	// 09.40 S I=A-G*S*S/2-V*S+Z*S*(Q/2+Q^2/6+Q^3/12+Q^4/20+Q^5/30) (unreachable here)
+}
+
+// group6 is group 6, called by DO.
+func (p *program) group6() {
+	// 06.10 S L=L+S;S T=T-S;S M=M-S*K;S A=I;S V=J
+	p.L = p.L + p.S
+	p.T = p.T - p.S
+	p.M = p.M - p.S*p.K
+	p.A = p.I
+	p.V = p.J
+}
+
+// group9 is group 9, called by DO.
+func (p *program) group9() {
+	// 09.10 S Q=S*K/M;S J=V+G*S+Z*(-Q-Q^2/2-Q^3/3-Q^4/4-Q^5/5)
+	p.Q = p.S * p.K / p.M
+	p.J = p.V + p.G*p.S + p.Z*(-p.Q-math.Pow(p.Q, 2)/2-math.Pow(p.Q, 3)/3-math.Pow(p.Q, 4)/4-math.Pow(p.Q, 5)/5)
+
+	// 09.40 S I=A-G*S*S/2-V*S+Z*S*(Q/2+Q^2/6+Q^3/12+Q^4/20+Q^5/30)
+	p.I = p.A - p.G*p.S*p.S/2 - p.V*p.S + p.Z*p.S*(p.Q/2+math.Pow(p.Q, 2)/6+math.Pow(p.Q, 3)/12+math.Pow(p.Q, 4)/20+math.Pow(p.Q, 5)/30)
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// The transcripts in lunar/testdata come from the claude-code port, which
+// is byte-identical to retrofocal.
+func TestTranscripts(t *testing.T) {
+	for _, name := range []string{"perfect", "good", "crash"} {
+		t.Run(name, func(t *testing.T) {
+			dir := filepath.Join("..", "..", "lunar", "testdata")
+			in, err := os.ReadFile(filepath.Join(dir, name+".in"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			want, err := os.ReadFile(filepath.Join(dir, name+".out"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var out bytes.Buffer
+			if err := execute(bytes.NewReader(in), &out); err != nil {
+				t.Fatal(err)
+			}
+			if got := out.Bytes(); !bytes.Equal(want, got) {
+				t.Errorf("want\n%s\ngot\n%s", want, got)
+			}
+		})
+	}
+}
+
+func TestTryAgain(t *testing.T) {
+	// YES restarts at 01.20 without the briefing, anything else asks again.
+	ks := strings.Repeat("0\n", 7) + "164.31426784\n" + strings.Repeat("200\n", 7)
+	in := ks + "MAYBE\nYES\n" + ks + "NO\n"
+	var out bytes.Buffer
+	if err := execute(strings.NewReader(in), &out); err != nil {
+		t.Fatal(err)
+	}
+	s := out.String()
+	if n := strings.Count(s, "CONTROL CALLING"); n != 1 {
+		t.Errorf("want briefing once, got %d", n)
+	}
+	if n := strings.Count(s, "FIRST RADAR CHECK"); n != 2 {
+		t.Errorf("want two flights, got %d", n)
+	}
+	if n := strings.Count(s, "(ANS. YES OR NO):"); n != 3 {
+		t.Errorf("want three questions, got %d", n)
+	}
+	if !strings.HasSuffix(s, "CONTROL OUT\n\n\n") {
+		t.Errorf("want CONTROL OUT at the end, got %q", s[len(s)-40:])
+	}
+}
+
+func TestEndOfInput(t *testing.T) {
+	var out bytes.Buffer
+	if err := execute(strings.NewReader("0\n0\n"), &out); !errors.Is(err, io.EOF) {
+		t.Errorf("want EOF, got %v", err)
+	}
+}
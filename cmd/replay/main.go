@@ -0,0 +1,217 @@
+// Command replay runs the shared corpus of answer sequences through every
+// port and compares what they print with the FOCAL program itself, run by
+// the interpreter of package focal.
+//
+//	replay
+//	replay -ports claude-code,lunar -minimize corpus/cases/invalid.in
+//
+// The ports are built from cmd/ into a temporary directory. For each case
+// and port replay prints ok for the same output, ~ for output within
+// -digits significant figures (see fidelity), DIFF, HANG after -timeout
+// or ERR, and a summary per port. -minimize shrinks each failing case to a
+// short reproducer. chatgpt-o3 is left out, it reads no answers.
+// replay exits with status 1 if a port fails a case.
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"gitlab.com/jhinrichsen/lunar-lander/corpus"
+	"gitlab.com/jhinrichsen/lunar-lander/fidelity"
+	"gitlab.com/jhinrichsen/lunar-lander/focal"
+	"gitlab.com/jhinrichsen/lunar-lander/variants"
+)
+
+// ports are the interactive ports under cmd/. windsurf-gpt41 is a module
+// of its own.
+var ports = []string{"reference", "lunar", "claude-code", "antigravity", "idea-junit-sonnet4", "windsurf-gpt41"}
+
+// port runs an input through a port and returns what it printed.
+type port struct {
+	name string
+	run  func(input string) (string, error)
+}
+
+// errHang tells that a port did not finish in time.
+var errHang = errors.New("hang")
+
+// build compiles the port name into dir and returns it.
+func build(root, dir, name string, timeout time.Duration) (port, error) {
+	bin := filepath.Join(dir, name)
+	cmd := exec.Command("go", "build", "-o", bin, "./cmd/"+name)
+	if _, err := os.Stat(filepath.Join(root, "cmd", name, "go.mod")); err == nil {
+		cmd = exec.Command("go", "build", "-o", bin, ".")
+		cmd.Dir = filepath.Join(root, "cmd", name)
+	} else {
+		cmd.Dir = root
+	}
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return port{}, fmt.Errorf("building %s: %v\n%s", name, err, out)
+	}
+	return port{name, func(input string) (string, error) {
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+		cmd := exec.CommandContext(ctx, bin)
+		cmd.Stdin = strings.NewReader(input)
+		var out bytes.Buffer
+		cmd.Stdout = &out
+		err := cmd.Run()
+		if ctx.Err() != nil {
+			return out.String(), errHang
+		}
+		return out.String(), err
+	}}, nil
+}
+
+// focal69 runs the FOCAL program, the truth the ports are held to.
+func focal69(prog *focal.Program) func(string) (string, error) {
+	return func(input string) (string, error) {
+		r, err := variants.Run(prog, strings.NewReader(input))
+		if err != nil {
+			return "", err
+		}
+		return r.Output, nil
+	}
+}
+
+// grade compares what p prints for input with want.
+func grade(p port, input, want string, tol fidelity.Tolerance) string {
+	got, err := p.run(input)
+	switch {
+	case errors.Is(err, errHang):
+		return "HANG"
+	case err != nil:
+		return "ERR"
+	case got == want:
+		return "ok"
+	case fidelity.Compare(want, got, tol).Faithful():
+		return "~"
+	}
+	return "DIFF"
+}
+
+// replay grades every port on every case and reports whether all passed.
+func replay(w io.Writer, truth func(string) (string, error), ps []port, cs []corpus.Case,
+	tol fidelity.Tolerance, minimize bool) (bool, error) {
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprint(tw, "case")
+	for _, p := range ps {
+		fmt.Fprintf(tw, "\t%s", p.name)
+	}
+	fmt.Fprintln(tw)
+	passed := make([]int, len(ps))
+	type failure struct {
+		p port
+		c corpus.Case
+	}
+	var failures []failure
+	for _, c := range cs {
+		c = corpus.Finish(c)
+		want, err := truth(c.Input())
+		if err != nil {
+			return false, fmt.Errorf("%s: %w", c.Name, err)
+		}
+		fmt.Fprint(tw, c.Name)
+		for i, p := range ps {
+			g := grade(p, c.Input(), want, tol)
+			if g == "ok" || g == "~" {
+				passed[i]++
+			} else {
+				failures = append(failures, failure{p, c})
+			}
+			fmt.Fprintf(tw, "\t%s", g)
+		}
+		fmt.Fprintln(tw)
+	}
+	fmt.Fprint(tw, "passed")
+	for i := range ps {
+		fmt.Fprintf(tw, "\t%d/%d", passed[i], len(cs))
+	}
+	fmt.Fprintln(tw)
+	if err := tw.Flush(); err != nil {
+		return false, err
+	}
+	if minimize {
+		for _, f := range failures {
+			m := corpus.Minimize(f.c, func(c corpus.Case) bool {
+				want, err := truth(c.Input())
+				if err != nil {
+					return false
+				}
+				g := grade(f.p, c.Input(), want, tol)
+				return g != "ok" && g != "~"
+			})
+			fmt.Fprintf(w, "%s %s: %s\n", f.p.name, f.c.Name, strings.Join(m.Answers, " "))
+		}
+	}
+	return len(failures) == 0, nil
+}
+
+// run builds the ports names of the repository at root and replays cs.
+func run(w io.Writer, root string, names []string, cs []corpus.Case, tol fidelity.Tolerance,
+	timeout time.Duration, minimize bool) (bool, error) {
+	prog, err := variants.Lookup("original").Program()
+	if err != nil {
+		return false, err
+	}
+	dir, err := os.MkdirTemp("", "replay")
+	if err != nil {
+		return false, err
+	}
+	defer os.RemoveAll(dir)
+	var ps []port
+	for _, name := range names {
+		p, err := build(root, dir, name, timeout)
+		if err != nil {
+			return false, err
+		}
+		ps = append(ps, p)
+	}
+	return replay(w, focal69(prog), ps, cs, tol, minimize)
+}
+
+func main() {
+	var tol fidelity.Tolerance
+	flag.IntVar(&tol.Digits, "digits", 3, "significant figures numbers must agree to for ~")
+	names := flag.String("ports", strings.Join(ports, ","), "comma separated ports under cmd/")
+	root := flag.String("root", ".", "root of the repository")
+	timeout := flag.Duration("timeout", 5*time.Second, "time a port may take per case")
+	minimize := flag.Bool("minimize", false, "shrink failing cases to short reproducers")
+	flag.Usage = func() {
+		fmt.Fprintln(flag.CommandLine.Output(), "usage: replay [flags] [case.in...]")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	cs := corpus.All()
+	if flag.NArg() > 0 {
+		cs = nil
+		for _, name := range flag.Args() {
+			b, err := os.ReadFile(name)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(1)
+			}
+			cs = append(cs, corpus.Parse(strings.TrimSuffix(filepath.Base(name), ".in"), string(b)))
+		}
+	}
+	ok, err := run(os.Stdout, *root, strings.Split(*names, ","), cs, tol, *timeout, *minimize)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if !ok {
+		os.Exit(1)
+	}
+}
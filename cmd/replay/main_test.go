@@ -0,0 +1,91 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"gitlab.com/jhinrichsen/lunar-lander/corpus"
+	"gitlab.com/jhinrichsen/lunar-lander/fidelity"
+	"gitlab.com/jhinrichsen/lunar-lander/lunar"
+	"gitlab.com/jhinrichsen/lunar-lander/variants"
+)
+
+// game is the lander of package lunar in process.
+func game(input string) (string, error) {
+	var out strings.Builder
+	lunar.NewGame(lunar.Classic(), strings.NewReader(input), &out).Run()
+	return out.String(), nil
+}
+
+func truth(t *testing.T) func(string) (string, error) {
+	prog, err := variants.Lookup("original").Program()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return focal69(prog)
+}
+
+func TestReplay(t *testing.T) {
+	ps := []port{
+		{"lunar", game},
+		// rounds the impact velocity to one decimal
+		{"rounding", func(input string) (string, error) {
+			s, err := game(input)
+			return strings.Replace(s, "21.35M.P.H.", "21.4M.P.H.", 1), err
+		}},
+		// refuses burn rates of 200
+		{"broken", func(input string) (string, error) {
+			return game(strings.ReplaceAll(input, "200\n", "199\n"))
+		}},
+		{"hanging", func(string) (string, error) { return "", errHang }},
+	}
+	cs := []corpus.Case{
+		corpus.Parse("coast", ""),
+		corpus.Parse("good", "0\n0\n0\n0\n0\n0\n0\n170\n200\n200\n200\n200\n200\n200\n170\n0\n0\n30\n0\n8\n10\n9\n100\nNO\n"),
+	}
+	var out strings.Builder
+	ok, err := replay(&out, truth(t), ps, cs, fidelity.Tolerance{Digits: 3}, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok {
+		t.Fatal("want failures")
+	}
+	for _, s := range []string{
+		"case    lunar  rounding  broken  hanging\n",
+		"coast   ok     ok        ok      HANG\n",
+		"good    ok     ~         DIFF    HANG\n",
+		"passed  2/2    2/2       1/2     0/2\n",
+		"broken good: 200 0 0 0 0 0 0 0 0 0 0 0 0 NO\n",
+		"hanging coast: 0 0 0 0 0 0 0 0 0 0 0 0 NO\n",
+	} {
+		if !strings.Contains(out.String(), s) {
+			t.Errorf("missing %q in\n%s", s, out.String())
+		}
+	}
+}
+
+func TestReplayPasses(t *testing.T) {
+	ok, err := replay(&strings.Builder{}, truth(t), []port{{"lunar", game}}, corpus.All(), fidelity.Tolerance{}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ok {
+		t.Fatal("want lunar to pass the corpus")
+	}
+}
+
+func TestRun(t *testing.T) {
+	if testing.Short() {
+		t.Skip("builds a port")
+	}
+	var out strings.Builder
+	ok, err := run(&out, "../..", []string{"claude-code"}, corpus.All()[:2], fidelity.Tolerance{}, 10*time.Second, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ok {
+		t.Fatalf("want claude-code to pass, got\n%s", out.String())
+	}
+}
@@ -0,0 +1,115 @@
+// Command tracediff compares the execution trace of a Go port with the
+// FOCAL program and points at the first FOCAL line where the variables
+// disagree.
+//
+//	claude-code -trace port.trace < case.in
+//	tracediff -in case.in lunar-lander.fc port.trace
+//
+// The first argument is a trace or a FOCAL program, which tracediff then
+// runs on the answers of -in. Values agree within the relative tolerance
+// -tol. tracediff exits with status 1 if the traces disagree.
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"gitlab.com/jhinrichsen/lunar-lander/focal"
+	"gitlab.com/jhinrichsen/lunar-lander/trace"
+)
+
+// context is the number of events shown before a mismatch.
+const context = 5
+
+// load reads a trace, or runs a FOCAL program on answers and traces it.
+func load(filename, answers string) ([]trace.Event, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	if !strings.HasSuffix(filename, ".fc") {
+		es, err := trace.Read(f)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%w", filename, err)
+		}
+		return es, nil
+	}
+	prog, err := focal.Parse(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s:%w", filename, err)
+	}
+	if answers == "" {
+		return nil, fmt.Errorf("%s: -in is needed to run a FOCAL program", filename)
+	}
+	in, err := os.ReadFile(answers)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	err = trace.Focal(prog, focal.NewTerminal(bytes.NewReader(in), io.Discard), &buf)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("%s: %w", filename, err)
+	}
+	return trace.Read(&buf)
+}
+
+// show prints the events of es up to and including i.
+func show(w io.Writer, title string, es []trace.Event, i int) {
+	fmt.Fprintf(w, "%s:\n", title)
+	if i < 0 {
+		i = len(es) - 1
+	}
+	for j := max(0, i-context); j <= i; j++ {
+		mark := " "
+		if j == i {
+			mark = ">"
+		}
+		fmt.Fprintf(w, "%s %s\n", mark, es[j])
+	}
+}
+
+// diff compares want and got and reports whether they agree.
+func diff(w io.Writer, want, got []trace.Event, tol float64) bool {
+	m := trace.Diff(want, got, tol)
+	if m == nil {
+		fmt.Fprintf(w, "traces agree (%d and %d events)\n", len(want), len(got))
+		return true
+	}
+	fmt.Fprintln(w, m.Format(want, got))
+	show(w, "FOCAL", want, m.Want)
+	show(w, "port", got, m.Got)
+	return false
+}
+
+func main() {
+	answers := flag.String("in", "", "answers to run a FOCAL program on")
+	tol := flag.Float64("tol", 1e-9, "relative tolerance of values")
+	flag.Usage = func() {
+		fmt.Fprintln(flag.CommandLine.Output(), "usage: tracediff [-in answers] [-tol t] focal.trace|program.fc port.trace")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 2 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	want, err := load(flag.Arg(0), *answers)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	got, err := load(flag.Arg(1), *answers)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if !diff(os.Stdout, want, got, *tol) {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func write(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	p := filepath.Join(dir, name)
+	if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func TestDiff(t *testing.T) {
+	dir := t.TempDir()
+	answers := write(t, dir, "case.in", "0\n")
+	want, err := load("../../lunar-lander.fc", answers)
+	if err != nil {
+		t.Fatal(err)
+	}
+	port := write(t, dir, "port.trace", "01.50\tintro\tA=120 G=0.001 M=32500 N=16500 V=1 Z=1.8\n"+
+		"02.20\taskK\tT=10\n03.10\tloop\tS=10\n09.10\tsub9\tJ=1.02\n")
+	got, err := load(port, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out strings.Builder
+	if diff(&out, want, got, 1e-9) {
+		t.Fatal("want the traces to disagree")
+	}
+	for _, s := range []string{
+		"09.10 S J=V+G*S+Z*(-Q-Q^2/2-Q^3/3-Q^4/4-Q^5/5): J = 1.01, the port has 1.02 at 09.10 (1st value)\n",
+		"FOCAL:\n",
+		"> 09.10\tS J=V+G*S",
+		"port:\n",
+		"> 09.10\tsub9\tJ=1.02\n",
+	} {
+		if !strings.Contains(out.String(), s) {
+			t.Errorf("missing %q in\n%s", s, out.String())
+		}
+	}
+	out.Reset()
+	if !diff(&out, want, want, 0) || !strings.HasPrefix(out.String(), "traces agree") {
+		t.Errorf("want a trace to agree with itself, got %s", out.String())
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	dir := t.TempDir()
+	for _, tt := range []struct{ file, answers string }{
+		{"../../lunar-lander.fc", ""},
+		{write(t, dir, "bad.trace", "01.50\n"), ""},
+		{write(t, dir, "bad.fc", "01.10 S\n"), "x"},
+		{filepath.Join(dir, "missing.trace"), ""},
+	} {
+		if _, err := load(tt.file, tt.answers); err == nil {
+			t.Errorf("%s: want an error", tt.file)
+		}
+	}
+}
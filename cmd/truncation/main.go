@@ -0,0 +1,168 @@
+// Command truncation measures the error of subroutine 9. The FOCAL program
+// cuts ln(1-Q) after five terms of its series; truncation flies a K
+// schedule once with that series and once with a reference integrator,
+// the closed-form rocket equation or adaptive Runge-Kutta, and prints
+// time, altitude and velocity of both at the end of every decision
+// interval, the last one at touchdown, their difference and the largest
+// fuel fraction Q of the interval, then the impact velocities and verdicts.
+//
+//	truncation -k 0,0,0,0,0,0,0,170,200,200,200,200,200,200,170,0,0,30,0,8,10,9,100
+//
+// The default schedule is the good landing of the sample output.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"gitlab.com/jhinrichsen/lunar-lander/lunar"
+)
+
+// good is the safe landing of the historical sample output.
+const good = "0,0,0,0,0,0,0,170,200,200,200,200,200,200,170,0,0,30,0,8,10,9,100"
+
+// references are the integrators to compare the series with.
+var references = map[string]lunar.Integrator{
+	"exact": lunar.Exact{},
+	"rk":    lunar.RK{},
+}
+
+// interval is the state at the end of a decision interval.
+type interval struct {
+	L, A, V float64
+	// Q is the largest fuel fraction of the steps of the interval.
+	Q float64
+}
+
+// fly flies sc with in and returns the state after every interval.
+func fly(sc lunar.Scenario, in lunar.Integrator, burn func(int) float64) []interval {
+	s := sc.Flight()
+	s.Integrator = in
+	q := 0.0
+	s.Observe = func(s *lunar.State) { q = max(q, s.Q) }
+	var is []interval
+	for n := 0; ; n++ {
+		k := burn(n)
+		if !sc.Valid(k) {
+			continue
+		}
+		ev := s.Fly(k, 0)
+		if ev == lunar.FuelOut {
+			s.FreeFall()
+			s.A = s.Ground
+		}
+		is = append(is, interval{s.L, s.A, s.V, q})
+		q = 0
+		if ev != lunar.Flying {
+			return is
+		}
+	}
+}
+
+// run writes the report of a flight of sc on burn with the series and
+// with ref, called name.
+func run(w io.Writer, sc lunar.Scenario, burn func(int) float64, name string, ref lunar.Integrator) error {
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintf(tw, "n\tL taylor\tL %s\tA taylor\tA %s\tdA\tV taylor\tV %s\tdV\tQ\t\n", name, name, name)
+	fmt.Fprintln(tw, "\tsecs\tsecs\tfeet\tfeet\tfeet\tMPH\tMPH\tMPH\t\t")
+	ts, rs := fly(sc, nil, burn), fly(sc, ref, burn)
+	cell := func(is []interval, n int, f func(interval) float64) string {
+		if n >= len(is) {
+			return "-"
+		}
+		return strconv.FormatFloat(f(is[n]), 'f', 3, 64)
+	}
+	secs := func(is []interval, n int) string {
+		if n >= len(is) {
+			return "-"
+		}
+		return strconv.FormatFloat(is[n].L, 'f', 2, 64)
+	}
+	feet := func(i interval) float64 { return i.A * 5280 }
+	mph := func(i interval) float64 { return i.V * 3600 }
+	for n := range max(len(ts), len(rs)) {
+		d := func(f func(interval) float64) string {
+			if n >= len(ts) || n >= len(rs) {
+				return "-"
+			}
+			return strconv.FormatFloat(f(ts[n])-f(rs[n]), 'g', 3, 64)
+		}
+		q := "-"
+		if n < len(ts) {
+			q = strconv.FormatFloat(ts[n].Q, 'f', 4, 64)
+		}
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t\n", n+1, secs(ts, n), secs(rs, n),
+			cell(ts, n, feet), cell(rs, n, feet), d(feet),
+			cell(ts, n, mph), cell(rs, n, mph), d(mph), q)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	t, r := lunar.Simulate(sc, burn), lunar.SimulateWith(sc, ref, burn)
+	tw = tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw)
+	fmt.Fprintf(tw, "taylor\t%.2f MPH\t%.2f secs\t%s\n", t.Impact, t.Time, t.Verdict)
+	fmt.Fprintf(tw, "%s\t%.2f MPH\t%.2f secs\t%s\n", name, r.Impact, r.Time, r.Verdict)
+	fmt.Fprintf(tw, "error\t%.3g MPH\t%.3g secs\t%.3g%% of the impact velocity\n",
+		t.Impact-r.Impact, t.Time-r.Time, 100*math.Abs(t.Impact-r.Impact)/r.Impact)
+	return tw.Flush()
+}
+
+// parseK parses a comma separated list of burn rates.
+func parseK(s string) ([]float64, error) {
+	var ks []float64
+	for _, f := range strings.FieldsFunc(s, func(r rune) bool { return r == ',' || r == ' ' }) {
+		k, err := strconv.ParseFloat(f, 64)
+		if err != nil {
+			return nil, fmt.Errorf("K schedule: %w", err)
+		}
+		ks = append(ks, k)
+	}
+	return ks, nil
+}
+
+func main() {
+	ks := flag.String("k", good, "comma separated K schedule, one value per interval")
+	trailing := flag.Float64("trailing", 0, "K after the schedule is exhausted")
+	scenario := flag.String("scenario", "", "preset or JSON scenario file (default classic)")
+	interval := flag.Float64("interval", 0, "decision interval in secs (default the scenario's)")
+	ref := flag.String("ref", "exact", "reference integrator: exact or rk")
+	flag.Parse()
+
+	sc, err := lunar.Open(*scenario)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if *interval != 0 {
+		sc.Interval = *interval
+		if err := sc.Validate(); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+	}
+	schedule, err := parseK(*ks)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	in, ok := references[*ref]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "unknown integrator %q, want exact or rk\n", *ref)
+		os.Exit(2)
+	}
+	if !sc.Valid(*trailing) {
+		fmt.Fprintf(os.Stderr, "trailing K %g not possible\n", *trailing)
+		os.Exit(2)
+	}
+	if err := run(os.Stdout, sc, lunar.Schedule(schedule, *trailing), *ref, in); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
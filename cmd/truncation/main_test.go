@@ -0,0 +1,72 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"gitlab.com/jhinrichsen/lunar-lander/lunar"
+)
+
+func TestRun(t *testing.T) {
+	ks, err := parseK(good)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out strings.Builder
+	if err := run(&out, lunar.Classic(), lunar.Schedule(ks, 0), "exact", lunar.Exact{}); err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{
+		"   7     70.00    70.00  251064.000  251064.000          0  3852.000  3852.000         0  0.0000\n",
+		"  23    226.11   226.13       0.000       0.000          0    21.354    21.395   -0.0404  0.0018\n",
+		"taylor  21.35 MPH    226.11 secs  CONGRATULATIONS ON A POOR LANDING\n",
+		"exact   21.39 MPH    226.13 secs  CONGRATULATIONS ON A POOR LANDING\n",
+	} {
+		if !strings.Contains(out.String(), s) {
+			t.Errorf("missing %q in\n%s", s, out.String())
+		}
+	}
+}
+
+// TestRunMalfunctions checks that table and summary fly the same failures.
+func TestRunMalfunctions(t *testing.T) {
+	sc, err := lunar.Open("../../scenarios/malfunction.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ks, err := parseK(good)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out strings.Builder
+	if err := run(&out, sc, lunar.Schedule(ks, 0), "exact", lunar.Exact{}); err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{
+		"  14    132.05   132.05       0.000       0.000",
+		"taylor  1361.68 MPH  132.05 secs",
+	} {
+		if !strings.Contains(out.String(), s) {
+			t.Errorf("missing %q in\n%s", s, out.String())
+		}
+	}
+}
+
+func TestFlyLengths(t *testing.T) {
+	ks := []float64{0, 0, 0, 0, 0, 0, 200, 200, 200, 200, 200, 0, 0, 100, 200, 200, 0, 0, 71, 37}
+	sc := lunar.Classic()
+	// the perfect landing runs dry without the truncation error
+	ts, rs := fly(sc, nil, lunar.Schedule(ks, 0)), fly(sc, lunar.RK{}, lunar.Schedule(ks, 0))
+	if len(ts) != 20 || len(rs) != 20 {
+		t.Fatalf("want 20 intervals, got %d and %d", len(ts), len(rs))
+	}
+	if l := rs[len(rs)-1]; l.A != 0 || l.L < 240 || l.V*3600 < 90 {
+		t.Errorf("want a crash on the surface, got %+v", l)
+	}
+}
+
+func TestParseK(t *testing.T) {
+	if _, err := parseK("0,x"); err == nil {
+		t.Error("want an error")
+	}
+}
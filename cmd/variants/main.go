@@ -0,0 +1,155 @@
+// Command variants lists the known listings of the lunar lander, shows how
+// two of them differ and flies them all on the same answers.
+//
+//	variants list
+//	variants diff original prompt
+//	variants run lunar/testdata/*.in
+//
+// diff marks every differing line as spelling, output or code, see package
+// variants. run prints one row per landing and variant and flags the rows
+// that land differently from the first variant.
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"gitlab.com/jhinrichsen/lunar-lander/variants"
+)
+
+func list(w io.Writer) error {
+	b := bufio.NewWriter(w)
+	for _, v := range variants.All {
+		fmt.Fprintf(b, "%-9s %s\n%9s %s\n", v.Name, v.Source, "", v.Notes)
+	}
+	return b.Flush()
+}
+
+func lookup(name string) (*variants.Variant, error) {
+	v := variants.Lookup(name)
+	if v == nil {
+		return nil, fmt.Errorf("no variant %q", name)
+	}
+	return v, nil
+}
+
+func diff(w io.Writer, a, b string) error {
+	va, err := lookup(a)
+	if err != nil {
+		return err
+	}
+	vb, err := lookup(b)
+	if err != nil {
+		return err
+	}
+	pa, err := va.Program()
+	if err != nil {
+		return err
+	}
+	pb, err := vb.Program()
+	if err != nil {
+		return err
+	}
+	out := bufio.NewWriter(w)
+	fmt.Fprintf(out, "--- %s\n+++ %s\n", a, b)
+	for _, d := range variants.Diff(pa, pb) {
+		fmt.Fprintf(out, "%s %s\n", d.Line, d.Kind)
+		if d.A != nil {
+			fmt.Fprintf(out, "- %s\n", d.A)
+		}
+		if d.B != nil {
+			fmt.Fprintf(out, "+ %s\n", d.B)
+		}
+	}
+	return out.Flush()
+}
+
+// flights runs v on the answers in the file input.
+func flights(v *variants.Variant, input string) ([]variants.Landing, error) {
+	p, err := v.Program()
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(input)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	r, err := variants.Run(p, f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s: %w", v.Name, input, err)
+	}
+	return r.Landings, nil
+}
+
+// same reports whether two landings print alike at the precision of the
+// verdict lines.
+func same(a, b variants.Landing) bool {
+	return a.Verdict == b.Verdict &&
+		fmt.Sprintf("%.2f %.2f %.2f", a.Time, a.Velocity, a.Fuel) ==
+			fmt.Sprintf("%.2f %.2f %.2f", b.Time, b.Velocity, b.Fuel)
+}
+
+func run(w io.Writer, inputs []string) error {
+	out := bufio.NewWriter(w)
+	for _, input := range inputs {
+		fmt.Fprintln(out, input)
+		var first []variants.Landing
+		for i, v := range variants.All {
+			ls, err := flights(v, input)
+			if err != nil {
+				return err
+			}
+			if i == 0 {
+				first = ls
+			}
+			for j, l := range ls {
+				mark := ""
+				if j >= len(first) || !same(l, first[j]) {
+					mark = "  *"
+				}
+				fmt.Fprintf(out, "  %-9s %d %12.6g SECS %12.6g MPH %12.6g LBS  %s%s\n",
+					v.Name, j+1, l.Time, l.Velocity, l.Fuel, l.Verdict, mark)
+			}
+			if len(ls) < len(first) {
+				fmt.Fprintf(out, "  %-9s %d landings instead of %d  *\n", v.Name, len(ls), len(first))
+			}
+		}
+	}
+	return out.Flush()
+}
+
+var errUsage = errors.New("usage: variants list | diff a b | run input...")
+
+func cmd(w io.Writer, args []string) error {
+	if len(args) == 0 {
+		return errUsage
+	}
+	switch {
+	case args[0] == "list" && len(args) == 1:
+		return list(w)
+	case args[0] == "diff" && len(args) == 3:
+		return diff(w, args[1], args[2])
+	case args[0] == "run" && len(args) > 1:
+		return run(w, args[1:])
+	}
+	return errUsage
+}
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintln(flag.CommandLine.Output(), errUsage)
+	}
+	flag.Parse()
+	if err := cmd(os.Stdout, flag.Args()); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		if err == errUsage {
+			os.Exit(2)
+		}
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestList(t *testing.T) {
+	var out strings.Builder
+	if err := cmd(&out, []string{"list"}); err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{
+		"original  lunar-lander.fc\n",
+		"prompt    PROMPT.md",
+		"scan      doc/LunarLanderListing.jpg\n",
+	} {
+		if !strings.Contains(out.String(), s) {
+			t.Errorf("missing %q in\n%s", s, out.String())
+		}
+	}
+}
+
+func TestDiff(t *testing.T) {
+	var out strings.Builder
+	if err := cmd(&out, []string{"diff", "original", "scan"}); err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{
+		"--- original\n+++ scan\n",
+		"03.40 spelling\n- 03.40 I ((N+S*K)-M)3.5,3.5;S S=(M-N)/K\n+ 03.40 I (N+S*K-M)3.5,3.5;S S=(M-N)/K\n",
+		"08.10 code\n",
+	} {
+		if !strings.Contains(out.String(), s) {
+			t.Errorf("missing %q in\n%s", s, out.String())
+		}
+	}
+	if strings.Contains(out.String(), "01.11") {
+		t.Errorf("scan has the 01.11 of the original, got\n%s", out.String())
+	}
+}
+
+func TestRun(t *testing.T) {
+	var out strings.Builder
+	err := cmd(&out, []string{"run",
+		"../../lunar/testdata/perfect.in",
+		"../antigravity/testdata/inputs_good.txt",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `../../lunar/testdata/perfect.in
+  original  1      190.335 SECS     0.663005 MPH        277.6 LBS  05.40
+  prompt    1  3.48749e+15 SECS  6.27748e+15 MPH -5.77762e+06 LBS  05.82  *
+  scan      1  3.48749e+15 SECS  6.27748e+15 MPH -5.77762e+06 LBS  05.82  *
+../antigravity/testdata/inputs_good.txt
+  original  1       190.18 SECS      4.89222 MPH          290 LBS  05.50
+  prompt    1       190.18 SECS      4.89222 MPH          290 LBS  05.50
+  scan      1       190.18 SECS      4.89222 MPH          290 LBS  05.50
+`
+	if out.String() != want {
+		t.Errorf("want\n%s\ngot\n%s", want, out.String())
+	}
+}
+
+func TestErrors(t *testing.T) {
+	for _, args := range [][]string{
+		nil,
+		{"list", "x"},
+		{"diff", "original"},
+		{"run"},
+		{"fly"},
+	} {
+		if err := cmd(new(strings.Builder), args); err != errUsage {
+			t.Errorf("%q: want the usage, got %v", args, err)
+		}
+	}
+	for _, args := range [][]string{
+		{"diff", "original", "basic"},
+		{"diff", "basic", "original"},
+		{"run", "missing.in"},
+	} {
+		if err := cmd(new(strings.Builder), args); err == nil || err == errUsage {
+			t.Errorf("%q: want an error, got %v", args, err)
+		}
+	}
+}
@@ -0,0 +1,199 @@
+// Package corpus is the shared set of answer sequences the ports are
+// fuzzed and compared on. A Case is what the person at the teletype
+// types, one answer per line: burn rates at the K prompt, YES or NO after
+// the landing, and whatever else a person might type.
+//
+// The cases in cases/ collect the seeds the ports kept on their own:
+// lunar/testdata, the inputs and fuzz corpus of antigravity, the table of
+// claude-code and testdata/ of the root. Decode turns fuzzer bytes into a
+// Case of structured answers, so fuzzing explores legal and invalid burn
+// rates, expressions, blank lines and answers to TRY AGAIN rather than
+// random bytes. Minimize shrinks a failing Case to a short reproducer.
+package corpus
+
+import (
+	"embed"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gitlab.com/jhinrichsen/lunar-lander/lunar"
+)
+
+//go:embed cases/*.in
+var cases embed.FS
+
+// Case is a sequence of answers.
+type Case struct {
+	Name    string
+	Answers []string
+}
+
+// Parse reads a case from the text of an input file, one answer per line.
+func Parse(name, text string) Case {
+	text = strings.TrimSuffix(text, "\n")
+	c := Case{Name: name}
+	if text != "" {
+		c.Answers = strings.Split(text, "\n")
+	}
+	return c
+}
+
+// Input returns the answers as a port reads them from standard input.
+func (c Case) Input() string {
+	if len(c.Answers) == 0 {
+		return ""
+	}
+	return strings.Join(c.Answers, "\n") + "\n"
+}
+
+// All returns the cases of the shared corpus sorted by name.
+func All() []Case {
+	cs, err := load(cases, "cases")
+	if err != nil {
+		panic(err)
+	}
+	return cs
+}
+
+// Load reads the *.in files of dir as cases.
+func Load(dir string) ([]Case, error) {
+	return load(os.DirFS(dir), ".")
+}
+
+func load(fsys fs.FS, dir string) ([]Case, error) {
+	names, err := fs.Glob(fsys, filepath.ToSlash(filepath.Join(dir, "*.in")))
+	if err != nil {
+		return nil, err
+	}
+	var cs []Case
+	for _, n := range names {
+		b, err := fs.ReadFile(fsys, n)
+		if err != nil {
+			return nil, err
+		}
+		cs = append(cs, Parse(strings.TrimSuffix(filepath.Base(n), ".in"), string(b)))
+	}
+	sort.Slice(cs, func(i, j int) bool { return cs[i].Name < cs[j].Name })
+	return cs, nil
+}
+
+// Answer kinds of Decode.
+const (
+	coast = iota
+	legal
+	fraction
+	minK
+	maxK
+	tooLow
+	tooHigh
+	negative
+	blank
+	yes
+	no
+	expression
+	kinds
+)
+
+// expressions are answers ASK evaluates, in the variables of the program.
+var expressions = []string{"2*100", "K", "FSQT(K)*10", "(K+8)/2"}
+
+// MaxAnswers bounds the length of a decoded case.
+const MaxAnswers = 100
+
+// Decode turns fuzzer bytes into a case. Each pair of bytes is an answer,
+// the first byte picks the kind, the second the value:
+//
+//	0  coast, 0
+//	1  a legal K, 8 to 200
+//	2  a legal K with two decimals
+//	3  8, the smallest K
+//	4  200, the largest K
+//	5  1 to 7, too small
+//	6  201 to 456, too large
+//	7  -1 to -256
+//	8  a blank line
+//	9  YES
+//	10 NO
+//	11 an expression such as 2*100 or K
+//
+// Decode finishes the case so every flight lands and the session ends.
+func Decode(data []byte) Case {
+	var c Case
+	for i := 0; i+1 < len(data) && len(c.Answers) < MaxAnswers; i += 2 {
+		v := int(data[i+1])
+		var a string
+		switch data[i] % kinds {
+		case coast:
+			a = "0"
+		case legal:
+			a = fmt.Sprint(8 + v*192/255)
+		case fraction:
+			a = fmt.Sprintf("%.2f", 8+float64(v)*192/255)
+		case minK:
+			a = "8"
+		case maxK:
+			a = "200"
+		case tooLow:
+			a = fmt.Sprint(1 + v%7)
+		case tooHigh:
+			a = fmt.Sprint(201 + v)
+		case negative:
+			a = fmt.Sprint(-1 - v)
+		case blank:
+			a = ""
+		case yes:
+			a = "YES"
+		case no:
+			a = "NO"
+		case expression:
+			a = expressions[v%len(expressions)]
+		}
+		c.Answers = append(c.Answers, a)
+	}
+	return Finish(c)
+}
+
+// Finish appends what a session of c needs to end: coasting until the
+// capsule is down and NO at TRY AGAIN. The lander of package lunar, which
+// reads like the FOCAL program, decides which prompt an answer meets.
+func Finish(c Case) Case {
+	c.Answers = append([]string(nil), c.Answers...)
+	for range 10 * MaxAnswers {
+		var out strings.Builder
+		in := &probe{r: strings.NewReader(c.Input()), out: &out}
+		lunar.NewGame(lunar.Classic(), in, &out).Run()
+		switch {
+		case !in.eof:
+			return c
+		case strings.HasSuffix(in.prompt, "K=:"):
+			c.Answers = append(c.Answers, "0")
+		case strings.HasSuffix(in.prompt, "(ANS. YES OR NO):"):
+			c.Answers = append(c.Answers, "NO")
+		default:
+			return c
+		}
+	}
+	return c
+}
+
+// probe notes the output when the input runs out, which ends with the
+// prompt that waited for more.
+type probe struct {
+	r      io.Reader
+	out    *strings.Builder
+	eof    bool
+	prompt string
+}
+
+func (p *probe) Read(b []byte) (int, error) {
+	n, err := p.r.Read(b)
+	if err == io.EOF && !p.eof {
+		p.eof, p.prompt = true, p.out.String()
+	}
+	return n, err
+}
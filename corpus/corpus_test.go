@@ -0,0 +1,137 @@
+package corpus
+
+import (
+	"math/rand/v2"
+	"reflect"
+	"strings"
+	"testing"
+
+	"gitlab.com/jhinrichsen/lunar-lander/lunar"
+	"gitlab.com/jhinrichsen/lunar-lander/variants"
+)
+
+// session returns what the lander prints for c.
+func session(c Case) string {
+	var out strings.Builder
+	lunar.NewGame(lunar.Classic(), strings.NewReader(c.Input()), &out).Run()
+	return out.String()
+}
+
+func TestAll(t *testing.T) {
+	cs := All()
+	if len(cs) < 16 {
+		t.Fatalf("want at least 16 cases, got %d", len(cs))
+	}
+	for i, c := range cs {
+		if i > 0 && cs[i-1].Name >= c.Name {
+			t.Errorf("%s after %s", c.Name, cs[i-1].Name)
+		}
+		if !strings.HasSuffix(session(Finish(c)), "CONTROL OUT\n\n\n") {
+			t.Errorf("%s: session does not end", c.Name)
+		}
+	}
+	if cs[0].Name != "again" || cs[0].Answers[13] != "MAYBE" {
+		t.Fatalf("want again first, got %+v", cs[0])
+	}
+}
+
+func TestLoad(t *testing.T) {
+	cs, err := Load("cases")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(cs, All()) {
+		t.Fatal("want the embedded cases")
+	}
+}
+
+func TestParse(t *testing.T) {
+	c := Parse("x", "0\n\n200\n")
+	if !reflect.DeepEqual(c.Answers, []string{"0", "", "200"}) {
+		t.Fatalf("got %q", c.Answers)
+	}
+	if c.Input() != "0\n\n200\n" {
+		t.Fatalf("got %q", c.Input())
+	}
+	if c := Parse("empty", ""); len(c.Answers) != 0 || c.Input() != "" {
+		t.Fatalf("want no answers, got %q", c.Answers)
+	}
+}
+
+func TestDecode(t *testing.T) {
+	c := Decode([]byte{0, 9, 1, 255, 2, 128, 3, 0, 4, 0, 5, 9, 6, 0, 7, 9, 8, 0, 21, 0, 22, 0, 11, 1})
+	want := []string{"0", "200", "104.38", "8", "200", "3", "201", "-10", "", "YES", "NO", "K"}
+	if !reflect.DeepEqual(c.Answers[:len(want)], want) {
+		t.Fatalf("want %q, got %q", want, c.Answers[:len(want)])
+	}
+	if !strings.HasSuffix(session(c), "CONTROL OUT\n\n\n") {
+		t.Fatal("want a finished session")
+	}
+	if c := Decode(nil); !reflect.DeepEqual(c.Answers[len(c.Answers)-2:], []string{"0", "NO"}) {
+		t.Fatalf("want coasting to the end, got %q", c.Answers)
+	}
+}
+
+func TestDecodeFinishes(t *testing.T) {
+	rng := rand.New(rand.NewPCG(1, 2))
+	for range 200 {
+		data := make([]byte, rng.IntN(2*MaxAnswers+20))
+		for i := range data {
+			data[i] = byte(rng.UintN(256))
+		}
+		c := Decode(data)
+		if !strings.HasSuffix(session(c), "CONTROL OUT\n\n\n") {
+			t.Fatalf("%x: session does not end", data)
+		}
+	}
+}
+
+func TestMinimize(t *testing.T) {
+	c := All()[0]
+	// the MAYBE at TRY AGAIN is asked again
+	fails := func(c Case) bool {
+		return strings.Contains(session(c), "(ANS. YES OR NO):(ANS. YES OR NO):")
+	}
+	if !fails(c) {
+		t.Fatal("want the case to fail")
+	}
+	m := Minimize(c, fails)
+	if !fails(m) {
+		t.Fatal("want the minimized case to fail")
+	}
+	if len(m.Answers) >= len(c.Answers) {
+		t.Fatalf("want fewer than %d answers, got %q", len(c.Answers), m.Answers)
+	}
+	t.Logf("%q", m.Answers)
+}
+
+// FuzzLunar compares the lander of package lunar with the FOCAL program.
+func FuzzLunar(f *testing.F) {
+	for _, c := range All() {
+		var data []byte
+		for _, a := range c.Answers {
+			// coast and NO keep the seeds close to the stored case
+			switch a {
+			case "NO":
+				data = append(data, no, 0)
+			default:
+				data = append(data, coast, 0)
+			}
+		}
+		f.Add(data)
+	}
+	prog, err := variants.Lookup("original").Program()
+	if err != nil {
+		f.Fatal(err)
+	}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		c := Decode(data)
+		r, err := variants.Run(prog, strings.NewReader(c.Input()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := session(c); got != r.Output {
+			t.Fatalf("%q: lunar and FOCAL differ", c.Answers)
+		}
+	})
+}
@@ -0,0 +1,40 @@
+package corpus
+
+// simpler are the answers Minimize tries in place of another, simplest
+// first.
+var simpler = []string{"0", "200", "8"}
+
+// Minimize shrinks c while fails keeps reporting the failure: it drops
+// runs of answers, halving their length down to single answers, then
+// replaces each remaining answer by a simpler burn rate. fails gets every
+// candidate finished, see Finish, and so does the result.
+func Minimize(c Case, fails func(Case) bool) Case {
+	try := func(answers []string) bool {
+		return fails(Finish(Case{Name: c.Name, Answers: answers}))
+	}
+	as := append([]string(nil), c.Answers...)
+	for n := len(as) / 2; n >= 1; n /= 2 {
+		for i := 0; i+n <= len(as); {
+			cand := append(append([]string(nil), as[:i]...), as[i+n:]...)
+			if try(cand) {
+				as = cand
+				continue
+			}
+			i += n
+		}
+	}
+	for i := range as {
+		for _, s := range simpler {
+			if as[i] == s {
+				break
+			}
+			cand := append([]string(nil), as...)
+			cand[i] = s
+			if try(cand) {
+				as = cand
+				break
+			}
+		}
+	}
+	return Finish(Case{Name: c.Name, Answers: as})
+}
@@ -0,0 +1,340 @@
+// Package expect drives an interactive program the way the person at the
+// teletype did: wait for a prompt, type an answer, wait for the next one.
+//
+// A Session runs a command (Command), a port in the same process (Func)
+// or any pair of pipes (New). Expect waits for text in the output, Send
+// types. Every step has its own timeout, so a port that hangs fails the
+// test with the output it got stuck on instead of blocking it. Run plays
+// a set of rules, a prompt and the answers to give to it, until the
+// program ends. The transcript keeps output and answers in the order they
+// happened, like paper with local echo; Telemetry picks the status rows
+// and the landing out of it.
+//
+//	s, err := expect.Command(exec.Command("retrofocal", "lunar-lander.fc"))
+//	err = s.Run(expect.Rule{Prompt: "K=:", Answers: ks},
+//		expect.Rule{Prompt: "(ANS. YES OR NO)", Answers: []string{"NO"}})
+//	tm := expect.Telemetry(s.Transcript())
+package expect
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"regexp"
+	"sync"
+	"time"
+)
+
+// DefaultTimeout is the time a step may take unless Session.Timeout says
+// otherwise.
+const DefaultTimeout = 5 * time.Second
+
+var (
+	// ErrTimeout tells that the awaited output did not come in time.
+	ErrTimeout = errors.New("expect: timeout")
+	// ErrEOF tells that the program ended before the awaited output.
+	ErrEOF = errors.New("expect: program ended")
+	// ErrNoAnswer tells that a rule ran out of answers.
+	ErrNoAnswer = errors.New("expect: no answer left")
+)
+
+// Session is a conversation with a running program.
+type Session struct {
+	// Timeout bounds every Expect and Close, DefaultTimeout if 0.
+	Timeout time.Duration
+
+	in   io.WriteCloser
+	wait func() error
+	kill func() // stops the program without waiting for it
+
+	mu         sync.Mutex
+	unread     []byte // output not consumed by Expect yet
+	output     bytes.Buffer
+	transcript bytes.Buffer
+	eof        bool
+	readErr    error
+	more       chan struct{} // signalled when output arrives or ends
+	done       chan struct{} // closed when the output ends
+}
+
+// New starts a session on a program that reads in and writes to out.
+// Closing the session closes in.
+func New(out io.Reader, in io.WriteCloser) *Session {
+	s := &Session{
+		in:   in,
+		wait: func() error { return nil },
+		kill: func() {},
+		more: make(chan struct{}, 1),
+		done: make(chan struct{}),
+	}
+	go s.read(out)
+	return s
+}
+
+// Command starts cmd with its standard input and output connected to a
+// new session. Standard error goes to the output too unless cmd sets it.
+func Command(cmd *exec.Cmd) (*Session, error) {
+	in, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	pr, pw := io.Pipe()
+	cmd.Stdout = pw
+	if cmd.Stderr == nil {
+		cmd.Stderr = pw
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+	waited := make(chan error, 1)
+	go func() {
+		err := cmd.Wait()
+		pw.Close()
+		waited <- err
+	}()
+	s := New(pr, in)
+	s.wait = sync.OnceValue(func() error { return <-waited })
+	s.kill = func() { cmd.Process.Kill() }
+	return s, nil
+}
+
+// Func runs f in its own goroutine as the program of a new session, for
+// ports that take an io.Reader and an io.Writer. The output ends when f
+// returns; Close returns its error.
+func Func(f func(in io.Reader, out io.Writer) error) *Session {
+	inR, inW := io.Pipe()
+	outR, outW := io.Pipe()
+	returned := make(chan error, 1)
+	go func() {
+		err := f(inR, outW)
+		// unblock a program still typing after a failed test
+		inR.Close()
+		outW.Close()
+		returned <- err
+	}()
+	s := New(outR, inW)
+	s.wait = sync.OnceValue(func() error { return <-returned })
+	// a goroutine cannot be killed, but it fails at its next read or write
+	s.kill = func() {
+		inR.Close()
+		outW.Close()
+	}
+	return s
+}
+
+func (s *Session) read(r io.Reader) {
+	buf := make([]byte, 4096)
+	for {
+		n, err := r.Read(buf)
+		s.mu.Lock()
+		s.unread = append(s.unread, buf[:n]...)
+		s.output.Write(buf[:n])
+		s.transcript.Write(buf[:n])
+		if err != nil {
+			s.eof = true
+			if err != io.EOF {
+				s.readErr = err
+			}
+		}
+		s.mu.Unlock()
+		select {
+		case s.more <- struct{}{}:
+		default:
+		}
+		if err != nil {
+			close(s.done)
+			return
+		}
+	}
+}
+
+func (s *Session) timeout() time.Duration {
+	if s.Timeout > 0 {
+		return s.Timeout
+	}
+	return DefaultTimeout
+}
+
+// await waits until match finds something in the unread output and
+// consumes the output up to the end of the match.
+func (s *Session) await(what string, match func([]byte) (end int, ok bool)) error {
+	timer := time.NewTimer(s.timeout())
+	defer timer.Stop()
+	for {
+		s.mu.Lock()
+		if end, ok := match(s.unread); ok {
+			s.unread = s.unread[end:]
+			s.mu.Unlock()
+			return nil
+		}
+		eof, readErr, tail := s.eof, s.readErr, tail(s.unread)
+		s.mu.Unlock()
+		if eof {
+			if readErr != nil {
+				return fmt.Errorf("expect: reading output while waiting for %s: %w", what, readErr)
+			}
+			return fmt.Errorf("%w while waiting for %s, last output %q", ErrEOF, what, tail)
+		}
+		select {
+		case <-s.more:
+		case <-timer.C:
+			return fmt.Errorf("%w after %v waiting for %s, last output %q", ErrTimeout, s.timeout(), what, tail)
+		}
+	}
+}
+
+// tail returns the end of b for error messages.
+func tail(b []byte) string {
+	const n = 60
+	if len(b) > n {
+		b = b[len(b)-n:]
+	}
+	return string(b)
+}
+
+// Expect waits for one of patterns in the output and returns the index of
+// the one that comes first. Output up to the end of the match is consumed.
+func (s *Session) Expect(patterns ...string) (int, error) {
+	which := -1
+	err := s.await(fmt.Sprintf("%q", patterns), func(b []byte) (int, bool) {
+		first, end := len(b)+1, 0
+		for i, p := range patterns {
+			if j := bytes.Index(b, []byte(p)); j >= 0 && j < first {
+				first, end, which = j, j+len(p), i
+			}
+		}
+		return end, which >= 0
+	})
+	return which, err
+}
+
+// ExpectRE waits for re to match the output and returns the match and its
+// submatches.
+func (s *Session) ExpectRE(re *regexp.Regexp) ([]string, error) {
+	var m []string
+	err := s.await(re.String(), func(b []byte) (int, bool) {
+		loc := re.FindSubmatchIndex(b)
+		if loc == nil {
+			return 0, false
+		}
+		for i := 0; i < len(loc); i += 2 {
+			if loc[i] < 0 {
+				m = append(m, "")
+				continue
+			}
+			m = append(m, string(b[loc[i]:loc[i+1]]))
+		}
+		return loc[1], true
+	})
+	return m, err
+}
+
+// Send types text. A program that does not read it in time fails the step
+// like a prompt that does not come.
+func (s *Session) Send(text string) error {
+	s.mu.Lock()
+	s.transcript.WriteString(text)
+	s.mu.Unlock()
+	sent := make(chan error, 1)
+	go func() {
+		_, err := io.WriteString(s.in, text)
+		sent <- err
+	}()
+	select {
+	case err := <-sent:
+		return err
+	case <-time.After(s.timeout()):
+		return fmt.Errorf("%w after %v sending %q", ErrTimeout, s.timeout(), text)
+	}
+}
+
+// SendLine types text and a newline.
+func (s *Session) SendLine(text string) error {
+	return s.Send(text + "\n")
+}
+
+// Close ends the input, waits for the output to end and for the program
+// to exit, and returns its error.
+func (s *Session) Close() error {
+	s.in.Close()
+	timer := time.NewTimer(s.timeout())
+	defer timer.Stop()
+	select {
+	case <-s.done:
+	case <-timer.C:
+		return fmt.Errorf("%w after %v waiting for the program to end", ErrTimeout, s.timeout())
+	}
+	exited := make(chan error, 1)
+	go func() { exited <- s.wait() }()
+	select {
+	case err := <-exited:
+		return err
+	case <-timer.C:
+		return fmt.Errorf("%w after %v waiting for the program to exit", ErrTimeout, s.timeout())
+	}
+}
+
+// Kill stops the program and closes the session. A Command is killed, a
+// Func has its pipes closed and ends at its next read or write.
+func (s *Session) Kill() error {
+	s.kill()
+	return s.Close()
+}
+
+// Output returns everything the program wrote so far.
+func (s *Session) Output() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.output.String()
+}
+
+// Transcript returns the output with the text sent interleaved where it
+// was typed.
+func (s *Session) Transcript() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.transcript.String()
+}
+
+// Rule answers a prompt: every time Prompt appears the next of Answers is
+// sent as a line.
+type Rule struct {
+	Prompt  string
+	Answers []string
+}
+
+// Run answers prompts by rules until the program ends, then closes the
+// session. A prompt whose rule has no answers left ends the run with
+// ErrNoAnswer. A run that fails kills the program, so none is left behind.
+func (s *Session) Run(rules ...Rule) error {
+	prompts := make([]string, len(rules))
+	next := make([]int, len(rules))
+	for i, r := range rules {
+		prompts[i] = r.Prompt
+	}
+	for {
+		i, err := s.Expect(prompts...)
+		if errors.Is(err, ErrEOF) {
+			return s.Close()
+		}
+		if err != nil {
+			return s.abort(err)
+		}
+		r := rules[i]
+		if next[i] == len(r.Answers) {
+			return s.abort(fmt.Errorf("%w for %q after %d", ErrNoAnswer, r.Prompt, len(r.Answers)))
+		}
+		if err := s.SendLine(r.Answers[next[i]]); err != nil {
+			return s.abort(err)
+		}
+		next[i]++
+	}
+}
+
+// abort kills the program after a failed step and returns err.
+func (s *Session) abort(err error) error {
+	s.Kill()
+	return err
+}
@@ -0,0 +1,173 @@
+package expect
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"gitlab.com/jhinrichsen/lunar-lander/lunar"
+)
+
+var perfect = strings.Fields("0 0 0 0 0 0 200 200 200 200 200 0 0 100 200 200 0 0 71 37")
+
+func lander() *Session {
+	return Func(func(in io.Reader, out io.Writer) error {
+		lunar.NewGame(lunar.Classic(), in, out).Run()
+		return nil
+	})
+}
+
+func TestRun(t *testing.T) {
+	s := lander()
+	err := s.Run(Rule{Prompt: "K=:", Answers: perfect},
+		Rule{Prompt: "(ANS. YES OR NO)", Answers: []string{"NO"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := os.ReadFile("../lunar/testdata/perfect.out")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Output() != string(want) {
+		t.Fatalf("want perfect.out, got\n%s", s.Output())
+	}
+	if !strings.Contains(s.Transcript(), "K=:37\nON THE MOON AT") {
+		t.Fatalf("want answers in the transcript, got\n%s", s.Transcript())
+	}
+}
+
+func TestExpect(t *testing.T) {
+	s := lander()
+	if i, err := s.Expect("FUEL RATE", "CONTROL CALLING"); err != nil || i != 1 {
+		t.Fatalf("want the first of both, 1, got %d, %v", i, err)
+	}
+	m, err := s.ExpectRE(regexp.MustCompile(`YOU'VE (\d+) LBS`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m[1] != "16000" {
+		t.Fatalf("want 16000, got %q", m)
+	}
+	for range 12 {
+		if _, err := s.Expect("K=:"); err != nil {
+			t.Fatal(err)
+		}
+		if err := s.SendLine("0"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	m, err = s.ExpectRE(regexp.MustCompile(`IMPACT VELOCITY OF\s+(\S+)M`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m[1] != "4008.79" {
+		t.Fatalf("want 4008.79, got %q", m[1])
+	}
+	if _, err := s.Expect("(ANS. YES OR NO)"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.SendLine("NO"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Expect("K=:"); !errors.Is(err, ErrEOF) {
+		t.Fatalf("want %v, got %v", ErrEOF, err)
+	}
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestTimeout(t *testing.T) {
+	s := Func(func(in io.Reader, out io.Writer) error {
+		fmt.Fprint(out, "K=:")
+		_, err := io.ReadAll(in)
+		return err
+	})
+	s.Timeout = 20 * time.Millisecond
+	_, err := s.Expect("(ANS. YES OR NO)")
+	if !errors.Is(err, ErrTimeout) {
+		t.Fatalf("want %v, got %v", ErrTimeout, err)
+	}
+	if !strings.Contains(err.Error(), `"K=:"`) {
+		t.Fatalf("want the last output in %q", err)
+	}
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// a program that stops reading
+	s = Func(func(in io.Reader, out io.Writer) error {
+		select {}
+	})
+	s.Timeout = 20 * time.Millisecond
+	if err := s.SendLine("0"); !errors.Is(err, ErrTimeout) {
+		t.Fatalf("want %v, got %v", ErrTimeout, err)
+	}
+}
+
+func TestNoAnswer(t *testing.T) {
+	s := lander()
+	err := s.Run(Rule{Prompt: "K=:", Answers: perfect[:3]})
+	if !errors.Is(err, ErrNoAnswer) {
+		t.Fatalf("want %v, got %v", ErrNoAnswer, err)
+	}
+	s.Close()
+}
+
+func TestFuncError(t *testing.T) {
+	boom := errors.New("boom")
+	s := Func(func(in io.Reader, out io.Writer) error {
+		return boom
+	})
+	if err := s.Run(); err != boom {
+		t.Fatalf("want %v, got %v", boom, err)
+	}
+}
+
+func TestCommand(t *testing.T) {
+	if _, err := exec.LookPath("cat"); err != nil {
+		t.Skip("cat not found in PATH")
+	}
+	s, err := Command(exec.Command("cat"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.SendLine("K=:"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Expect("K=:"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if got := s.Transcript(); got != "K=:\nK=:\n" {
+		t.Fatalf("want input and echo, got %q", got)
+	}
+}
+
+// TestRunTimeout checks that a run that times out leaves no process
+// behind.
+func TestRunTimeout(t *testing.T) {
+	if _, err := exec.LookPath("cat"); err != nil {
+		t.Skip("cat not found in PATH")
+	}
+	cmd := exec.Command("cat")
+	s, err := Command(cmd)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.Timeout = 20 * time.Millisecond
+	if err := s.Run(Rule{Prompt: "K=:"}); !errors.Is(err, ErrTimeout) {
+		t.Fatalf("want %v, got %v", ErrTimeout, err)
+	}
+	if cmd.ProcessState == nil {
+		t.Fatal("want cat ended after the timeout, still running")
+	}
+}
@@ -0,0 +1,69 @@
+package expect
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Row is a status line of the lander: time, altitude in miles and feet,
+// velocity and fuel, as printed before the K prompt.
+type Row struct {
+	Time, Miles, Feet, Velocity, Fuel float64
+}
+
+// Flight is what a transcript of the lander tells about a flight.
+type Flight struct {
+	Rows []Row
+	// FuelOut is the time the fuel ran out, 0 if it did not.
+	FuelOut float64
+	// Landed tells whether the flight reached the surface; Time,
+	// Velocity and Fuel are the landing report then, Verdict the line
+	// after it.
+	Landed               bool
+	Time, Velocity, Fuel float64
+	Verdict              string
+}
+
+const number = `(-?[0-9.]+(?:E[-+]?[0-9]+)?)`
+
+var (
+	rowRE     = regexp.MustCompile(strings.Repeat(number+`\s+`, 5) + `K=:`)
+	fuelOutRE = regexp.MustCompile(`FUEL OUT AT\s+` + number + `\s+SECS`)
+	landingRE = regexp.MustCompile(`ON THE MOON AT\s+` + number + `\s+SECS\s*\n` +
+		`IMPACT VELOCITY OF\s+` + number + `\s*M\.P\.H\.\s*\n` +
+		`FUEL LEFT:\s+` + number + `\s+LBS\s*\n(.*)`)
+)
+
+// Telemetry extracts the flight from the output or transcript of the
+// lander, the first flight if it was tried again. It reads the English
+// units of the original.
+func Telemetry(transcript string) Flight {
+	var f Flight
+	if m := landingRE.FindStringSubmatchIndex(transcript); m != nil {
+		f.Landed = true
+		f.Time = atof(transcript[m[2]:m[3]])
+		f.Velocity = atof(transcript[m[4]:m[5]])
+		f.Fuel = atof(transcript[m[6]:m[7]])
+		f.Verdict = strings.TrimSpace(transcript[m[8]:m[9]])
+		transcript = transcript[:m[0]]
+	}
+	if m := fuelOutRE.FindStringSubmatch(transcript); m != nil {
+		f.FuelOut = atof(m[1])
+	}
+	for _, m := range rowRE.FindAllStringSubmatch(transcript, -1) {
+		f.Rows = append(f.Rows, Row{
+			Time:     atof(m[1]),
+			Miles:    atof(m[2]),
+			Feet:     atof(m[3]),
+			Velocity: atof(m[4]),
+			Fuel:     atof(m[5]),
+		})
+	}
+	return f
+}
+
+func atof(s string) float64 {
+	x, _ := strconv.ParseFloat(s, 64)
+	return x
+}
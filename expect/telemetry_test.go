@@ -0,0 +1,54 @@
+package expect
+
+import (
+	"os"
+	"testing"
+)
+
+func TestTelemetry(t *testing.T) {
+	b, err := os.ReadFile("../lunar/testdata/perfect.out")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := Telemetry(string(b))
+	if !f.Landed || f.Time != 190.34 || f.Velocity != 0.66 || f.Fuel != 277.60 {
+		t.Fatalf("want landing at 190.34 s, 0.66 mph, 277.6 lbs, got %+v", f)
+	}
+	if f.Verdict != "PERFECT LANDING !-(LUCKY)" {
+		t.Fatalf("want perfect landing, got %q", f.Verdict)
+	}
+	if len(f.Rows) != 20 {
+		t.Fatalf("want 20 rows, got %d", len(f.Rows))
+	}
+	want := Row{Time: 190, Miles: 0, Feet: 1, Velocity: 4.24, Fuel: 290}
+	if got := f.Rows[19]; got != want {
+		t.Fatalf("want %+v, got %+v", want, got)
+	}
+	if f.FuelOut != 0 {
+		t.Fatalf("want fuel left, got out at %v", f.FuelOut)
+	}
+}
+
+func TestTelemetryCrash(t *testing.T) {
+	b, err := os.ReadFile("../lunar/testdata/crash.out")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := Telemetry(string(b))
+	if f.FuelOut != 80 {
+		t.Fatalf("want fuel out at 80, got %v", f.FuelOut)
+	}
+	if !f.Landed || f.Fuel != 0 {
+		t.Fatalf("want landing without fuel, got %+v", f)
+	}
+	if len(f.Rows) != 8 || f.Rows[0] != (Row{Miles: 120, Velocity: 3600, Fuel: 16000}) {
+		t.Fatalf("want 8 rows from 120 miles, got %+v", f.Rows)
+	}
+}
+
+func TestTelemetryNone(t *testing.T) {
+	f := Telemetry("CONTROL OUT\n")
+	if f.Landed || len(f.Rows) != 0 {
+		t.Fatalf("want nothing, got %+v", f)
+	}
+}
@@ -0,0 +1,250 @@
+package fidelity
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strings"
+)
+
+// Tolerance says when two numbers agree. They agree if they are equal or
+// within any of the three bounds; the zero Tolerance wants them equal.
+type Tolerance struct {
+	// Digits is the number of significant figures that must agree: the
+	// difference may be half a unit in the last of them.
+	Digits int
+	// Abs is the largest absolute difference.
+	Abs float64
+	// Rel is the largest difference relative to the larger magnitude.
+	Rel float64
+}
+
+// Equal reports whether a and b agree within t.
+func (t Tolerance) Equal(a, b float64) bool {
+	if a == b {
+		return true
+	}
+	d := math.Abs(a - b)
+	m := math.Max(math.Abs(a), math.Abs(b))
+	if d <= t.Abs || d <= t.Rel*m {
+		return true
+	}
+	if t.Digits > 0 {
+		exp := math.Floor(math.Log10(m))
+		// half a unit in the last place, rounded up against float noise
+		return d <= math.Pow(10, exp-float64(t.Digits)+1)/2*(1+1e-9)
+	}
+	return false
+}
+
+// Status is how a line of the port compares to the transcript.
+type Status int
+
+// Statuses of lines, from faithful to missing.
+const (
+	Same    Status = iota // equal text and numbers
+	Close                 // equal text, numbers within tolerance
+	Differ                // different text or numbers out of tolerance
+	Missing               // only in the transcript
+	Extra                 // only in the port's output
+)
+
+// marks are the sdiff style markers of statuses.
+var marks = [...]byte{Same: ' ', Close: '~', Differ: '|', Missing: '<', Extra: '>'}
+
+// String returns the name of s.
+func (s Status) String() string {
+	return [...]string{"same", "close", "differ", "missing", "extra"}[s]
+}
+
+// Line is a line of the comparison.
+type Line struct {
+	Want, Got string
+	Status    Status
+	// Dev is the largest relative deviation of a number of the line.
+	Dev float64
+}
+
+// Report is the result of Compare.
+type Report struct {
+	Lines []Line
+	// Numbers counts the numbers compared, Close those that were not
+	// equal but within tolerance, Wrong those out of tolerance.
+	Numbers, Close, Wrong int
+	// Dev is the largest relative deviation of any number compared.
+	Dev float64
+}
+
+// Lines splits a transcript into lines at newlines and after each K=:
+// prompt, where the echo of the answer ended the line on the teletype.
+func Lines(s string) []string {
+	s = strings.ReplaceAll(s, "K=:", "K=:\n")
+	var ls []string
+	for _, l := range strings.Split(s, "\n") {
+		if strings.TrimSpace(l) != "" {
+			ls = append(ls, l)
+		}
+	}
+	return ls
+}
+
+// line is a tokenized line.
+type line struct {
+	s      string
+	tokens []Token
+	shape  string // the text with numbers replaced by #
+}
+
+func parse(ls []string) []line {
+	ps := make([]line, len(ls))
+	for i, l := range ls {
+		ts := Tokenize(l)
+		shape := make([]string, len(ts))
+		for j, t := range ts {
+			shape[j] = t.Text
+			if t.IsNum {
+				shape[j] = "#"
+			}
+		}
+		ps[i] = line{l, ts, strings.Join(shape, " ")}
+	}
+	return ps
+}
+
+// Compare compares the output of a port, got, with the transcript want.
+// Lines are aligned by their text, numbers left out, the way diff aligns
+// lines; blank lines do not count.
+func Compare(want, got string, tol Tolerance) *Report {
+	w, g := parse(Lines(want)), parse(Lines(got))
+	// lcs[i][j] is the length of the longest common subsequence of
+	// shapes of w[i:] and g[j:]
+	lcs := make([][]int, len(w)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(g)+1)
+	}
+	for i := len(w) - 1; i >= 0; i-- {
+		for j := len(g) - 1; j >= 0; j-- {
+			if w[i].shape == g[j].shape {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+	r := &Report{}
+	var dw, dg []line // unmatched since the last match
+	flush := func() {
+		for k := range max(len(dw), len(dg)) {
+			switch {
+			case k >= len(dg):
+				r.Lines = append(r.Lines, Line{Want: dw[k].s, Status: Missing})
+			case k >= len(dw):
+				r.Lines = append(r.Lines, Line{Got: dg[k].s, Status: Extra})
+			default:
+				r.Lines = append(r.Lines, Line{Want: dw[k].s, Got: dg[k].s, Status: Differ})
+			}
+		}
+		dw, dg = nil, nil
+	}
+	i, j := 0, 0
+	for i < len(w) || j < len(g) {
+		switch {
+		case i < len(w) && j < len(g) && w[i].shape == g[j].shape:
+			flush()
+			r.Lines = append(r.Lines, r.numbers(w[i], g[j], tol))
+			i++
+			j++
+		case j == len(g) || i < len(w) && lcs[i+1][j] >= lcs[i][j+1]:
+			dw = append(dw, w[i])
+			i++
+		default:
+			dg = append(dg, g[j])
+			j++
+		}
+	}
+	flush()
+	return r
+}
+
+// numbers compares the numbers of two lines of the same shape.
+func (r *Report) numbers(w, g line, tol Tolerance) Line {
+	l := Line{Want: w.s, Got: g.s, Status: Same}
+	for k, t := range w.tokens {
+		if !t.IsNum {
+			continue
+		}
+		u := g.tokens[k]
+		r.Numbers++
+		if t.Num == u.Num {
+			continue
+		}
+		dev := math.Abs(t.Num-u.Num) / math.Max(math.Abs(t.Num), math.Abs(u.Num))
+		l.Dev = math.Max(l.Dev, dev)
+		r.Dev = math.Max(r.Dev, dev)
+		if tol.Equal(t.Num, u.Num) {
+			r.Close++
+			l.Status = max(l.Status, Close)
+		} else {
+			r.Wrong++
+			l.Status = Differ
+		}
+	}
+	return l
+}
+
+// Count returns the number of lines with status s.
+func (r *Report) Count(s Status) int {
+	n := 0
+	for _, l := range r.Lines {
+		if l.Status == s {
+			n++
+		}
+	}
+	return n
+}
+
+// Faithful reports whether every line is the same or close.
+func (r *Report) Faithful() bool {
+	return r.Count(Same)+r.Count(Close) == len(r.Lines)
+}
+
+// Score is the fraction of lines that are the same or close, 1 for a
+// faithful port.
+func (r *Report) Score() float64 {
+	if len(r.Lines) == 0 {
+		return 1
+	}
+	return float64(r.Count(Same)+r.Count(Close)) / float64(len(r.Lines))
+}
+
+// Summary returns the counts of the report in one line.
+func (r *Report) Summary() string {
+	return fmt.Sprintf("score %.3f: %d lines, %d same, %d close, %d differ, %d missing, %d extra; "+
+		"%d numbers, %d close, %d wrong, largest deviation %.3g",
+		r.Score(), len(r.Lines), r.Count(Same), r.Count(Close), r.Count(Differ),
+		r.Count(Missing), r.Count(Extra), r.Numbers, r.Close, r.Wrong, r.Dev)
+}
+
+// SideBySide writes the transcript on the left and the port on the right,
+// each cut to width columns, with a marker between them: blank for the
+// same, ~ for close, | for different, < and > for lines only on one side.
+// With all false only lines that are not the same are written.
+func (r *Report) SideBySide(w io.Writer, width int, all bool) error {
+	cut := func(s string) string {
+		s = strings.TrimRight(s, " ")
+		if len(s) > width {
+			return s[:width]
+		}
+		return s
+	}
+	for _, l := range r.Lines {
+		if !all && l.Status == Same {
+			continue
+		}
+		s := fmt.Sprintf("%-*s %c %s", width, cut(l.Want), marks[l.Status], cut(l.Got))
+		if _, err := fmt.Fprintln(w, strings.TrimRight(s, " ")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
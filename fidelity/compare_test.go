@@ -0,0 +1,109 @@
+package fidelity
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestTolerance(t *testing.T) {
+	for _, tt := range []struct {
+		tol  Tolerance
+		a, b float64
+		want bool
+	}{
+		{Tolerance{}, 1, 1, true},
+		{Tolerance{}, 1, 1.0000001, false},
+		{Tolerance{Digits: 3}, 0.66, 0.6604, true},
+		{Tolerance{Digits: 3}, 0.66, 0.661, false},
+		{Tolerance{Digits: 3}, 3600, 3604, true},
+		{Tolerance{Digits: 3}, 3600, 3606, false},
+		{Tolerance{Digits: 3}, 277.60, 277.65, true},
+		{Tolerance{Digits: 3}, 0, 0.001, false},
+		{Tolerance{Abs: 0.01}, 0, 0.01, true},
+		{Tolerance{Rel: 0.01}, 100, 101, true},
+		{Tolerance{Rel: 0.01}, 100, 102, false},
+	} {
+		if got := tt.tol.Equal(tt.a, tt.b); got != tt.want {
+			t.Errorf("%+v %v %v: want %v, got %v", tt.tol, tt.a, tt.b, tt.want, got)
+		}
+	}
+}
+
+func perfect(t *testing.T) string {
+	t.Helper()
+	b, err := os.ReadFile("../lunar/testdata/perfect.out")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b)
+}
+
+func TestCompareSame(t *testing.T) {
+	want := perfect(t)
+	// spacing does not count
+	got := strings.ReplaceAll(want, "   ", " ")
+	r := Compare(want, got, Tolerance{})
+	if !r.Faithful() || r.Score() != 1 || r.Close != 0 {
+		t.Fatal(r.Summary())
+	}
+	if r.Numbers < 100 {
+		t.Fatalf("want the numbers of 20 rows, got %d", r.Numbers)
+	}
+}
+
+func TestCompareClose(t *testing.T) {
+	want := perfect(t)
+	got := strings.Replace(want, "277.60 LBS", "277.63 LBS", 1)
+	got = strings.Replace(got, "0.66M.P.H.", "0.67M.P.H.", 1)
+	r := Compare(want, got, Tolerance{Digits: 3})
+	if r.Faithful() || r.Close != 1 || r.Wrong != 1 {
+		t.Fatal(r.Summary())
+	}
+	if r.Count(Close) != 1 || r.Count(Differ) != 1 {
+		t.Fatal(r.Summary())
+	}
+	if r.Dev < 0.01 || r.Dev > 0.02 {
+		t.Fatalf("want the deviation of 0.66 and 0.67, got %v", r.Dev)
+	}
+}
+
+func TestCompareLines(t *testing.T) {
+	want := "A 1\nB 2\nC 3\nD 4\n"
+	got := "A 1\nX 2\nC 3\nC 3\nD 5\n"
+	r := Compare(want, got, Tolerance{Digits: 3})
+	var st []string
+	for _, l := range r.Lines {
+		st = append(st, l.Status.String())
+	}
+	if got, want := strings.Join(st, " "), "same differ same extra differ"; got != want {
+		t.Fatalf("want %s, got %s", want, got)
+	}
+	if r.Score() != 0.4 {
+		t.Fatalf("want score 0.4, got %v", r.Score())
+	}
+	r = Compare(want, "A 1\n", Tolerance{})
+	if r.Count(Missing) != 3 {
+		t.Fatal(r.Summary())
+	}
+}
+
+func TestSideBySide(t *testing.T) {
+	r := Compare("FUEL LEFT: 277.60 LBS\nB\nPERFECT LANDING\n", "FUEL LEFT: 277.61 LBS\nPERFECT LANDING\nTRY AGAIN\n", Tolerance{Digits: 3})
+	var sb strings.Builder
+	if err := r.SideBySide(&sb, 12, true); err != nil {
+		t.Fatal(err)
+	}
+	want := "FUEL LEFT: 2 ~ FUEL LEFT: 2\n" +
+		"B            <\n" +
+		"PERFECT LAND   PERFECT LAND\n" +
+		"             > TRY AGAIN\n"
+	if sb.String() != want {
+		t.Fatalf("want\n%s\ngot\n%s", want, sb.String())
+	}
+	sb.Reset()
+	r.SideBySide(&sb, 12, false)
+	if strings.Contains(sb.String(), "PERFECT") {
+		t.Fatalf("want only what differs, got\n%s", sb.String())
+	}
+}
@@ -0,0 +1,97 @@
+// Package fidelity grades how faithfully a port reproduces a transcript.
+// PROMPT.md asks for output matching the original to three significant
+// figures; a byte comparison fails a port for a last digit rounded the
+// other way just as for a wrong formula.
+//
+// Compare breaks both transcripts into lines and each line into text and
+// numbers. Text and the layout of a line must agree, spacing aside;
+// numbers must agree within a Tolerance. The Report lists the lines side
+// by side, marks those that are only close and those that differ, and
+// counts them into a score.
+package fidelity
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Token is a word of text or a number of a line.
+type Token struct {
+	Text  string // as printed
+	Num   float64
+	IsNum bool
+}
+
+// Tokenize splits line at blanks and between text and numbers. A number
+// is digits with an optional decimal point and E exponent; a sign belongs
+// to it unless it follows a letter or digit, so TIME-120 is TIME- and 120.
+func Tokenize(line string) []Token {
+	var ts []Token
+	text := func(s string) {
+		if s != "" {
+			ts = append(ts, Token{Text: s})
+		}
+	}
+	for _, f := range strings.Fields(line) {
+		start := 0 // of the text not yet a token
+		for i := 0; i < len(f); {
+			n := numberAt(f, i)
+			if n == 0 {
+				i++
+				continue
+			}
+			text(f[start:i])
+			x, _ := strconv.ParseFloat(f[i:i+n], 64)
+			ts = append(ts, Token{Text: f[i : i+n], Num: x, IsNum: true})
+			i += n
+			start = i
+		}
+		text(f[start:])
+	}
+	return ts
+}
+
+// numberAt returns the length of the number starting at s[i], 0 if none
+// does.
+func numberAt(s string, i int) int {
+	isDigit := func(j int) bool { return j < len(s) && s[j] >= '0' && s[j] <= '9' }
+	isAlnum := func(j int) bool {
+		return j >= 0 && (isDigit(j) || s[j] >= 'A' && s[j] <= 'Z' || s[j] >= 'a' && s[j] <= 'z')
+	}
+	if isAlnum(i-1) && !isDigit(i) {
+		return 0
+	}
+	j := i
+	if s[j] == '-' || s[j] == '+' {
+		j++
+	}
+	digits := 0
+	for isDigit(j) {
+		j++
+		digits++
+	}
+	if j < len(s) && s[j] == '.' {
+		j++
+		for isDigit(j) {
+			j++
+			digits++
+		}
+	}
+	if digits == 0 {
+		return 0
+	}
+	// an exponent needs digits, E alone is text as in 0YES
+	if j < len(s) && s[j] == 'E' {
+		k := j + 1
+		if k < len(s) && (s[k] == '-' || s[k] == '+') {
+			k++
+		}
+		if isDigit(k) {
+			for isDigit(k) {
+				k++
+			}
+			j = k
+		}
+	}
+	return j - i
+}
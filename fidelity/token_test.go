@@ -0,0 +1,35 @@
+package fidelity
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTokenize(t *testing.T) {
+	for _, tt := range []struct {
+		line string
+		want []string
+	}{
+		{"IMPACT VELOCITY OF     0.66M.P.H.", []string{"IMPACT", "VELOCITY", "OF", "#0.66", "M.P.H."}},
+		{"TIME-120 SECS. CAPSULE WEIGHT-32500 LBS", []string{"TIME-", "#120", "SECS.", "CAPSULE", "WEIGHT-", "#32500", "LBS"}},
+		{"  170    0  4988   200.63  1000.0  K=:", []string{"#170", "#0", "#4988", "#200.63", "#1000.0", "K=:"}},
+		{"= -1.234500E+04 0YES .5", []string{"=", "#-1.234500E+04", "#0", "YES", "#.5"}},
+		{"-(LUCKY) 5.E", []string{"-(LUCKY)", "#5.", "E"}},
+		{"", nil},
+	} {
+		var got []string
+		for _, tok := range Tokenize(tt.line) {
+			s := tok.Text
+			if tok.IsNum {
+				s = "#" + s
+			}
+			got = append(got, s)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%q: want %q, got %q", tt.line, tt.want, got)
+		}
+	}
+	if ts := Tokenize("-1.5E2"); ts[0].Num != -150 {
+		t.Errorf("want -150, got %v", ts[0].Num)
+	}
+}
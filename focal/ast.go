@@ -0,0 +1,479 @@
+package focal
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Node is any part of the syntax tree. String returns the node in
+// canonical FOCAL syntax.
+type Node interface {
+	String() string
+}
+
+// Stmt is a FOCAL command.
+type Stmt interface {
+	Node
+	stmt()
+}
+
+// Item is an element of a TYPE or ASK list: Text, Newline, CR, Format or
+// an expression (TYPE) or variable (ASK).
+type Item interface {
+	Node
+	item()
+}
+
+// Expr is an arithmetic expression.
+type Expr interface {
+	Item
+	expr()
+}
+
+// Program is a FOCAL program, its lines in ascending order.
+type Program struct {
+	Lines []*Line
+}
+
+// Line is a numbered line of statements.
+type Line struct {
+	Num    Num
+	Stmts  []Stmt
+	Source string // statements as written
+}
+
+func (l *Line) String() string {
+	return l.Num.String() + " " + l.Source
+}
+
+// Line returns line n or nil.
+func (p *Program) Line(n Num) *Line {
+	i, ok := p.search(n)
+	if !ok {
+		return nil
+	}
+	return p.Lines[i]
+}
+
+// Resolve returns the line a GOTO or DO of n starts at: line n itself or,
+// for a group number, the first line of the group. Zero, a G without
+// target, is the first line of the program.
+func (p *Program) Resolve(n Num) *Line {
+	if n == 0 && len(p.Lines) > 0 {
+		return p.Lines[0]
+	}
+	if !n.IsGroup() {
+		return p.Line(n)
+	}
+	if g := p.Group(n.Group()); len(g) > 0 {
+		return g[0]
+	}
+	return nil
+}
+
+// Group returns the lines of group g.
+func (p *Program) Group(g int) []*Line {
+	i, _ := p.search(Num(g * 100))
+	j, _ := p.search(Num((g + 1) * 100))
+	return p.Lines[i:j]
+}
+
+// Next returns the line after n or nil.
+func (p *Program) Next(n Num) *Line {
+	i, ok := p.search(n)
+	if ok {
+		i++
+	}
+	if i == len(p.Lines) {
+		return nil
+	}
+	return p.Lines[i]
+}
+
+// Insert adds l in order, replacing a line with the same number.
+func (p *Program) Insert(l *Line) {
+	i, ok := p.search(l.Num)
+	if ok {
+		p.Lines[i] = l
+		return
+	}
+	p.Lines = slices.Insert(p.Lines, i, l)
+}
+
+// Delete removes line n, or all lines of group n.
+func (p *Program) Delete(n Num) {
+	if n.IsGroup() {
+		i, _ := p.search(n)
+		j, _ := p.search(Num((n.Group() + 1) * 100))
+		p.Lines = slices.Delete(p.Lines, i, j)
+		return
+	}
+	if i, ok := p.search(n); ok {
+		p.Lines = slices.Delete(p.Lines, i, i+1)
+	}
+}
+
+func (p *Program) search(n Num) (int, bool) {
+	return slices.BinarySearchFunc(p.Lines, n, func(l *Line, n Num) int {
+		return int(l.Num - n)
+	})
+}
+
+// String lists the program with a blank line between groups, the layout of
+// lunar-lander.fc.
+func (p *Program) String() string {
+	var b strings.Builder
+	for i, l := range p.Lines {
+		if i > 0 && l.Num.Group() != p.Lines[i-1].Num.Group() {
+			b.WriteByte('\n')
+		}
+		b.WriteString(l.String())
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+// Statements.
+type (
+	// Type is T, the output statement.
+	Type struct{ Items []Item }
+
+	// Ask is A, the input statement.
+	Ask struct{ Items []Item }
+
+	// Set is S var=expr.
+	Set struct {
+		Var *Var
+		X   Expr
+	}
+
+	// Goto is G n. A zero target starts at the first line.
+	Goto struct{ Target Num }
+
+	// Do is D n, calling a line or a whole group.
+	Do struct{ Target Num }
+
+	// If is I (x)a,b,c, jumping to a, b or c when x is negative, zero or
+	// positive. Missing or zero targets continue with the next statement.
+	If struct {
+		Cond    Expr
+		Targets []Num
+	}
+
+	// For is F var=start,step,end or F var=start,end; the rest of the
+	// line is its body.
+	For struct {
+		Var              *Var
+		Start, Step, End Expr // Step is nil for increments of 1
+		Body             []Stmt
+	}
+
+	// Erase is E, clearing all variables.
+	Erase struct{}
+
+	// Quit is Q.
+	Quit struct{}
+
+	// Return is R, leaving a DO.
+	Return struct{}
+
+	// Comment is C, ignoring the rest of the line.
+	Comment struct{ Text string }
+)
+
+func (*Type) stmt()    {}
+func (*Ask) stmt()     {}
+func (*Set) stmt()     {}
+func (*Goto) stmt()    {}
+func (*Do) stmt()      {}
+func (*If) stmt()      {}
+func (*For) stmt()     {}
+func (*Erase) stmt()   {}
+func (*Quit) stmt()    {}
+func (*Return) stmt()  {}
+func (*Comment) stmt() {}
+
+func (s *Type) String() string { return "T " + items(s.Items) }
+func (s *Ask) String() string  { return "A " + items(s.Items) }
+func (s *Set) String() string  { return "S " + s.Var.String() + "=" + s.X.String() }
+
+func (s *Goto) String() string {
+	if s.Target == 0 {
+		return "G"
+	}
+	return "G " + target(s.Target)
+}
+
+func (s *Do) String() string { return "D " + target(s.Target) }
+
+func (s *If) String() string {
+	ts := make([]string, len(s.Targets))
+	for i, t := range s.Targets {
+		if t != 0 {
+			ts[i] = target(t)
+		}
+	}
+	return "I (" + s.Cond.String() + ")" + strings.Join(ts, ",")
+}
+
+func (s *For) String() string {
+	h := "F " + s.Var.String() + "=" + s.Start.String()
+	if s.Step != nil {
+		h += "," + s.Step.String()
+	}
+	h += "," + s.End.String()
+	return strings.Join(append([]string{h}, stmts(s.Body)...), ";")
+}
+
+func (*Erase) String() string     { return "E" }
+func (*Quit) String() string      { return "Q" }
+func (*Return) String() string    { return "R" }
+func (s *Comment) String() string { return "C" + s.Text }
+
+// target prints a jump target the short way FOCAL programmers write it,
+// 2.7 for 02.70 and 9 for group 9.
+func target(n Num) string {
+	if n.IsGroup() {
+		return strconv.Itoa(n.Group())
+	}
+	return strings.TrimSuffix(fmt.Sprintf("%d.%02d", n.Group(), n.Step()), "0")
+}
+
+func stmts(ss []Stmt) []string {
+	s := make([]string, len(ss))
+	for i, st := range ss {
+		s[i] = st.String()
+	}
+	return s
+}
+
+// items joins a TYPE or ASK list, with commas only where they separate.
+func items(is []Item) string {
+	var b strings.Builder
+	for i, it := range is {
+		_, nl := it.(Newline)
+		_, cr := it.(CR)
+		if i > 0 && !nl && !cr {
+			_, pnl := is[i-1].(Newline)
+			_, pcr := is[i-1].(CR)
+			if !pnl && !pcr {
+				b.WriteByte(',')
+			}
+		}
+		b.WriteString(it.String())
+	}
+	return b.String()
+}
+
+// List items.
+type (
+	// Text is a quoted string.
+	Text string
+
+	// Newline is !, carriage return and line feed.
+	Newline struct{}
+
+	// CR is #, carriage return without line feed.
+	CR struct{}
+
+	// Format is %n.m, sticky until the next format. A bare % selects
+	// floating point (E) output.
+	Format struct {
+		Width, Digits int
+		E             bool
+	}
+)
+
+func (Text) item()    {}
+func (Newline) item() {}
+func (CR) item()      {}
+func (Format) item()  {}
+
+func (t Text) String() string  { return `"` + string(t) + `"` }
+func (Newline) String() string { return "!" }
+func (CR) String() string      { return "#" }
+
+func (f Format) String() string {
+	switch {
+	case f.E:
+		return "%"
+	case f.Digits == 0:
+		return fmt.Sprintf("%%%d", f.Width)
+	}
+	return fmt.Sprintf("%%%d.%02d", f.Width, f.Digits)
+}
+
+// Expressions.
+type (
+	// Number is a constant. Text keeps the spelling of the source, such
+	// as .001 or the letter encoding 0NO.
+	Number struct {
+		Value float64
+		Text  string
+	}
+
+	// Var is a variable, optionally subscripted. FOCAL-69 tells variables
+	// apart by their first two characters, Name holds those.
+	Var struct {
+		Name  string
+		Index Expr // nil unless subscripted
+	}
+
+	// Unary is -x or +x.
+	Unary struct {
+		Op byte
+		X  Expr
+	}
+
+	// Binary is x op y with op one of + - * / ^.
+	Binary struct {
+		Op   byte
+		X, Y Expr
+	}
+
+	// Call is a function call such as FSQT(x). Arg is nil for FRAN().
+	Call struct {
+		Name string // four letters, FSQT
+		Arg  Expr
+	}
+)
+
+func (*Number) item() {}
+func (*Var) item()    {}
+func (*Unary) item()  {}
+func (*Binary) item() {}
+func (*Call) item()   {}
+
+func (*Number) expr() {}
+func (*Var) expr()    {}
+func (*Unary) expr()  {}
+func (*Binary) expr() {}
+func (*Call) expr()   {}
+
+func (x *Number) String() string {
+	if x.Text != "" {
+		return x.Text
+	}
+	return strconv.FormatFloat(x.Value, 'g', -1, 64)
+}
+
+func (x *Var) String() string {
+	if x.Index == nil {
+		return x.Name
+	}
+	return x.Name + "(" + x.Index.String() + ")"
+}
+
+func (x *Unary) String() string {
+	return string(x.Op) + paren(x.X, unary, false)
+}
+
+func (x *Binary) String() string {
+	p := Precedence(x.Op)
+	// ^ groups to the right, everything else to the left.
+	return paren(x.X, p, x.Op == '^') + string(x.Op) + paren(x.Y, p, x.Op != '^')
+}
+
+func (x *Call) String() string {
+	if x.Arg == nil {
+		return x.Name + "()"
+	}
+	return x.Name + "(" + x.Arg.String() + ")"
+}
+
+// Precedence returns the binding strength of a binary operator, higher
+// binds tighter.
+func Precedence(op byte) int {
+	switch op {
+	case '+', '-':
+		return 1
+	case '*', '/':
+		return 2
+	case '^':
+		return 4
+	}
+	return 0
+}
+
+// unary is the binding strength of a sign: -A*B is (-A)*B but -A^2 is
+// -(A^2).
+const unary = 3
+
+// paren prints x inside an operator of precedence p, in parentheses if it
+// binds weaker, or equally strong on the side that does not associate.
+func paren(x Expr, p int, tie bool) string {
+	var q int
+	switch x := x.(type) {
+	case *Binary:
+		q = Precedence(x.Op)
+	case *Unary:
+		q = unary
+	default:
+		return x.String()
+	}
+	if q < p || (q == p && tie) {
+		return "(" + x.String() + ")"
+	}
+	return x.String()
+}
+
+// Inspect traverses n depth first, calling f for every node including the
+// body of FOR and the lines of a program. When f returns false the
+// children of that node are skipped.
+func Inspect(n Node, f func(Node) bool) {
+	if n == nil || !f(n) {
+		return
+	}
+	each := func(ns ...Node) {
+		for _, c := range ns {
+			if c != nil {
+				Inspect(c, f)
+			}
+		}
+	}
+	switch n := n.(type) {
+	case *Program:
+		for _, l := range n.Lines {
+			Inspect(l, f)
+		}
+	case *Line:
+		for _, s := range n.Stmts {
+			Inspect(s, f)
+		}
+	case *Type:
+		for _, it := range n.Items {
+			Inspect(it, f)
+		}
+	case *Ask:
+		for _, it := range n.Items {
+			Inspect(it, f)
+		}
+	case *Set:
+		each(n.Var, n.X)
+	case *If:
+		each(n.Cond)
+	case *For:
+		each(n.Var, n.Start)
+		if n.Step != nil {
+			each(n.Step)
+		}
+		each(n.End)
+		for _, s := range n.Body {
+			Inspect(s, f)
+		}
+	case *Var:
+		if n.Index != nil {
+			each(n.Index)
+		}
+	case *Unary:
+		each(n.X)
+	case *Binary:
+		each(n.X, n.Y)
+	case *Call:
+		if n.Arg != nil {
+			each(n.Arg)
+		}
+	}
+}
@@ -0,0 +1,232 @@
+package focal
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// EdgeKind tells how control passes from one line to another.
+type EdgeKind int
+
+const (
+	// NextEdge falls through to the following line.
+	NextEdge EdgeKind = iota
+	// GotoEdge is a GOTO.
+	GotoEdge
+	// IfEdge is an arm of an IF.
+	IfEdge
+	// DoEdge is a DO; control comes back to the calling line.
+	DoEdge
+)
+
+// Edge is a transfer of control between lines.
+type Edge struct {
+	From, To Num
+	Kind     EdgeKind
+	Label    string // the conditions of an IF edge, such as <=0
+}
+
+// Graph is the control-flow graph of a program at line granularity.
+type Graph struct {
+	Prog  *Program
+	Edges []Edge
+
+	// reach holds every line execution can get to, and main the lines
+	// it can get to outside of a DO.
+	reach, main map[Num]bool
+}
+
+// arms labels IF edges by the conditions that take them, indexed by a bit
+// set of negative (1), zero (2) and positive (4).
+var arms = [8]string{1: "<0", 2: "=0", 3: "<=0", 4: ">0", 5: "<>0", 6: ">=0", 7: "any"}
+
+// Flow builds the control-flow graph of p. Lines are reachable from the
+// first line by falling through, GOTO, IF and DO. Within a DO, falling off
+// the end of the group returns to the caller instead of continuing with
+// the next group, so that edge is only drawn where the main program can
+// take it.
+func Flow(p *Program) *Graph {
+	g := &Graph{Prog: p, reach: map[Num]bool{}, main: map[Num]bool{}}
+	g.walk()
+	for _, l := range p.Lines {
+		for _, s := range l.Stmts {
+			conds := map[Num]int{}
+			var order []Num
+			jumps([]Stmt{s}, func(s Stmt, arm int, t Num) {
+				r := p.Resolve(t)
+				if r == nil {
+					return
+				}
+				switch s.(type) {
+				case *Goto:
+					g.Edges = append(g.Edges, Edge{From: l.Num, To: r.Num, Kind: GotoEdge})
+				case *Do:
+					g.Edges = append(g.Edges, Edge{From: l.Num, To: r.Num, Kind: DoEdge})
+				case *If:
+					if conds[r.Num] == 0 {
+						order = append(order, r.Num)
+					}
+					conds[r.Num] |= 1 << arm
+				}
+			})
+			for _, t := range order {
+				g.Edges = append(g.Edges, Edge{From: l.Num, To: t, Kind: IfEdge, Label: arms[conds[t]]})
+			}
+		}
+		next := p.Next(l.Num)
+		if next == nil || Terminates(l.Stmts) {
+			continue
+		}
+		// Across groups only the main program falls through; lines
+		// nothing reaches keep all their edges.
+		if next.Num.Group() == l.Num.Group() || g.main[l.Num] || !g.reach[l.Num] {
+			g.Edges = append(g.Edges, Edge{From: l.Num, To: next.Num, Kind: NextEdge})
+		}
+	}
+	return g
+}
+
+// jumps calls f for every GOTO, IF arm and DO target in ss, including FOR
+// bodies. arm is the index of an IF target.
+func jumps(ss []Stmt, f func(s Stmt, arm int, t Num)) {
+	for _, s := range ss {
+		switch s := s.(type) {
+		case *Goto:
+			f(s, 0, s.Target)
+		case *If:
+			for i, t := range s.Targets {
+				if t != 0 {
+					f(s, i, t)
+				}
+			}
+		case *Do:
+			f(s, 0, s.Target)
+		case *For:
+			jumps(s.Body, f)
+		}
+	}
+}
+
+// Terminates reports whether a line with statements ss never continues
+// with the next line: it ends in GOTO, RETURN, QUIT or an IF with all three
+// targets.
+func Terminates(ss []Stmt) bool {
+	for _, s := range ss {
+		switch s := s.(type) {
+		case *Goto, *Return, *Quit:
+			return true
+		case *If:
+			if len(s.Targets) == 3 && !slices.Contains(s.Targets, 0) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// walk finds the reachable lines. A context is 0 for the main program or
+// the number of the group or line being done.
+func (g *Graph) walk() {
+	p := g.Prog
+	if len(p.Lines) == 0 {
+		return
+	}
+	type state struct{ line, ctx Num }
+	seen := map[state]bool{}
+	work := []state{{p.Lines[0].Num, 0}}
+	push := func(l *Line, ctx Num) {
+		if l != nil && !seen[state{l.Num, ctx}] {
+			work = append(work, state{l.Num, ctx})
+		}
+	}
+	for len(work) > 0 {
+		s := work[len(work)-1]
+		work = work[:len(work)-1]
+		if seen[s] {
+			continue
+		}
+		seen[s] = true
+		g.reach[s.line] = true
+		if s.ctx == 0 {
+			g.main[s.line] = true
+		}
+		l := p.Line(s.line)
+		jumps(l.Stmts, func(st Stmt, _ int, t Num) {
+			if _, ok := st.(*Do); ok {
+				push(p.Resolve(t), t)
+				return
+			}
+			push(p.Resolve(t), s.ctx)
+		})
+		if Terminates(l.Stmts) {
+			continue
+		}
+		next := p.Next(s.line)
+		switch {
+		case next == nil:
+		case s.ctx == 0:
+			push(next, 0)
+		case s.ctx.IsGroup() && next.Num.Group() == s.ctx.Group():
+			push(next, s.ctx)
+		}
+	}
+}
+
+// Reachable reports whether execution can get to line n.
+func (g *Graph) Reachable(n Num) bool {
+	return g.reach[n]
+}
+
+// WriteDot writes the graph in Graphviz DOT, one cluster per group. GOTOs
+// are bold, IF arms labelled with their condition, DOs dashed;
+// unreachable lines are grey.
+func (g *Graph) WriteDot(w io.Writer, name string) error {
+	b := bufio.NewWriter(w)
+	fmt.Fprintf(b, "digraph %s {\n", strconv.Quote(name))
+	fmt.Fprintln(b, "\tnode [shape=box fontname=\"Courier\" fontsize=10];")
+	fmt.Fprintln(b, "\tedge [fontname=\"Courier\" fontsize=9];")
+	lines := g.Prog.Lines
+	for i, l := range lines {
+		if i == 0 || lines[i-1].Num.Group() != l.Num.Group() {
+			fmt.Fprintf(b, "\tsubgraph cluster_%d {\n\t\tlabel=\"GROUP %d\";\n", l.Num.Group(), l.Num.Group())
+		}
+		attr := ""
+		if !g.reach[l.Num] {
+			attr = " color=gray fontcolor=gray"
+		}
+		fmt.Fprintf(b, "\t\t%q [label=%s%s];\n", l.Num.String(), strconv.Quote(l.Num.String()+" "+abbrev(l.Source, 40)), attr)
+		if i == len(lines)-1 || lines[i+1].Num.Group() != l.Num.Group() {
+			fmt.Fprintln(b, "\t}")
+		}
+	}
+	for _, e := range g.Edges {
+		var attr []string
+		switch e.Kind {
+		case GotoEdge:
+			attr = append(attr, "style=bold")
+		case IfEdge:
+			attr = append(attr, "label="+strconv.Quote(e.Label))
+		case DoEdge:
+			attr = append(attr, "style=dashed", `label="DO"`)
+		}
+		fmt.Fprintf(b, "\t%q -> %q", e.From.String(), e.To.String())
+		if len(attr) > 0 {
+			fmt.Fprintf(b, " [%s]", strings.Join(attr, " "))
+		}
+		fmt.Fprintln(b, ";")
+	}
+	fmt.Fprintln(b, "}")
+	return b.Flush()
+}
+
+// abbrev shortens s to n characters.
+func abbrev(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n-3] + "..."
+}
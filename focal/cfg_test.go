@@ -0,0 +1,96 @@
+package focal
+
+import (
+	"strings"
+	"testing"
+)
+
+func program(t *testing.T, src string) *Program {
+	t.Helper()
+	p, err := Parse(strings.NewReader(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func hasEdge(g *Graph, e Edge) bool {
+	for _, f := range g.Edges {
+		if f == e {
+			return true
+		}
+	}
+	return false
+}
+
+func TestFlowLander(t *testing.T) {
+	p := lander(t)
+	g := Flow(p)
+	for _, l := range p.Lines {
+		if !g.Reachable(l.Num) {
+			t.Errorf("%s: want reachable", l.Num)
+		}
+	}
+	for _, e := range []Edge{
+		{From: 594, To: 120, Kind: IfEdge, Label: "=0"},
+		{From: 594, To: 592, Kind: IfEdge, Label: "<>0"},
+		{From: 380, To: 610, Kind: DoEdge},
+		{From: 380, To: 310, Kind: GotoEdge},
+		{From: 150, To: 210, Kind: NextEdge},
+	} {
+		if !hasEdge(g, e) {
+			t.Errorf("missing edge %+v", e)
+		}
+	}
+	for _, e := range g.Edges {
+		// Group 6 is only done, it returns instead of running into 07.10.
+		if e.From == 610 && e.To == 710 {
+			t.Errorf("unexpected edge %+v", e)
+		}
+		if e.From == 380 && e.Kind == NextEdge {
+			t.Errorf("unexpected edge %+v after a GOTO", e)
+		}
+	}
+}
+
+func TestFlowUnreachable(t *testing.T) {
+	g := Flow(program(t, `
+01.10 D 2;Q
+01.20 T "DEAD"
+02.10 T "HI"
+02.20 G 1.2
+`))
+	for n, want := range map[Num]bool{110: true, 120: true, 210: true, 220: true} {
+		if g.Reachable(n) != want {
+			t.Errorf("%s: want reachable %v", n, want)
+		}
+	}
+	g = Flow(program(t, `
+01.10 T "A";Q
+01.20 T "B"
+02.10 I (X) 1.2,1.2,1.2
+`))
+	if g.Reachable(120) || g.Reachable(210) {
+		t.Error("want only 01.10 reachable")
+	}
+	if !hasEdge(g, Edge{From: 210, To: 120, Kind: IfEdge, Label: "any"}) {
+		t.Errorf("want IF edges of unreachable lines, got %+v", g.Edges)
+	}
+}
+
+func TestWriteDot(t *testing.T) {
+	var b strings.Builder
+	if err := Flow(program(t, "01.10 D 2;Q\n01.20 T X\n02.10 R\n")).WriteDot(&b, "t.fc"); err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{
+		`digraph "t.fc" {`,
+		"subgraph cluster_2 {",
+		`"01.20" [label="01.20 T X" color=gray fontcolor=gray];`,
+		`"01.10" -> "02.10" [style=dashed label="DO"];`,
+	} {
+		if !strings.Contains(b.String(), s) {
+			t.Errorf("missing %q in\n%s", s, b.String())
+		}
+	}
+}
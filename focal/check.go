@@ -0,0 +1,261 @@
+package focal
+
+import (
+	"cmp"
+	"fmt"
+	"maps"
+	"slices"
+)
+
+// Diagnostic is a problem Check found in a line.
+type Diagnostic struct {
+	Line Num
+	Msg  string
+}
+
+func (d Diagnostic) String() string {
+	return d.Line.String() + ": " + d.Msg
+}
+
+// Check reports jumps to lines or groups that do not exist, lines that
+// cannot be reached, and variables that may be used before a SET, ASK or
+// FOR gives them a value, in line order.
+//
+// FOCAL line numbers are two digit fractions: the G 1.2 of 05.94 in
+// lunar-lander.fc goes to 01.20 and is fine. Using a variable before
+// setting it is legal, FOCAL reads it as 0; lunar-lander.fc relies on that
+// for the clock L after the E of 01.20.
+func Check(p *Program) []Diagnostic {
+	var ds []Diagnostic
+	for _, l := range p.Lines {
+		jumps(l.Stmts, func(s Stmt, _ int, t Num) {
+			if p.Resolve(t) != nil {
+				return
+			}
+			cmd := map[bool]string{true: "GOTO", false: "IF"}[isGoto(s)]
+			msg := fmt.Sprintf("%s %s: no line %s", cmd, target(t), t)
+			if _, ok := s.(*Do); ok {
+				msg = fmt.Sprintf("DO %s: no line %s", target(t), t)
+				if t.IsGroup() {
+					msg = fmt.Sprintf("DO %d: no group %d", t.Group(), t.Group())
+				}
+			}
+			ds = append(ds, Diagnostic{l.Num, msg})
+		})
+	}
+	g := Flow(p)
+	for _, l := range p.Lines {
+		if !g.Reachable(l.Num) {
+			ds = append(ds, Diagnostic{l.Num, "unreachable"})
+		}
+	}
+	ds = append(ds, unset(g)...)
+	slices.SortStableFunc(ds, func(a, b Diagnostic) int {
+		return cmp.Compare(a.Line, b.Line)
+	})
+	return slices.Compact(ds)
+}
+
+func isGoto(s Stmt) bool {
+	_, ok := s.(*Goto)
+	return ok
+}
+
+// defined is the set of variables certainly set at a point of the program.
+// nil stands for all variables: the optimistic start of the analysis for
+// code it has not reached yet.
+type defined map[string]bool
+
+func (d defined) has(v string) bool {
+	return d == nil || d[v]
+}
+
+// meet intersects incoming with the state at a line and reports a change.
+// A missing state has not been reached yet.
+func meet(states map[Num]defined, n Num, incoming defined) bool {
+	old, ok := states[n]
+	switch {
+	case !ok || (old == nil && incoming != nil):
+		states[n] = maps.Clone(incoming)
+		return true
+	case incoming == nil:
+		return false
+	}
+	changed := false
+	for v := range old {
+		if !incoming[v] {
+			delete(old, v)
+			changed = true
+		}
+	}
+	return changed
+}
+
+// flow is the must-be-set analysis. It is context insensitive: a group
+// starts with what all its DOs have in common, and a DO continues with what
+// the group sets added to the caller's variables.
+type flow struct {
+	g       *Graph
+	in      map[Num]defined // at the start of a line
+	exit    map[Num]defined // when a DO of a line or group returns
+	changed bool
+	report  func(n Num, v string)
+}
+
+// unset reports variables that may be used before they are set.
+func unset(g *Graph) []Diagnostic {
+	p := g.Prog
+	if len(p.Lines) == 0 {
+		return nil
+	}
+	f := &flow{g: g, in: map[Num]defined{p.Lines[0].Num: {}}, exit: map[Num]defined{}}
+	for f.changed = true; f.changed; {
+		f.changed = false
+		for _, l := range p.Lines {
+			if d, ok := f.in[l.Num]; ok {
+				f.line(l, maps.Clone(d))
+			}
+		}
+	}
+	var ds []Diagnostic
+	f.report = func(n Num, v string) {
+		ds = append(ds, Diagnostic{n, v + " may be used before it is set"})
+	}
+	for _, l := range p.Lines {
+		if d, ok := f.in[l.Num]; ok {
+			f.line(l, maps.Clone(d))
+		}
+	}
+	slices.SortFunc(ds, func(a, b Diagnostic) int {
+		return cmp.Or(cmp.Compare(a.Line, b.Line), cmp.Compare(a.Msg, b.Msg))
+	})
+	return ds
+}
+
+func (f *flow) to(n Num, d defined) {
+	if meet(f.in, n, d) {
+		f.changed = true
+	}
+}
+
+func (f *flow) ret(n Num, d defined) {
+	if meet(f.exit, n, d) {
+		f.changed = true
+	}
+}
+
+// line runs the statements of l on d and passes the result on to the
+// lines that follow.
+func (f *flow) line(l *Line, d defined) {
+	d, done := f.stmts(l, l.Stmts, d)
+	if done {
+		return
+	}
+	// A DO of this line returns here, a DO of its group too if it is
+	// the last line of the group.
+	f.ret(l.Num, d)
+	next := f.g.Prog.Next(l.Num)
+	if next == nil || next.Num.Group() != l.Num.Group() {
+		f.ret(Num(l.Num.Group()*100), d)
+	}
+	if next != nil && (next.Num.Group() == l.Num.Group() || f.g.main[l.Num]) {
+		f.to(next.Num, d)
+	}
+}
+
+// stmts returns the variables set after ss and whether ss always jump
+// away.
+func (f *flow) stmts(l *Line, ss []Stmt, d defined) (defined, bool) {
+	p := f.g.Prog
+	// A variable is reported once per path: after its first use it
+	// counts as set.
+	use := func(x Node) {
+		Inspect(x, func(n Node) bool {
+			if v, ok := n.(*Var); ok && v.Index == nil && !d.has(v.Name) {
+				if f.report != nil {
+					f.report(l.Num, v.Name)
+				}
+				d[v.Name] = true
+			}
+			return true
+		})
+	}
+	set := func(v *Var) {
+		if v.Index != nil {
+			use(v.Index)
+		} else if d != nil {
+			d[v.Name] = true
+		}
+	}
+	jump := func(t Num) {
+		if r := p.Resolve(t); r != nil {
+			f.to(r.Num, d)
+		}
+	}
+	for _, s := range ss {
+		switch s := s.(type) {
+		case *Type:
+			for _, it := range s.Items {
+				if x, ok := it.(Expr); ok {
+					use(x)
+				}
+			}
+		case *Ask:
+			for _, it := range s.Items {
+				if v, ok := it.(*Var); ok {
+					set(v)
+				}
+			}
+		case *Set:
+			use(s.X)
+			set(s.Var)
+		case *For:
+			use(s.Start)
+			if s.Step != nil {
+				use(s.Step)
+			}
+			use(s.End)
+			set(s.Var)
+			if after, done := f.stmts(l, s.Body, maps.Clone(d)); !done {
+				d = after
+			}
+		case *If:
+			use(s.Cond)
+			for _, t := range s.Targets {
+				if t != 0 {
+					jump(t)
+				}
+			}
+			if Terminates([]Stmt{s}) {
+				return d, true
+			}
+		case *Goto:
+			jump(s.Target)
+			return d, true
+		case *Do:
+			r := p.Resolve(s.Target)
+			if r == nil {
+				continue
+			}
+			f.to(r.Num, d)
+			ex, ok := f.exit[s.Target]
+			if !ok || ex == nil {
+				// Not known to return yet.
+				d = nil
+				continue
+			}
+			if d != nil {
+				maps.Copy(d, ex)
+			}
+		case *Erase:
+			d = defined{}
+		case *Return:
+			f.ret(l.Num, d)
+			f.ret(Num(l.Num.Group()*100), d)
+			return d, true
+		case *Quit:
+			return d, true
+		}
+	}
+	return d, false
+}
@@ -0,0 +1,76 @@
+package focal
+
+import (
+	"slices"
+	"testing"
+)
+
+func diagnostics(ds []Diagnostic) []string {
+	var ss []string
+	for _, d := range ds {
+		ss = append(ss, d.String())
+	}
+	return ss
+}
+
+func TestCheckLander(t *testing.T) {
+	// L is read as 0 after the E of 01.20, each game counts from there.
+	want := []string{"02.10: L may be used before it is set"}
+	if got := diagnostics(Check(lander(t))); !slices.Equal(got, want) {
+		t.Errorf("want %q, got %q", want, got)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want []string
+	}{
+		{"targets", `
+01.10 S X=1;I (X) 1.3,3.1;D 4;D 1.4
+01.20 G 2.2
+01.30 Q
+`, []string{
+			"01.10: IF 3.1: no line 03.10",
+			"01.10: DO 4: no group 4",
+			"01.10: DO 1.4: no line 01.40",
+			"01.20: GOTO 2.2: no line 02.20",
+		}},
+		{"unreachable", `
+01.10 G 1.3
+01.20 T "NEVER"
+01.30 Q
+02.10 T "NOR THIS"
+`, []string{"01.20: unreachable", "02.10: unreachable"}},
+		{"set by ask, for and do", `
+01.10 A A;F I=1,3;T I
+01.20 D 2;T A+B+C
+01.30 Q
+02.10 S B=1
+02.20 S C=B
+`, nil},
+		{"erase", `
+01.10 S A=1;E;T A
+01.20 T A
+`, []string{"01.10: A may be used before it is set"}},
+		{"one path", `
+01.10 A X;I (X) 1.3
+01.20 S Y=1
+01.30 T Y
+`, []string{"01.30: Y may be used before it is set"}},
+		{"loop", `
+01.10 S I=0
+01.20 S I=I+1;I (I-3) 1.2;T J
+01.30 S J=1;G 1.2
+`, []string{"01.20: J may be used before it is set"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := diagnostics(Check(program(t, tt.src)))
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("want %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
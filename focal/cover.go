@@ -0,0 +1,88 @@
+package focal
+
+// Coverage counts how often the lines of a program started and which arms
+// its IF statements took, over any number of runs.
+type Coverage struct {
+	Prog  *Program
+	Runs  int
+	Lines map[Num]int
+	arms  map[*If]*[3]int
+}
+
+// Branch is an IF statement and how often it took each arm: negative,
+// zero and positive.
+type Branch struct {
+	Line Num
+	If   *If
+	Hits [3]int
+}
+
+// NewCoverage returns empty counts for p.
+func NewCoverage(p *Program) *Coverage {
+	return &Coverage{Prog: p, Lines: map[Num]int{}, arms: map[*If]*[3]int{}}
+}
+
+// Run runs the program on tty and adds to the counts. Like Interp.Run it
+// returns io.EOF if the input ran out; the counts up to there are kept.
+func (c *Coverage) Run(tty *Terminal) error {
+	c.Runs++
+	in := NewInterp(c.Prog, tty)
+	in.branch = func(s *If, arm int) {
+		if c.arms[s] == nil {
+			c.arms[s] = new([3]int)
+		}
+		c.arms[s][arm]++
+	}
+	for !in.Done() {
+		if in.AtLineStart() {
+			n, _, _ := in.Pos()
+			c.Lines[n]++
+		}
+		if err := in.Step(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Branches returns the IF statements of the program in order.
+func (c *Coverage) Branches() []Branch {
+	var bs []Branch
+	var walk func(l *Line, ss []Stmt)
+	walk = func(l *Line, ss []Stmt) {
+		for _, s := range ss {
+			switch s := s.(type) {
+			case *If:
+				b := Branch{Line: l.Num, If: s}
+				if h := c.arms[s]; h != nil {
+					b.Hits = *h
+				}
+				bs = append(bs, b)
+			case *For:
+				walk(l, s.Body)
+			}
+		}
+	}
+	for _, l := range c.Prog.Lines {
+		walk(l, l.Stmts)
+	}
+	return bs
+}
+
+// Summary returns the number of lines and IF arms that ran and how many
+// there are.
+func (c *Coverage) Summary() (lines, allLines, arms, allArms int) {
+	for _, l := range c.Prog.Lines {
+		if c.Lines[l.Num] > 0 {
+			lines++
+		}
+	}
+	for _, b := range c.Branches() {
+		for _, h := range b.Hits {
+			if h > 0 {
+				arms++
+			}
+		}
+	}
+	return lines, len(c.Prog.Lines), arms, 3 * len(c.Branches())
+}
@@ -0,0 +1,67 @@
+package focal
+
+import (
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestCoverage(t *testing.T) {
+	c := NewCoverage(program(t, `01.10 A X;F I=1,2;I (X) 1.3
+01.20 T "POSITIVE";Q
+01.30 D 2;I (X) 1.4,,1.4
+01.40 Q
+02.10 R`))
+	for _, in := range []string{"-1\n", "0\n", ""} {
+		err := c.Run(NewTerminal(strings.NewReader(in), io.Discard))
+		if err != nil && !errors.Is(err, io.EOF) {
+			t.Fatal(err)
+		}
+	}
+	if c.Runs != 3 {
+		t.Errorf("want 3 runs, got %d", c.Runs)
+	}
+	for n, want := range map[Num]int{110: 3, 120: 1, 130: 1, 140: 1, 210: 1} {
+		if got := c.Lines[n]; got != want {
+			t.Errorf("%s: want %d, got %d", n, want, got)
+		}
+	}
+	bs := c.Branches()
+	if len(bs) != 2 {
+		t.Fatalf("want 2 IFs, got %v", bs)
+	}
+	// -1 leaves the FOR at once, 0 runs the IF in its body twice.
+	if bs[0].Line != 110 || bs[0].Hits != [3]int{1, 2, 0} {
+		t.Errorf("want 01.10 IF <0 1 =0 2, got %+v", bs[0])
+	}
+	if bs[1].Line != 130 || bs[1].Hits != [3]int{1, 0, 0} {
+		t.Errorf("want 01.30 IF <0 1, got %+v", bs[1])
+	}
+	lines, allLines, arms, allArms := c.Summary()
+	if lines != 5 || allLines != 5 || arms != 3 || allArms != 6 {
+		t.Errorf("want 5/5 lines and 3/6 arms, got %d/%d %d/%d", lines, allLines, arms, allArms)
+	}
+}
+
+func TestCoverageLander(t *testing.T) {
+	c := NewCoverage(lander(t))
+	in, err := os.Open("../lunar/testdata/perfect.in")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer in.Close()
+	if err := c.Run(NewTerminal(in, io.Discard)); err != nil {
+		t.Fatal(err)
+	}
+	if c.Lines[410] != 0 || c.Lines[510] != 1 || c.Lines[598] != 1 {
+		t.Errorf("want a landing with fuel left, got %v", c.Lines)
+	}
+	for _, b := range c.Branches() {
+		// 05.40 sends the perfect landing on to the message.
+		if b.Line == 540 && b.Hits != [3]int{0, 0, 1} {
+			t.Errorf("want 05.40 >0 once, got %v", b.Hits)
+		}
+	}
+}
@@ -0,0 +1,182 @@
+package focal
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+)
+
+// Stop tells why the debugger handed back control.
+type Stop int
+
+const (
+	// Stepped finished a Step or Next.
+	Stepped Stop = iota
+	// Breakpoint reached the start of a line with a breakpoint.
+	Breakpoint
+	// Watchpoint changed a watched expression.
+	Watchpoint
+	// Ended ran the program to its end.
+	Ended
+)
+
+func (s Stop) String() string {
+	return [...]string{"stepped", "breakpoint", "watchpoint", "ended"}[s]
+}
+
+// Watch is an expression the debugger evaluates after every statement.
+type Watch struct {
+	Expr    Expr
+	Value   float64
+	Changed bool // by the last statement
+}
+
+// Debugger runs a program under control: breakpoints on lines, single steps
+// per statement and watched expressions. It starts before the first line.
+type Debugger struct {
+	*Interp
+	breaks  map[Num]bool
+	watches []*Watch
+	begun   bool // a statement ran or Continue stopped before the first
+}
+
+// NewDebugger returns a debugger for p on tty.
+func NewDebugger(p *Program, tty *Terminal) *Debugger {
+	return &Debugger{Interp: NewInterp(p, tty), breaks: map[Num]bool{}}
+}
+
+// Break sets a breakpoint at line n, which must exist.
+func (d *Debugger) Break(n Num) error {
+	if d.Prog.Line(n) == nil {
+		return fmt.Errorf("no line %s", n)
+	}
+	d.breaks[n] = true
+	return nil
+}
+
+// Clear removes the breakpoint at line n.
+func (d *Debugger) Clear(n Num) {
+	delete(d.breaks, n)
+}
+
+// Breakpoints returns the lines with breakpoints in order.
+func (d *Debugger) Breakpoints() []Num {
+	return slices.Sorted(maps.Keys(d.breaks))
+}
+
+// Watch adds an expression such as A or M-N to watch. FRAN() is refused,
+// evaluating it would change the numbers the program draws.
+func (d *Debugger) Watch(src string) (*Watch, error) {
+	x, err := ParseExpr(src)
+	if err != nil {
+		return nil, err
+	}
+	if err := pure(x); err != nil {
+		return nil, err
+	}
+	w := &Watch{Expr: x, Value: d.Eval(x)}
+	d.watches = append(d.watches, w)
+	return w, nil
+}
+
+// Watches returns the watched expressions in the order they were added.
+func (d *Debugger) Watches() []*Watch {
+	return d.watches
+}
+
+// pure returns an error if x calls FRAN.
+func pure(x Expr) error {
+	var err error
+	Inspect(x, func(n Node) bool {
+		if c, ok := n.(*Call); ok && c.Name == "FRAN" {
+			err = fmt.Errorf("%s: FRAN() would change the program's random numbers", x)
+		}
+		return err == nil
+	})
+	return err
+}
+
+// Print evaluates an expression such as FSQT(V) in the program's variables.
+func (d *Debugger) Print(src string) (float64, error) {
+	x, err := ParseExpr(src)
+	if err != nil {
+		return 0, err
+	}
+	if err := pure(x); err != nil {
+		return 0, err
+	}
+	return d.Eval(x), nil
+}
+
+// Step runs one statement and updates the watches.
+func (d *Debugger) Step() error {
+	d.begun = true
+	err := d.Interp.Step()
+	for _, w := range d.watches {
+		v := d.Eval(w.Expr)
+		// NaN never equals itself; a watch stuck at NaN is no change.
+		w.Changed = v != w.Value && (v == v || w.Value == w.Value)
+		w.Value = v
+	}
+	return err
+}
+
+// changed reports whether the last statement changed a watch.
+func (d *Debugger) changed() bool {
+	return slices.ContainsFunc(d.watches, func(w *Watch) bool { return w.Changed })
+}
+
+// Next runs one statement like Step, but runs a DO to its return. It stops
+// early at breakpoints and watch changes inside the DO.
+func (d *Debugger) Next() (Stop, error) {
+	depth := len(d.Stack())
+	if err := d.Step(); err != nil {
+		return Ended, err
+	}
+	for len(d.Stack()) > depth {
+		if s, ok := d.stopped(); ok {
+			return s, nil
+		}
+		if err := d.Step(); err != nil {
+			return Ended, err
+		}
+	}
+	if d.Done() {
+		return Ended, nil
+	}
+	return Stepped, nil
+}
+
+// Continue runs until a breakpoint, a watch change or the end. A
+// breakpoint on the first line stops before the program starts.
+func (d *Debugger) Continue() (Stop, error) {
+	if !d.begun {
+		d.begun = true
+		if s, ok := d.stopped(); ok {
+			return s, nil
+		}
+	}
+	for {
+		if err := d.Step(); err != nil {
+			return Ended, err
+		}
+		if s, ok := d.stopped(); ok {
+			return s, nil
+		}
+	}
+}
+
+// stopped reports whether the program has to stop after a statement.
+func (d *Debugger) stopped() (Stop, bool) {
+	switch {
+	case d.Done():
+		return Ended, true
+	case d.changed():
+		return Watchpoint, true
+	}
+	n, _, _ := d.Pos()
+	if d.AtLineStart() && d.breaks[n] {
+		return Breakpoint, true
+	}
+	return 0, false
+}
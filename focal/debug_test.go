@@ -0,0 +1,129 @@
+package focal
+
+import (
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func landerDebugger(t *testing.T) *Debugger {
+	t.Helper()
+	in, err := os.Open("../lunar/testdata/perfect.in")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { in.Close() })
+	return NewDebugger(lander(t), NewTerminal(in, io.Discard))
+}
+
+func TestDebuggerBreakpoint(t *testing.T) {
+	d := landerDebugger(t)
+	if err := d.Break(610); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.Break(611); err == nil {
+		t.Error("want an error for a breakpoint on a missing line")
+	}
+	for i := range 3 {
+		s, err := d.Continue()
+		if err != nil {
+			t.Fatal(err)
+		}
+		n, st, _ := d.Pos()
+		if s != Breakpoint || n != 610 || st.String() != "S L=L+S" {
+			t.Fatalf("want breakpoint at 06.10, got %v at %s %v", s, n, st)
+		}
+		if want := []Frame{{380, 600}}; !slices.Equal(d.Stack(), want) {
+			t.Errorf("want stack %v, got %v", want, d.Stack())
+		}
+		// Each round of group 3 burns 10 seconds of the first K of 0.
+		if l := d.Var("L"); l != float64(10*i) {
+			t.Errorf("round %d: want L=%d, got %v", i, 10*i, l)
+		}
+	}
+	d.Clear(610)
+	if s, err := d.Continue(); s != Ended || err != nil {
+		t.Errorf("want the end, got %v %v", s, err)
+	}
+}
+
+func TestDebuggerBreakFirstLine(t *testing.T) {
+	d := landerDebugger(t)
+	first := d.Prog.Lines[0].Num
+	if err := d.Break(first); err != nil {
+		t.Fatal(err)
+	}
+	s, err := d.Continue()
+	if n, _, _ := d.Pos(); s != Breakpoint || err != nil || n != first {
+		t.Fatalf("want breakpoint at %s, got %v %v at %s", first, s, err, n)
+	}
+	d.Clear(first)
+	if s, err := d.Continue(); s != Ended || err != nil {
+		t.Errorf("want the end, got %v %v", s, err)
+	}
+}
+
+func TestDebuggerWatch(t *testing.T) {
+	d := landerDebugger(t)
+	w, err := d.Watch("A")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := d.Watch("A+FRAN()"); err == nil {
+		t.Error("want FRAN() refused")
+	}
+	s, err := d.Continue()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n, _, _ := d.Pos(); s != Watchpoint || !w.Changed || w.Value != 120 || n != 150 {
+		t.Errorf("want A=120 set in 01.50, got %v %+v at %s", s, w, n)
+	}
+	if v, err := d.Print("M-N"); err != nil || v != 0 {
+		t.Errorf("want M-N=0 before S M, got %v %v", v, err)
+	}
+	if _, err := d.Print("M+"); err == nil {
+		t.Error("want a syntax error")
+	}
+	d.watches = nil
+	if _, err := d.Watch("M-N"); err != nil {
+		t.Fatal(err)
+	}
+	d.Continue() // S M
+	s, err = d.Continue()
+	if err != nil || s != Watchpoint || d.Watches()[0].Value != 16000 {
+		t.Errorf("want M-N=16000, got %v %+v %v", s, d.Watches()[0], err)
+	}
+}
+
+func TestDebuggerNext(t *testing.T) {
+	p := program(t, `01.10 D 2;S B=1
+01.20 D 2;Q
+02.10 S A=A+1
+02.20 S A=A+1`)
+	d := NewDebugger(p, NewTerminal(strings.NewReader(""), io.Discard))
+	if s, err := d.Next(); s != Stepped || err != nil {
+		t.Fatalf("want stepped, got %v %v", s, err)
+	}
+	if n, st, _ := d.Pos(); n != 110 || st.String() != "S B=1" || d.Var("A") != 2 {
+		t.Errorf("want to be past the DO with A=2, got %s %v %v", n, st, d.Vars())
+	}
+	d.Break(220)
+	if s, _ := d.Next(); s != Stepped {
+		t.Errorf("want stepped, got %v", s)
+	}
+	if s, _ := d.Next(); s != Breakpoint {
+		t.Errorf("want the breakpoint inside the DO, got %v", s)
+	}
+	if got := d.Breakpoints(); !slices.Equal(got, []Num{220}) {
+		t.Errorf("want breakpoint 02.20, got %v", got)
+	}
+	if s, _ := d.Next(); s != Stepped {
+		t.Errorf("want back at the Q, got %v", s)
+	}
+	if s, _ := d.Next(); s != Ended {
+		t.Errorf("want the end, got %v", s)
+	}
+}
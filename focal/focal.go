@@ -0,0 +1,92 @@
+// Package focal reads FOCAL-69 programs such as lunar-lander.fc into a
+// syntax tree and provides the teletype runtime that translated programs
+// run on, an interpreter and a debugger built on it.
+//
+// FOCAL numbers its lines gg.ll, group and line within the group. The
+// fractional part is a two digit field, so 1.2 and 01.20 name the same line.
+package focal
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Num is a line number gg.ll stored as gg*100+ll. A line part of 00 refers
+// to a whole group, as in D 9.
+type Num int
+
+// Largest group number of FOCAL-69.
+const MaxGroup = 31
+
+// Group returns gg.
+func (n Num) Group() int {
+	return int(n) / 100
+}
+
+// Step returns ll, the line within the group.
+func (n Num) Step() int {
+	return int(n) % 100
+}
+
+// IsGroup reports whether n refers to a whole group.
+func (n Num) IsGroup() bool {
+	return n.Step() == 0
+}
+
+// String formats n the way FOCAL lists it, 01.20.
+func (n Num) String() string {
+	return fmt.Sprintf("%02d.%02d", n.Group(), n.Step())
+}
+
+// ParseNum parses a line or group number such as 2.7, 02.70 or 9.
+func ParseNum(s string) (Num, error) {
+	g, l, dot := strings.Cut(s, ".")
+	if len(l) > 2 {
+		return 0, fmt.Errorf("line number %q: more than two line digits", s)
+	}
+	if dot && l == "" {
+		l = "0"
+	}
+	gg, err := strconv.Atoi(g)
+	if err != nil || gg < 1 || gg > MaxGroup {
+		return 0, fmt.Errorf("line number %q: group must be 1..%d", s, MaxGroup)
+	}
+	ll := 0
+	if dot {
+		ll, err = strconv.Atoi(l)
+		if err != nil || l[0] == '-' || l[0] == '+' {
+			return 0, fmt.Errorf("line number %q: bad line part", s)
+		}
+		if len(l) == 1 {
+			ll *= 10
+		}
+	}
+	return Num(gg*100 + ll), nil
+}
+
+// Functions are the FOCAL-69 functions of one argument. FRAN, the random
+// number generator, takes none and is provided by Terminal.
+var Functions = map[string]func(float64) float64{
+	"FABS": math.Abs,
+	"FATN": math.Atan,
+	"FCOS": math.Cos,
+	"FEXP": math.Exp,
+	"FITR": math.Trunc,
+	"FLOG": math.Log,
+	"FSGN": Sgn,
+	"FSIN": math.Sin,
+	"FSQT": math.Sqrt,
+}
+
+// Sgn returns -1, 0 or 1 (FSGN).
+func Sgn(x float64) float64 {
+	switch {
+	case x < 0:
+		return -1
+	case x > 0:
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,42 @@
+package focal
+
+import "testing"
+
+func TestParseNum(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Num
+	}{
+		{"1.2", 120},
+		{"01.20", 120},
+		{"5.81", 581},
+		{"2.7", 270},
+		{"9", 900},
+		{"9.", 900},
+		{"31.99", 3199},
+	}
+	for _, tt := range tests {
+		got, err := ParseNum(tt.in)
+		if err != nil {
+			t.Errorf("%s: %v", tt.in, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%s: want %d, got %d", tt.in, tt.want, got)
+		}
+	}
+	for _, in := range []string{"", "0.10", "32.10", "1.234", "1.-5", "x"} {
+		if _, err := ParseNum(in); err == nil {
+			t.Errorf("%q: want error", in)
+		}
+	}
+}
+
+func TestNumString(t *testing.T) {
+	if got := Num(120).String(); got != "01.20" {
+		t.Errorf("want 01.20, got %s", got)
+	}
+	if n := Num(900); !n.IsGroup() || n.Group() != 9 {
+		t.Errorf("want group 9, got %v", n)
+	}
+}
@@ -0,0 +1,393 @@
+package focal
+
+import (
+	"fmt"
+	"maps"
+	"math"
+)
+
+// Interp runs a program one statement at a time on a Terminal. Unlike the
+// code focal2go generates it can stop between any two statements, which is
+// what the debugger needs.
+type Interp struct {
+	Prog *Program
+
+	tty    *Terminal
+	names  map[string]bool // the variables of Prog
+	vars   map[string]float64
+	arrays map[string]map[float64]float64
+	pc     pc
+	frames []frame
+	start  bool // pc is the first statement of a line
+	done   bool
+
+	// branch, if set, learns the arm an IF takes: 0 for negative, 1 for
+	// zero, 2 for positive.
+	branch func(s *If, arm int)
+}
+
+// pc points at statement i of ss, a line or a FOR body.
+type pc struct {
+	line *Line
+	ss   []Stmt
+	i    int
+}
+
+// frame is a running DO or FOR.
+type frame struct {
+	ret pc // the statement after the DO or FOR
+
+	// DO
+	call   Num // line of the DO
+	target Num
+
+	// FOR
+	loop      *For
+	step, end float64
+}
+
+// Frame is a DO that has not returned yet.
+type Frame struct {
+	Line   Num // the line doing it
+	Target Num // the group or line done
+}
+
+// maxDepth limits nested DOs, catching runaway recursion.
+const maxDepth = 1000
+
+// NewInterp returns an interpreter about to run the first line of p.
+func NewInterp(p *Program, tty *Terminal) *Interp {
+	in := &Interp{Prog: p, tty: tty, names: map[string]bool{}}
+	Inspect(p, func(n Node) bool {
+		if v, ok := n.(*Var); ok && v.Index == nil {
+			in.names[v.Name] = true
+		}
+		return true
+	})
+	in.erase()
+	if len(p.Lines) == 0 {
+		in.done = true
+	} else {
+		in.jump(p.Lines[0])
+	}
+	return in
+}
+
+func (in *Interp) erase() {
+	in.vars = map[string]float64{}
+	in.arrays = map[string]map[float64]float64{}
+}
+
+// Done reports whether the program has ended.
+func (in *Interp) Done() bool {
+	return in.done
+}
+
+// Pos returns the line and statement that run next.
+func (in *Interp) Pos() (Num, Stmt, bool) {
+	if in.done {
+		return 0, nil, false
+	}
+	return in.pc.line.Num, in.pc.ss[in.pc.i], true
+}
+
+// AtLineStart reports whether the next statement is the first of its line,
+// reached by falling through or a jump.
+func (in *Interp) AtLineStart() bool {
+	return !in.done && in.start
+}
+
+// Stack returns the DOs that have not returned, outermost first.
+func (in *Interp) Stack() []Frame {
+	var cs []Frame
+	for _, f := range in.frames {
+		if f.loop == nil {
+			cs = append(cs, Frame{f.call, f.target})
+		}
+	}
+	return cs
+}
+
+// Var returns the value of a variable; unset variables are 0.
+func (in *Interp) Var(name string) float64 {
+	return in.vars[name]
+}
+
+// Vars returns a copy of the variables that have been set.
+func (in *Interp) Vars() map[string]float64 {
+	return maps.Clone(in.vars)
+}
+
+// Eval returns the value of x.
+func (in *Interp) Eval(x Expr) float64 {
+	return eval(x, in.value, in.tty.Random)
+}
+
+// value returns the value of a variable or array element.
+func (in *Interp) value(v *Var) float64 {
+	if v.Index == nil {
+		return in.vars[v.Name]
+	}
+	return in.arrays[v.Name][in.Eval(v.Index)]
+}
+
+// eval returns the value of x, reading variables with value and FRAN from
+// random.
+func eval(x Expr, value func(*Var) float64, random func() float64) float64 {
+	switch x := x.(type) {
+	case *Number:
+		return x.Value
+	case *Var:
+		return value(x)
+	case *Unary:
+		if x.Op == '-' {
+			return -eval(x.X, value, random)
+		}
+		return eval(x.X, value, random)
+	case *Binary:
+		a, b := eval(x.X, value, random), eval(x.Y, value, random)
+		switch x.Op {
+		case '+':
+			return a + b
+		case '-':
+			return a - b
+		case '*':
+			return a * b
+		case '/':
+			return a / b
+		case '^':
+			return math.Pow(a, b)
+		}
+	case *Call:
+		if x.Name == "FRAN" {
+			return random()
+		}
+		return Functions[x.Name](eval(x.Arg, value, random))
+	}
+	panic(fmt.Sprintf("focal: unexpected expression %T", x))
+}
+
+// answer reads an ASK answer, which may use the program's variables and
+// arrays.
+func (in *Interp) answer() float64 {
+	x, err := ParseAnswer(in.tty.line(), func(name string) bool { return in.names[name] })
+	if err != nil {
+		return 0
+	}
+	return in.Eval(x)
+}
+
+func (in *Interp) assign(v *Var, x float64) {
+	if v.Index == nil {
+		in.vars[v.Name] = x
+		return
+	}
+	a := in.arrays[v.Name]
+	if a == nil {
+		a = map[float64]float64{}
+		in.arrays[v.Name] = a
+	}
+	a[in.Eval(v.Index)] = x
+}
+
+// Run runs the program to its end. Like Terminal.Run it returns io.EOF if
+// the input ran out.
+func (in *Interp) Run() error {
+	for !in.done {
+		if err := in.Step(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Step runs the next statement. Errors end the program; Step does nothing
+// once it has ended.
+func (in *Interp) Step() error {
+	if in.done {
+		return nil
+	}
+	pc := in.pc
+	in.pc.i++
+	in.start = false
+	err := in.tty.Run(func() { in.exec(pc) })
+	if err != nil {
+		in.done = true
+		return err
+	}
+	in.settle()
+	return nil
+}
+
+// fail stops the program with an error at line l, without number for a
+// line typed in direct mode.
+func fail(l *Line, format string, args ...any) {
+	if l.Num == 0 {
+		panic(stop{fmt.Errorf(format, args...)})
+	}
+	panic(stop{fmt.Errorf("%s: "+format, append([]any{l.Num}, args...)...)})
+}
+
+func (in *Interp) exec(pc pc) {
+	l := pc.line
+	switch s := pc.ss[pc.i].(type) {
+	case *Type:
+		for _, it := range s.Items {
+			in.item(it)
+		}
+	case *Ask:
+		for _, it := range s.Items {
+			if v, ok := it.(*Var); ok {
+				in.assign(v, in.answer())
+				continue
+			}
+			in.item(it)
+		}
+	case *Set:
+		in.assign(s.Var, in.Eval(s.X))
+	case *Goto:
+		in.goTo(l, "GOTO", s.Target)
+	case *Do:
+		r := in.Prog.Resolve(s.Target)
+		if r == nil {
+			fail(l, "DO %s: no such line", target(s.Target))
+		}
+		if len(in.Stack()) == maxDepth {
+			fail(l, "DO %s: more than %d nested DOs", target(s.Target), maxDepth)
+		}
+		in.frames = append(in.frames, frame{ret: in.pc, call: l.Num, target: s.Target})
+		in.jump(r)
+	case *If:
+		x := in.Eval(s.Cond)
+		arm := 2
+		if x < 0 {
+			arm = 0
+		} else if x == 0 {
+			arm = 1
+		}
+		if in.branch != nil {
+			in.branch(s, arm)
+		}
+		if arm < len(s.Targets) && s.Targets[arm] != 0 {
+			in.goTo(l, "IF", s.Targets[arm])
+		}
+	case *For:
+		step := 1.0
+		if s.Step != nil {
+			step = in.Eval(s.Step)
+		}
+		start := in.Eval(s.Start)
+		end := in.Eval(s.End)
+		in.assign(s.Var, start)
+		if inRange(start, step, end) {
+			in.frames = append(in.frames, frame{ret: in.pc, loop: s, step: step, end: end})
+			in.pc = pcOf(l, s.Body)
+		}
+	case *Erase:
+		in.erase()
+	case *Quit:
+		in.done = true
+	case *Return:
+		in.ret()
+	case *Comment:
+		in.pc.i = len(pc.ss)
+	}
+}
+
+func (in *Interp) item(it Item) {
+	switch it := it.(type) {
+	case Text:
+		in.tty.Text(string(it))
+	case Newline:
+		in.tty.Newline()
+	case CR:
+		in.tty.CR()
+	case Format:
+		if it.E {
+			in.tty.Format(0, 0)
+		} else {
+			in.tty.Format(it.Width, it.Digits)
+		}
+	case Expr:
+		in.tty.Number(in.Eval(it))
+	}
+}
+
+func inRange(x, step, end float64) bool {
+	if step < 0 {
+		return x >= end
+	}
+	return x <= end
+}
+
+func pcOf(l *Line, ss []Stmt) pc {
+	return pc{line: l, ss: ss}
+}
+
+// jump continues with the first statement of l.
+func (in *Interp) jump(l *Line) {
+	in.pc = pcOf(l, l.Stmts)
+	in.start = true
+}
+
+// goTo jumps to line n, leaving the FOR loops of the current DO.
+func (in *Interp) goTo(l *Line, cmd string, n Num) {
+	r := in.Prog.Resolve(n)
+	if r == nil {
+		fail(l, "%s %s: no such line", cmd, target(n))
+	}
+	for len(in.frames) > 0 && in.frames[len(in.frames)-1].loop != nil {
+		in.frames = in.frames[:len(in.frames)-1]
+	}
+	in.jump(r)
+}
+
+// ret returns from the innermost DO; outside of one the program ends.
+func (in *Interp) ret() {
+	for len(in.frames) > 0 {
+		f := in.frames[len(in.frames)-1]
+		in.frames = in.frames[:len(in.frames)-1]
+		if f.loop == nil {
+			in.pc = f.ret
+			return
+		}
+	}
+	in.done = true
+}
+
+// settle moves pc past finished statement lists: it runs the next round
+// of a FOR, returns from a DO at the end of its line or group, or falls
+// through to the next line.
+func (in *Interp) settle() {
+	for !in.done && in.pc.i >= len(in.pc.ss) {
+		n := len(in.frames)
+		if n > 0 && in.frames[n-1].loop != nil {
+			f := &in.frames[n-1]
+			x := in.Eval(f.loop.Var) + f.step
+			in.assign(f.loop.Var, x)
+			if inRange(x, f.step, f.end) {
+				in.pc.i = 0
+				continue
+			}
+			in.pc = f.ret
+			in.frames = in.frames[:n-1]
+			continue
+		}
+		l := in.pc.line
+		if l.Num == 0 {
+			// a line typed in direct mode, see Session
+			in.done = true
+			return
+		}
+		next := in.Prog.Next(l.Num)
+		if n > 0 && (!in.frames[n-1].target.IsGroup() || next == nil || next.Num.Group() != l.Num.Group()) {
+			in.ret()
+			continue
+		}
+		if next == nil {
+			in.done = true
+			return
+		}
+		in.jump(next)
+	}
+}
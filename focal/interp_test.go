@@ -0,0 +1,139 @@
+package focal
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestInterpTranscripts(t *testing.T) {
+	p := lander(t)
+	for _, name := range []string{"perfect", "good", "crash"} {
+		t.Run(name, func(t *testing.T) {
+			dir := filepath.Join("..", "lunar", "testdata")
+			in, err := os.ReadFile(filepath.Join(dir, name+".in"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			want, err := os.ReadFile(filepath.Join(dir, name+".out"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var out bytes.Buffer
+			err = NewInterp(p, NewTerminal(bytes.NewReader(in), &out)).Run()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := out.Bytes(); !bytes.Equal(want, got) {
+				t.Errorf("want\n%s\ngot\n%s", want, got)
+			}
+		})
+	}
+}
+
+func run(t *testing.T, src, input string) (string, error) {
+	t.Helper()
+	var out strings.Builder
+	err := NewInterp(program(t, src), NewTerminal(strings.NewReader(input), &out)).Run()
+	return out.String(), err
+}
+
+func TestInterp(t *testing.T) {
+	tests := []struct {
+		name, src, in, want string
+	}{
+		{"for", `01.10 F I=1,3;T %1,I`, "", "  1  2  3"},
+		{"for step", `01.10 F I=3,-1,1;T %1,I;T "-"`, "", "  3-  2-  1-"},
+		{"for empty", `01.10 F I=2,1;T "X"
+01.20 T "Y"`, "", "Y"},
+		{"nested for", `01.10 F I=1,2;F J=1,2;T %1,I*J`, "", "  1  2  2  4"},
+		{"do group", `01.10 D 2;T "C"
+01.20 Q
+02.10 T "A"
+02.20 T "B"
+03.10 T "NOT"`, "", "ABC"},
+		{"do line", `01.10 D 2.2;T "C";Q
+02.10 T "A"
+02.20 T "B"
+02.30 T "NOT"`, "", "BC"},
+		{"return", `01.10 D 2;T "C";Q
+02.10 T "A";R;T "NOT"
+02.20 T "NOT"`, "", "AC"},
+		{"do in for", `01.10 F I=1,3;D 2
+01.20 Q
+02.10 T %1,I`, "", "  1  2  3"},
+		{"goto leaves for", `01.10 F I=1,5;I (2-I) 1.2
+01.20 T %1,I`, "", "  3"},
+		{"if", `01.10 I (-1) 1.3,1.4;T "NOT"
+01.20 Q
+01.30 I (0) 1.3,1.5
+01.40 T "NOT"
+01.50 I (1) 1.3,1.3;T "OK"`, "", "OK"},
+		{"ask", `01.10 A "X",X,Y;T %2,X+Y`, "3\n-4\n", "X::  -1"},
+		// NO is a variable of this program, so the answer NO is too.
+		{"ask expression", `01.10 S A(1)=5;A K,K,NO,P;T %4,K,NO,P`, "A(1)*2\nK+1\nNO\nYES\n", "::::    11     0  2569"},
+		{"array", `01.10 S A(1)=2;S A(2)=3;S A=4;T %1,A(1)+A(2),A`, "", "  5  4"},
+		{"erase", `01.10 S A=1;E;T %1,A`, "", "  0"},
+		{"comment", `01.10 C T "NOT"
+01.20 T "OK"`, "", "OK"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := run(t, tt.src, tt.in)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("want %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestInterpErrors(t *testing.T) {
+	tests := []struct{ src, want string }{
+		{"01.10 G 2.1", "01.10: GOTO 2.1: no such line"},
+		{"01.10 D 3", "01.10: DO 3: no such line"},
+		{"01.10 I (1) 1.1,1.1,4.1", "01.10: IF 4.1: no such line"},
+		{"01.10 D 1", "01.10: DO 1: more than 1000 nested DOs"},
+	}
+	for _, tt := range tests {
+		if _, err := run(t, tt.src, ""); err == nil || err.Error() != tt.want {
+			t.Errorf("%s: want %q, got %v", tt.src, tt.want, err)
+		}
+	}
+	if _, err := run(t, "01.10 A X", ""); !errors.Is(err, io.EOF) {
+		t.Errorf("want EOF, got %v", err)
+	}
+}
+
+func TestInterpStep(t *testing.T) {
+	in := NewInterp(program(t, `01.10 S A=1;D 2
+01.20 Q
+02.10 S B=A+1`), NewTerminal(strings.NewReader(""), io.Discard))
+	var trace []string
+	for !in.Done() {
+		n, s, _ := in.Pos()
+		trace = append(trace, n.String()+" "+s.String())
+		if n == 210 {
+			if want := []Frame{{110, 200}}; !slices.Equal(in.Stack(), want) {
+				t.Errorf("want stack %v, got %v", want, in.Stack())
+			}
+		}
+		if err := in.Step(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want := []string{"01.10 S A=1", "01.10 D 2", "02.10 S B=A+1", "01.20 Q"}
+	if !slices.Equal(trace, want) {
+		t.Errorf("want %q, got %q", want, trace)
+	}
+	if in.Var("B") != 2 || len(in.Stack()) != 0 {
+		t.Errorf("want B=2 and no DO, got %v %v", in.Vars(), in.Stack())
+	}
+}
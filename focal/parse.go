@@ -0,0 +1,589 @@
+package focal
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// SyntaxError reports where a line fails to parse.
+type SyntaxError struct {
+	Col int // 1-based byte position within the statements
+	Msg string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("col %d: %s", e.Col, e.Msg)
+}
+
+// Parse reads a program, one numbered line per text line. Blank lines are
+// skipped. A repeated line number replaces the earlier line, just like
+// typing it again at the FOCAL prompt.
+func Parse(r io.Reader) (*Program, error) {
+	var p Program
+	sc := bufio.NewScanner(r)
+	for n := 1; sc.Scan(); n++ {
+		s := strings.TrimSpace(sc.Text())
+		if s == "" {
+			continue
+		}
+		l, err := ParseLine(s)
+		if err != nil {
+			return nil, fmt.Errorf("%d: %w", n, err)
+		}
+		p.Insert(l)
+	}
+	return &p, sc.Err()
+}
+
+// ParseLine parses a numbered line such as 02.73 T "K=";A K;G 2.7.
+func ParseLine(s string) (*Line, error) {
+	s = strings.TrimSpace(s)
+	i := 0
+	for i < len(s) && (isDigit(s[i]) || s[i] == '.') {
+		i++
+	}
+	n, err := ParseNum(s[:i])
+	if err != nil {
+		return nil, err
+	}
+	if n.IsGroup() {
+		return nil, fmt.Errorf("line number %q: line part must not be 00", s[:i])
+	}
+	src := strings.TrimSpace(s[i:])
+	ss, err := ParseStmts(src)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", n, err)
+	}
+	return &Line{Num: n, Stmts: ss, Source: src}, nil
+}
+
+// ParseStmts parses statements separated by semicolons: the text of a line
+// after its number.
+func ParseStmts(s string) ([]Stmt, error) {
+	return parse(s, (*parser).stmts)
+}
+
+// ParseExpr parses a single expression.
+func ParseExpr(s string) (Expr, error) {
+	return parse(s, func(p *parser) Expr {
+		x := p.expr()
+		p.space()
+		if p.pos < len(p.s) {
+			p.fail("unexpected %q after expression", p.s[p.pos])
+		}
+		return x
+	})
+}
+
+// parser is a recursive descent parser over one line. Errors unwind the
+// descent as a *SyntaxError panic that parse recovers.
+type parser struct {
+	s   string
+	pos int
+
+	// isVar, set while parsing an ASK answer, tells the names of the
+	// program's variables from numbers spelled in letters.
+	isVar func(name string) bool
+}
+
+// ParseAnswer parses an ASK answer. FOCAL-69 evaluates answers like
+// expressions, so 12, K*2 and FSQT(K) are all answers. A word that is not a
+// function and not one of the program's variables, isVar tells which, reads
+// as a number with letter digits: YES is the constant 0YES. Text after the
+// expression is ignored.
+func ParseAnswer(s string, isVar func(name string) bool) (Expr, error) {
+	return parse(s, func(p *parser) Expr {
+		p.isVar = isVar
+		return p.expr()
+	})
+}
+
+func parse[T any](s string, f func(*parser) T) (t T, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			e, ok := r.(*SyntaxError)
+			if !ok {
+				panic(r)
+			}
+			err = e
+		}
+	}()
+	return f(&parser{s: s}), nil
+}
+
+func (p *parser) fail(format string, args ...any) {
+	panic(&SyntaxError{Col: p.pos + 1, Msg: fmt.Sprintf(format, args...)})
+}
+
+// peek returns the next byte, 0 at the end of the line.
+func (p *parser) peek() byte {
+	if p.pos == len(p.s) {
+		return 0
+	}
+	return p.s[p.pos]
+}
+
+func (p *parser) space() {
+	for p.peek() == ' ' || p.peek() == '\t' {
+		p.pos++
+	}
+}
+
+// end reports whether the current statement is complete.
+func (p *parser) end() bool {
+	return p.pos == len(p.s) || p.peek() == ';'
+}
+
+func (p *parser) expect(c byte) {
+	p.space()
+	if p.peek() != c {
+		p.fail("want %q", c)
+	}
+	p.pos++
+}
+
+func (p *parser) stmts() []Stmt {
+	var ss []Stmt
+	for {
+		p.space()
+		switch {
+		case p.pos == len(p.s):
+			return ss
+		case p.peek() == ';':
+			p.pos++
+			continue
+		}
+		s := p.stmt()
+		ss = append(ss, s)
+		switch s.(type) {
+		case *For, *Comment:
+			// took the rest of the line
+			return ss
+		}
+		p.space()
+		if !p.end() {
+			p.fail("unexpected %q", p.peek())
+		}
+	}
+}
+
+func (p *parser) stmt() Stmt {
+	start := p.pos
+	for isLetter(p.peek()) {
+		p.pos++
+	}
+	if p.pos == start {
+		p.fail("want command")
+	}
+	switch upper(p.s[start]) {
+	case 'T':
+		return &Type{Items: p.items(false)}
+	case 'A':
+		return &Ask{Items: p.items(true)}
+	case 'S':
+		v := p.variable()
+		p.expect('=')
+		return &Set{Var: v, X: p.expr()}
+	case 'G':
+		return &Goto{Target: p.target()}
+	case 'D':
+		t := p.target()
+		if t == 0 {
+			p.fail("DO needs a line or group")
+		}
+		return &Do{Target: t}
+	case 'I':
+		return p.ifStmt()
+	case 'F':
+		return p.forStmt()
+	case 'E':
+		return &Erase{}
+	case 'Q':
+		return &Quit{}
+	case 'R':
+		return &Return{}
+	case 'C':
+		s := &Comment{Text: p.s[start+1:]}
+		p.pos = len(p.s)
+		return s
+	}
+	p.pos = start
+	p.fail("unknown command %q", p.s[start:start+1])
+	return nil
+}
+
+// items parses the list of a TYPE, or of an ASK, which takes variables
+// instead of expressions.
+func (p *parser) items(ask bool) []Item {
+	var is []Item
+	for {
+		p.space()
+		if p.end() {
+			return is
+		}
+		switch p.peek() {
+		case ',':
+			p.pos++
+		case '"':
+			p.pos++
+			i := strings.IndexByte(p.s[p.pos:], '"')
+			if i < 0 {
+				p.fail("unterminated string")
+			}
+			is = append(is, Text(p.s[p.pos:p.pos+i]))
+			p.pos += i + 1
+		case '!':
+			p.pos++
+			is = append(is, Newline{})
+		case '#':
+			p.pos++
+			is = append(is, CR{})
+		case '%':
+			p.pos++
+			is = append(is, p.format())
+		default:
+			if ask {
+				is = append(is, p.variable())
+			} else {
+				is = append(is, p.expr())
+			}
+		}
+	}
+}
+
+// format parses what follows %. The digits after the point form a two
+// digit field like a line number, %6.02 has two decimals.
+func (p *parser) format() Format {
+	w := p.digits()
+	if w == "" {
+		return Format{E: true}
+	}
+	f := Format{}
+	f.Width, _ = strconv.Atoi(w)
+	if p.peek() == '.' {
+		p.pos++
+		d := p.digits()
+		if len(d) > 2 {
+			p.fail("format %%%s.%s: more than two decimal digits", w, d)
+		}
+		if d != "" {
+			f.Digits, _ = strconv.Atoi(d)
+			if len(d) == 1 {
+				f.Digits *= 10
+			}
+		}
+	}
+	return f
+}
+
+func (p *parser) digits() string {
+	start := p.pos
+	for isDigit(p.peek()) {
+		p.pos++
+	}
+	return p.s[start:p.pos]
+}
+
+// target parses an optional line or group number.
+func (p *parser) target() Num {
+	p.space()
+	if p.end() {
+		return 0
+	}
+	return p.lineNum()
+}
+
+func (p *parser) lineNum() Num {
+	start := p.pos
+	for isDigit(p.peek()) || p.peek() == '.' {
+		p.pos++
+	}
+	if p.pos == start {
+		p.fail("want line number")
+	}
+	n, err := ParseNum(p.s[start:p.pos])
+	if err != nil {
+		p.pos = start
+		p.fail("%v", err)
+	}
+	return n
+}
+
+func (p *parser) ifStmt() Stmt {
+	p.space()
+	if _, ok := closing(p.peek()); !ok {
+		p.fail("want ( after I")
+	}
+	s := &If{Cond: p.expr()}
+	for len(s.Targets) < 3 {
+		p.space()
+		var t Num
+		if isDigit(p.peek()) || p.peek() == '.' {
+			t = p.lineNum()
+		}
+		s.Targets = append(s.Targets, t)
+		p.space()
+		if p.peek() != ',' {
+			break
+		}
+		p.pos++
+	}
+	for len(s.Targets) > 0 && s.Targets[len(s.Targets)-1] == 0 {
+		s.Targets = s.Targets[:len(s.Targets)-1]
+	}
+	return s
+}
+
+func (p *parser) forStmt() Stmt {
+	s := &For{Var: p.variable()}
+	p.expect('=')
+	s.Start = p.expr()
+	p.expect(',')
+	s.End = p.expr()
+	p.space()
+	if p.peek() == ',' {
+		p.pos++
+		s.Step, s.End = s.End, p.expr()
+	}
+	p.space()
+	if !p.end() {
+		p.fail("unexpected %q", p.peek())
+	}
+	s.Body = p.stmts()
+	return s
+}
+
+func (p *parser) expr() Expr {
+	x := p.term()
+	for {
+		p.space()
+		op := p.peek()
+		if op != '+' && op != '-' {
+			return x
+		}
+		p.pos++
+		x = &Binary{Op: op, X: x, Y: p.term()}
+	}
+}
+
+func (p *parser) term() Expr {
+	x := p.unary()
+	for {
+		p.space()
+		op := p.peek()
+		if op != '*' && op != '/' {
+			return x
+		}
+		p.pos++
+		x = &Binary{Op: op, X: x, Y: p.unary()}
+	}
+}
+
+func (p *parser) unary() Expr {
+	p.space()
+	if op := p.peek(); op == '-' || op == '+' {
+		p.pos++
+		return &Unary{Op: op, X: p.unary()}
+	}
+	x := p.primary()
+	p.space()
+	if p.peek() == '^' {
+		p.pos++
+		return &Binary{Op: '^', X: x, Y: p.unary()}
+	}
+	return x
+}
+
+func (p *parser) primary() Expr {
+	p.space()
+	c := p.peek()
+	if end, ok := closing(c); ok {
+		p.pos++
+		x := p.expr()
+		p.expect(end)
+		return x
+	}
+	switch {
+	case isLetter(c) && p.isVar != nil:
+		return p.word()
+	case isDigit(c) || c == '.':
+		v, n := ReadNumber(p.s[p.pos:])
+		if n == 0 {
+			p.fail("want number")
+		}
+		x := &Number{Value: v, Text: p.s[p.pos : p.pos+n]}
+		p.pos += n
+		return x
+	case upper(c) == 'F':
+		return p.call()
+	case isLetter(c):
+		return p.variable()
+	}
+	p.fail("want expression")
+	return nil
+}
+
+// word reads a name in an answer: a function call, a variable or a number
+// spelled in letters.
+func (p *parser) word() Expr {
+	start := p.pos
+	for isLetter(p.peek()) || isDigit(p.peek()) {
+		p.pos++
+	}
+	name := strings.ToUpper(p.s[start:p.pos])
+	array := p.peek() == '('
+	p.pos = start
+	if name[0] == 'F' {
+		if _, ok := Functions[name[:min(len(name), 4)]]; ok || strings.HasPrefix(name, "FRAN") {
+			return p.call()
+		}
+	} else if array || p.isVar(name[:min(len(name), 2)]) {
+		return p.variable()
+	}
+	v, n := ReadNumber(p.s[start:])
+	p.pos += n
+	return &Number{Value: v, Text: p.s[start:p.pos]}
+}
+
+func (p *parser) call() Expr {
+	start := p.pos
+	for isLetter(p.peek()) || isDigit(p.peek()) {
+		p.pos++
+	}
+	name := strings.ToUpper(p.s[start:min(p.pos, start+4)])
+	if _, ok := Functions[name]; !ok && name != "FRAN" {
+		p.pos = start
+		p.fail("unknown function %s", name)
+	}
+	p.space()
+	end, ok := closing(p.peek())
+	if !ok {
+		p.fail("want ( after %s", name)
+	}
+	p.pos++
+	x := &Call{Name: name}
+	if p.space(); p.peek() != end {
+		x.Arg = p.expr()
+	}
+	p.expect(end)
+	if (x.Arg == nil) != (name == "FRAN") {
+		p.pos = start
+		p.fail("%s takes %s argument", name, map[bool]string{true: "no", false: "one"}[name == "FRAN"])
+	}
+	return x
+}
+
+func (p *parser) variable() *Var {
+	p.space()
+	start := p.pos
+	if !isLetter(p.peek()) || upper(p.peek()) == 'F' {
+		p.fail("want variable")
+	}
+	for isLetter(p.peek()) || isDigit(p.peek()) {
+		p.pos++
+	}
+	v := &Var{Name: strings.ToUpper(p.s[start:min(p.pos, start+2)])}
+	if p.peek() == '(' {
+		p.pos++
+		v.Index = p.expr()
+		p.expect(')')
+	}
+	return v
+}
+
+// closing returns the bracket that closes c; FOCAL accepts (), [] and <>.
+func closing(c byte) (byte, bool) {
+	switch c {
+	case '(':
+		return ')', true
+	case '[':
+		return ']', true
+	case '<':
+		return '>', true
+	}
+	return 0, false
+}
+
+// ReadNumber reads the number at the start of s the way FOCAL-69 reads
+// numbers in programs and in ASK answers, and returns its value and length.
+// Letters count as digits, A=1 to Z=26, so that a program can compare an
+// answer of YES with the constant 0YES. E followed by a digit or sign starts
+// an exponent unless letters came before.
+func ReadNumber(s string) (float64, int) {
+	i, dot, alpha, digits := 0, false, false, false
+scan:
+	for i < len(s) {
+		c := s[i]
+		switch {
+		case isDigit(c):
+			digits = true
+		case c == '.' && !dot:
+			dot = true
+		case upper(c) == 'E' && digits && !alpha && exponent(s[i+1:]):
+			i++
+			if s[i] == '+' || s[i] == '-' {
+				i++
+			}
+			for i < len(s) && isDigit(s[i]) {
+				i++
+			}
+			break scan
+		case isLetter(c):
+			alpha = true
+		default:
+			break scan
+		}
+		i++
+	}
+	if !digits && !alpha {
+		return 0, 0
+	}
+	if !alpha {
+		v, _ := strconv.ParseFloat(s[:i], 64)
+		return v, i
+	}
+	var v float64
+	scale := 0.0
+	for _, c := range []byte(s[:i]) {
+		d := float64(c - '0')
+		if isLetter(c) {
+			d = float64(upper(c) - 'A' + 1)
+		}
+		switch {
+		case c == '.':
+			scale = .1
+		case scale == 0:
+			v = v*10 + d
+		default:
+			v += d * scale
+			scale /= 10
+		}
+	}
+	return v, i
+}
+
+// exponent reports whether s, following an E, holds an exponent.
+func exponent(s string) bool {
+	if s != "" && (s[0] == '+' || s[0] == '-') {
+		s = s[1:]
+	}
+	return s != "" && isDigit(s[0])
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isLetter(c byte) bool {
+	return c >= 'A' && c <= 'Z' || c >= 'a' && c <= 'z'
+}
+
+func upper(c byte) byte {
+	if c >= 'a' && c <= 'z' {
+		return c - 'a' + 'A'
+	}
+	return c
+}
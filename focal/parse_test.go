@@ -0,0 +1,236 @@
+package focal
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+)
+
+func lander(t *testing.T) *Program {
+	t.Helper()
+	f, err := os.Open("../lunar-lander.fc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	p, err := Parse(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func TestParseLander(t *testing.T) {
+	p := lander(t)
+	if n := len(p.Lines); n != 39 {
+		t.Fatalf("want 39 lines, got %d", n)
+	}
+	// 05.94 jumps to 1.2, which is 01.20.
+	if l := p.Resolve(120); l == nil || !strings.HasPrefix(l.Source, `T "FIRST RADAR`) {
+		t.Errorf("want 1.2 to resolve to 01.20, got %v", l)
+	}
+	if g := p.Group(5); len(g) != 13 || g[0].Num != 510 || g[12].Num != 598 {
+		t.Errorf("want group 5 from 05.10 to 05.98, got %v", g)
+	}
+	if l := p.Next(598); l == nil || l.Num != 610 {
+		t.Errorf("want 06.10 after 05.98, got %v", l)
+	}
+	if l := p.Resolve(900); l == nil || l.Num != 910 {
+		t.Errorf("want group 9 to start at 09.10, got %v", l)
+	}
+	// The file ends in a blank line.
+	if got, want := p.String()+"\n", readFile(t, "../lunar-lander.fc"); got != want {
+		t.Errorf("listing differs from source:\n%s", got)
+	}
+}
+
+func readFile(t *testing.T, name string) string {
+	t.Helper()
+	b, err := os.ReadFile(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b)
+}
+
+// Printing a statement and parsing it again must give the same statement.
+func TestCanonicalRoundTrip(t *testing.T) {
+	for _, l := range lander(t).Lines {
+		for _, s := range l.Stmts {
+			ss, err := ParseStmts(s.String())
+			if err != nil {
+				t.Errorf("%s: %s: %v", l.Num, s, err)
+				continue
+			}
+			if len(ss) != 1 || ss[0].String() != s.String() {
+				t.Errorf("%s: want %s, got %v", l.Num, s, ss)
+			}
+		}
+	}
+}
+
+func TestParseExpr(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"M*G/(Z*K)", "M*G/(Z*K)"},
+		{"M*G/Z*K", "M*G/Z*K"},
+		{"A-(B-C)", "A-(B-C)"},
+		{"(A-B)-C", "A-B-C"},
+		{"-Q^2", "-Q^2"},
+		{"(-Q)^2", "(-Q)^2"},
+		{"-(A*B)", "-(A*B)"},
+		{"2^3^2", "2^3^2"},
+		{"(2^3)^2", "(2^3)^2"},
+		{"[A+B]*<C>", "(A+B)*C"},
+		{"fsqt(v*v)", "FSQT(V*V)"},
+		{"FRAN()", "FRAN()"},
+		{"ALPHA(I+1)", "AL(I+1)"},
+		{"0NO", "0NO"},
+	}
+	for _, tt := range tests {
+		x, err := ParseExpr(tt.in)
+		if err != nil {
+			t.Errorf("%s: %v", tt.in, err)
+			continue
+		}
+		if got := x.String(); got != tt.want {
+			t.Errorf("%s: want %s, got %s", tt.in, tt.want, got)
+		}
+	}
+}
+
+func TestParseAnswer(t *testing.T) {
+	isVar := func(name string) bool { return name == "K" || name == "NO" }
+	tests := []struct {
+		in, want string
+	}{
+		{"12", "12"},
+		{"-12.5", "-12.5"},
+		{"2*K+1", "2*K+1"},
+		{"fsqt(k)", "FSQT(K)"},
+		{"YES", "YES"},
+		{"NO", "NO"},
+		{"KILO", "KILO"},
+		{"NOPE", "NO"},
+		{"A(1)", "A(1)"},
+		{"FUEL", "FUEL"},
+		{"12 LBS", "12"},
+		{"K=3", "K"},
+	}
+	for _, tt := range tests {
+		x, err := ParseAnswer(tt.in, isVar)
+		if err != nil {
+			t.Errorf("%s: %v", tt.in, err)
+			continue
+		}
+		if got := x.String(); got != tt.want {
+			t.Errorf("%s: want %s, got %s", tt.in, tt.want, got)
+		}
+	}
+	if x, _ := ParseAnswer("YES", isVar); x.(*Number).Value != 2569 {
+		t.Errorf("want YES = 2569, got %v", x)
+	}
+	for _, in := range []string{"", "*2", "(1"} {
+		if _, err := ParseAnswer(in, isVar); err == nil {
+			t.Errorf("%q: want error", in)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, in := range []string{
+		`T "OPEN`,
+		"S A",
+		"S FX=1",
+		"I X)1.1",
+		"D",
+		"X 1",
+		"T FOO(1)",
+		"T FSQT()",
+		"T FRAN(1)",
+		"S A=(1",
+		"G 1.234",
+		"S A=1 B",
+		"T %6.123",
+	} {
+		_, err := ParseStmts(in)
+		var se *SyntaxError
+		if !errors.As(err, &se) {
+			t.Errorf("%q: want syntax error, got %v", in, err)
+		}
+	}
+	if _, err := ParseLine("01.00 T 1"); err == nil {
+		t.Error("want error for line 01.00")
+	}
+	if _, err := Parse(strings.NewReader("01.10 T 1\n01.20 T )\n")); err == nil || !strings.HasPrefix(err.Error(), "2: 01.20: col ") {
+		t.Errorf("want error in line 2, got %v", err)
+	}
+}
+
+func TestParseStmts(t *testing.T) {
+	ss, err := ParseStmts(`T "A"!;F X=10,-1,1;T X;I (X-5)2.1,,3.3`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ss) != 2 {
+		t.Fatalf("want TYPE and FOR, got %v", ss)
+	}
+	f := ss[1].(*For)
+	if f.Step.String() != "-1" || f.End.String() != "1" || len(f.Body) != 2 {
+		t.Errorf("want step -1 to 1 with two statements, got %s", f)
+	}
+	if i := f.Body[1].(*If); len(i.Targets) != 3 || i.Targets[1] != 0 || i.Targets[2] != 330 {
+		t.Errorf("want empty zero arm, got %s", i)
+	}
+	// Duplicate lines replace earlier ones.
+	p, err := Parse(strings.NewReader("2.1 T 2\n1.1 T 1\n2.1 T 3\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := p.String(); got != "01.10 T 1\n\n02.10 T 3\n" {
+		t.Errorf("got %q", got)
+	}
+}
+
+func TestReadNumber(t *testing.T) {
+	tests := []struct {
+		in   string
+		want float64
+		n    int
+	}{
+		{"120", 120, 3},
+		{".001)", .001, 4},
+		{"1.8;", 1.8, 3},
+		{"1E3", 1000, 3},
+		{"2.5E-2*A", .025, 6},
+		{"0NO", 155, 3},
+		{"0YES)", 2569, 4},
+		{"YES", 2569, 3},
+		{"no", 155, 2},
+		{"A", 1, 1},
+		{"12X", 144, 3},
+		{"-1", 0, 0},
+		{"", 0, 0},
+	}
+	for _, tt := range tests {
+		v, n := ReadNumber(tt.in)
+		if v != tt.want || n != tt.n {
+			t.Errorf("%q: want %g, %d, got %g, %d", tt.in, tt.want, tt.n, v, n)
+		}
+	}
+}
+
+func TestInspect(t *testing.T) {
+	vars := map[string]bool{}
+	Inspect(lander(t), func(n Node) bool {
+		if v, ok := n.(*Var); ok {
+			vars[v.Name] = true
+		}
+		return true
+	})
+	if len(vars) != 16 || !vars["X"] || !vars["P"] {
+		t.Errorf("want 16 variables including X and P, got %v", vars)
+	}
+}
@@ -0,0 +1,260 @@
+package focal
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// Session is FOCAL in direct mode, reading commands at its * prompt. A
+// line typed with a number goes into the program in order, replacing a line
+// with the same number. Four commands work on the program:
+//
+//	W          list the program, W 2 group 2, W 2.1 line 02.10
+//	E ALL      erase the program, E 2 a group, E 2.1 a line
+//	M 8.1      type line 08.10 and read its new text: /old/new/ replaces
+//	           the first old, an empty answer keeps the line
+//	L C file   load a program, L S file saves it, to the file loaded last
+//	           if no name is given
+//
+// Anything else runs at once as a line without number: GO runs the
+// program, G 2.1 from line 02.10, T A prints a variable and E erases them
+// all. Like in FOCAL variables survive from one command to the next.
+// Commands and the program's ASK answers are read from the same terminal.
+type Session struct {
+	Prog *Program
+	File string // where L S saves without a name
+
+	tty    *Terminal
+	vars   map[string]float64
+	arrays map[string]map[float64]float64
+}
+
+// header starts a listing of the whole program, as on the 1969 teletype.
+const header = "C-FOCAL,1969\n\n"
+
+// NewSession returns a session editing p on tty.
+func NewSession(p *Program, tty *Terminal) *Session {
+	s := &Session{Prog: p, tty: tty}
+	s.erase()
+	return s
+}
+
+func (s *Session) erase() {
+	s.vars = map[string]float64{}
+	s.arrays = map[string]map[float64]float64{}
+}
+
+// Run reads and runs commands until the input ends. It returns the first
+// error reading input or writing output; errors of commands are typed as
+// ? and the message.
+func (s *Session) Run() error {
+	for {
+		s.tty.write("*")
+		cmd, err := s.tty.readLine()
+		if err != nil {
+			s.tty.write("\n")
+			if errors.Is(err, io.EOF) {
+				err = nil
+			}
+			if err == nil {
+				err = s.tty.err
+			}
+			return err
+		}
+		if err := s.Exec(cmd); err != nil {
+			if errors.Is(err, io.EOF) {
+				// the program ran out of answers, so do the commands
+				s.tty.write("\n")
+				return s.tty.err
+			}
+			s.tty.write("? " + err.Error() + "\n")
+		}
+		if s.tty.err != nil {
+			return s.tty.err
+		}
+	}
+}
+
+// Exec runs one command.
+func (s *Session) Exec(cmd string) error {
+	cmd = strings.TrimSpace(cmd)
+	if cmd == "" {
+		return nil
+	}
+	if isDigit(cmd[0]) || cmd[0] == '.' {
+		l, err := ParseLine(cmd)
+		if err != nil {
+			return err
+		}
+		s.Prog.Insert(l)
+		return nil
+	}
+	word, arg := words(cmd)
+	switch upper(word[0]) {
+	case 'W':
+		return s.write(arg)
+	case 'E':
+		if arg != "" {
+			return s.delete(arg)
+		}
+	case 'M':
+		return s.modify(arg)
+	case 'L':
+		return s.library(arg)
+	}
+	return s.direct(cmd)
+}
+
+// words splits off the first word of a command.
+func words(cmd string) (string, string) {
+	i := 0
+	for i < len(cmd) && isLetter(cmd[i]) {
+		i++
+	}
+	if i == 0 {
+		return cmd, ""
+	}
+	return cmd[:i], strings.TrimSpace(cmd[i:])
+}
+
+// lines parses the argument of W and E: ALL or nothing for the whole
+// program, a group or a line.
+func (s *Session) lines(arg string) ([]*Line, Num, error) {
+	if arg == "" || upper(arg[0]) == 'A' {
+		return s.Prog.Lines, 0, nil
+	}
+	n, err := ParseNum(arg)
+	if err != nil {
+		return nil, 0, err
+	}
+	if n.IsGroup() {
+		g := s.Prog.Group(n.Group())
+		if len(g) == 0 {
+			return nil, 0, fmt.Errorf("no group %d", n.Group())
+		}
+		return g, n, nil
+	}
+	l := s.Prog.Line(n)
+	if l == nil {
+		return nil, 0, fmt.Errorf("no line %s", n)
+	}
+	return []*Line{l}, n, nil
+}
+
+func (s *Session) write(arg string) error {
+	ls, n, err := s.lines(arg)
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		s.tty.write(header + s.Prog.String())
+		return nil
+	}
+	s.tty.write((&Program{Lines: ls}).String())
+	return nil
+}
+
+func (s *Session) delete(arg string) error {
+	_, n, err := s.lines(arg)
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		s.Prog.Lines = nil
+		s.erase()
+		return nil
+	}
+	s.Prog.Delete(n)
+	return nil
+}
+
+func (s *Session) modify(arg string) error {
+	n, err := ParseNum(arg)
+	if err != nil {
+		return err
+	}
+	l := s.Prog.Line(n)
+	if l == nil {
+		return fmt.Errorf("no line %s", n)
+	}
+	s.tty.write(l.String() + "\n")
+	edit, err := s.tty.readLine()
+	if err != nil {
+		return err
+	}
+	edit = strings.TrimRight(edit, "\r\n")
+	src := l.Source
+	switch {
+	case strings.TrimSpace(edit) == "":
+		return nil
+	case edit[0] == '/':
+		old, repl, ok := strings.Cut(strings.TrimSuffix(edit[1:], "/"), "/")
+		if !ok || old == "" || !strings.Contains(src, old) {
+			return fmt.Errorf("%s: no %q to replace", n, old)
+		}
+		src = strings.Replace(src, old, repl, 1)
+	default:
+		src = edit
+	}
+	nl, err := ParseLine(n.String() + " " + src)
+	if err != nil {
+		return err
+	}
+	s.Prog.Insert(nl)
+	return nil
+}
+
+func (s *Session) library(arg string) error {
+	sub, name := words(arg)
+	if sub == "" {
+		return errors.New("want L C or L S")
+	}
+	if name == "" {
+		name = s.File
+	}
+	switch upper(sub[0]) {
+	case 'C':
+		f, err := os.Open(name)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		p, err := Parse(f)
+		if err != nil {
+			return fmt.Errorf("%s:%w", name, err)
+		}
+		s.Prog, s.File = p, name
+		s.erase()
+		return nil
+	case 'S':
+		if name == "" {
+			return errors.New("L S needs a file name")
+		}
+		if err := os.WriteFile(name, []byte(s.Prog.String()), 0o644); err != nil {
+			return err
+		}
+		s.File = name
+		return nil
+	}
+	return fmt.Errorf("unknown library command %q", sub)
+}
+
+// direct runs statements typed without a line number on the variables of
+// the session. GOTO and DO lead into the program.
+func (s *Session) direct(cmd string) error {
+	ss, err := ParseStmts(cmd)
+	if err != nil {
+		return err
+	}
+	in := NewInterp(s.Prog, s.tty)
+	in.vars, in.arrays = s.vars, s.arrays
+	in.pc = pcOf(&Line{Stmts: ss, Source: cmd}, ss)
+	in.start, in.done = false, len(ss) == 0
+	err = in.Run()
+	// E inside the run replaced the maps.
+	s.vars, s.arrays = in.vars, in.arrays
+	return err
+}
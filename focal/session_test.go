@@ -0,0 +1,123 @@
+package focal
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func session(t *testing.T, p *Program, input string) (*Session, string) {
+	t.Helper()
+	var out strings.Builder
+	s := NewSession(p, NewTerminal(strings.NewReader(input), &out))
+	if err := s.Run(); err != nil {
+		t.Fatal(err)
+	}
+	return s, out.String()
+}
+
+func TestSession(t *testing.T) {
+	tests := []struct {
+		name, in, want string
+	}{
+		{"sorted entry", "1.2 T \"B\"!\n01.10 T \"A\"!\nW\n",
+			"***C-FOCAL,1969\n\n01.10 T \"A\"!\n01.20 T \"B\"!\n*\n"},
+		{"replace", "1.1 T 1\n1.1 T 2\nW 1.1\n", "***01.10 T 2\n*\n"},
+		{"write group", "1.1 T 1\n2.1 T 2\n2.2 T 3\nW 2\n",
+			"****02.10 T 2\n02.20 T 3\n*\n"},
+		{"go", "1.1 A K\n1.2 T %3,K*2!\nGO\n21\n", "***:   42\n*\n"},
+		{"go line", "1.1 T \"A\"\n1.2 T \"B\"!\nG 1.2\n", "***B\n*\n"},
+		{"do", "1.1 T \"A\"\n1.2 T \"B\"!\nD 1.1;T \"C\"!\n", "***AC\n*\n"},
+		{"variables survive", "S A=3\nT %1,A!\n1.1 S A=A+1\nGO\nT %1,A!\nE\nT %1,A!\n",
+			"**  3\n***  4\n**  0\n*\n"},
+		{"erase line", "1.1 T 1\n1.2 T 2\nE 1.1\nW\n", "****C-FOCAL,1969\n\n01.20 T 2\n*\n"},
+		{"erase group", "1.1 T 1\n2.1 T 2\nE 1\nW\n", "****C-FOCAL,1969\n\n02.10 T 2\n*\n"},
+		{"erase all", "1.1 T 1\nS A=1\nE ALL\nW\nT %1,A!\n", "****C-FOCAL,1969\n\n*  0\n*\n"},
+		{"modify", "8.1 S W=(1-M*G/Z*K)/2\nM 8.1\n/Z*K/(Z*K)/\nW 8.1\n",
+			"**08.10 S W=(1-M*G/Z*K)/2\n*08.10 S W=(1-M*G/(Z*K))/2\n*\n"},
+		{"modify replace", "1.1 T 1\nM 1.1\nT 2\nW\n", "**01.10 T 1\n*C-FOCAL,1969\n\n01.10 T 2\n*\n"},
+		{"modify keep", "1.1 T 1\nM 1.1\n\nW 1.1\n", "**01.10 T 1\n*01.10 T 1\n*\n"},
+		{"errors", "1.1 X\nW 3\nE 1.5\nM 1.5\nM 1.1\nG 2.1\nL X\n1.1 T 1\nM 1.1\n/2/3/\n",
+			"*? 01.10: col 1: unknown command \"X\"\n*? no group 3\n*? no line 01.50\n*? no line 01.50\n" +
+				"*? no line 01.10\n*? GOTO 2.1: no such line\n*? unknown library command \"X\"\n" +
+				"**01.10 T 1\n? 01.10: no \"2\" to replace\n*\n"},
+		{"out of answers", "1.1 A K\nGO\n", "**:\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, got := session(t, &Program{}, tt.in)
+			if got != tt.want {
+				t.Errorf("want %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestSessionLibrary(t *testing.T) {
+	dir := t.TempDir()
+	saved := filepath.Join(dir, "lander.fc")
+	s, out := session(t, &Program{}, "L C ../lunar-lander.fc\nM 1.2\n/!!!;E/!!;E/\nL S "+saved+"\nE ALL\nL S\n")
+	if strings.Contains(out, "?") {
+		t.Fatalf("want no errors, got %q", out)
+	}
+	if s.File != saved || len(s.Prog.Lines) != 0 {
+		t.Errorf("want an empty program saved to %s, got %d lines in %s", saved, len(s.Prog.Lines), s.File)
+	}
+	// The second L S saved the empty program over the first.
+	b, err := os.ReadFile(saved)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(b) != 0 {
+		t.Errorf("want an empty file, got %q", b)
+	}
+
+	s, out = session(t, &Program{}, "L C ../lunar-lander.fc\nM 1.2\n/!!!;E/!!;E/\nL S "+saved+"\n")
+	if strings.Contains(out, "?") {
+		t.Fatalf("want no errors, got %q", out)
+	}
+	want, err := os.ReadFile("../lunar-lander.fc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err = os.ReadFile(saved)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// lunar-lander.fc ends in a blank line.
+	w := strings.Replace(strings.TrimSuffix(string(want), "\n"), `UP"!!!;E`, `UP"!!;E`, 1)
+	if string(b) != w {
+		t.Errorf("want\n%s\ngot\n%s", w, b)
+	}
+	if len(s.Prog.Lines) != 39 {
+		t.Errorf("want 39 lines, got %d", len(s.Prog.Lines))
+	}
+
+	_, out = session(t, &Program{}, "L S\nL C missing.fc\nL\n")
+	for _, e := range []string{"? L S needs a file name", "? open missing.fc", "? want L C or L S"} {
+		if !strings.Contains(out, e) {
+			t.Errorf("missing %q in %q", e, out)
+		}
+	}
+}
+
+// TestSessionLander flies the perfect landing from the prompt and checks
+// that the variables outlive the run.
+func TestSessionLander(t *testing.T) {
+	input, err := os.ReadFile("../lunar/testdata/perfect.in")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := os.ReadFile("../lunar/testdata/perfect.out")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, out := session(t, lander(t), "GO\n"+string(input)+"T %6.02,M-N!\n")
+	if !strings.HasPrefix(out, "*"+string(want)) {
+		t.Fatalf("want the transcript, got\n%s", out)
+	}
+	if rest := strings.TrimPrefix(out, "*"+string(want)); rest != "*  277.60\n*\n" {
+		t.Errorf("want the fuel left, got %q", rest)
+	}
+}
@@ -0,0 +1,155 @@
+package focal
+
+import (
+	"bufio"
+	"io"
+	"math/rand/v2"
+
+	"gitlab.com/jhinrichsen/lunar-lander/typefmt"
+)
+
+// Sprint formats x as TYPE prints it under f; see package typefmt.
+func (f Format) Sprint(x float64) string {
+	return typefmt.Format(f).Sprint(x)
+}
+
+// Terminal is the teletype a translated program runs on. It prints TYPE
+// lists under the sticky number format and reads ASK answers.
+type Terminal struct {
+	in  *bufio.Reader
+	out io.Writer
+	f   Format
+	rng func() float64
+	err error // first write error
+}
+
+// NewTerminal returns a terminal reading answers from in and printing to out.
+func NewTerminal(in io.Reader, out io.Writer) *Terminal {
+	return &Terminal{
+		in:  bufio.NewReader(in),
+		out: out,
+		f:   Format(typefmt.Default),
+		rng: NewRandom(),
+	}
+}
+
+func (t *Terminal) write(s string) {
+	if t.err == nil {
+		_, t.err = io.WriteString(t.out, s)
+	}
+}
+
+// Text prints a string literal.
+func (t *Terminal) Text(s string) {
+	t.write(s)
+}
+
+// Newline prints !.
+func (t *Terminal) Newline() {
+	t.write("\n")
+}
+
+// CR prints #, returning the carriage without advancing the paper.
+func (t *Terminal) CR() {
+	t.write("\r")
+}
+
+// Format sets the number format %width.digits; a zero width selects E
+// format like a bare %.
+func (t *Terminal) Format(width, digits int) {
+	t.f = Format{Width: width, Digits: digits, E: width == 0}
+}
+
+// Number prints x in the current format.
+func (t *Terminal) Number(x float64) {
+	t.write(t.f.Sprint(x))
+}
+
+// Ask prints the colon FOCAL prompts with, reads an answer and returns its
+// value for a program whose variables are vars, as Answer does. At the end
+// of input Ask stops the program, Run then returns io.EOF.
+func (t *Terminal) Ask(vars map[string]float64) float64 {
+	return Answer(t.line(), vars, t.Random)
+}
+
+// line prints the colon and reads the text of an answer.
+func (t *Terminal) line() string {
+	t.write(":")
+	s, err := t.readLine()
+	if err != nil {
+		panic(stop{err})
+	}
+	return s
+}
+
+// readLine reads a line of input. A last line without newline is fine,
+// io.EOF comes once nothing is left.
+func (t *Terminal) readLine() (string, error) {
+	s, err := t.in.ReadString('\n')
+	if err != nil && (err != io.EOF || s == "") {
+		return "", err
+	}
+	return s, nil
+}
+
+// Answer returns the value of the ASK answer s, see ParseAnswer, for a
+// program whose variables are the keys of vars; they may be 0. Arrays read
+// as 0. Blank or unreadable answers count as 0. FRAN draws from random, or
+// from math/rand if it is nil.
+func Answer(s string, vars map[string]float64, random func() float64) float64 {
+	x, err := ParseAnswer(s, func(name string) bool {
+		_, ok := vars[name]
+		return ok
+	})
+	if err != nil {
+		return 0
+	}
+	if random == nil {
+		random = rand.Float64
+	}
+	return eval(x, func(v *Var) float64 {
+		if v.Index != nil {
+			return 0
+		}
+		return vars[v.Name]
+	}, random)
+}
+
+// NewRandom returns the FRAN() sequence every terminal starts with, for
+// ports evaluating ASK answers without one.
+func NewRandom() func() float64 {
+	return rand.New(rand.NewPCG(1969, 8)).Float64
+}
+
+// Random returns the next FRAN() value in [0, 1). The sequence is the same
+// on every run.
+func (t *Terminal) Random() float64 {
+	return t.rng()
+}
+
+// stop unwinds a running program; err is nil for Q.
+type stop struct{ err error }
+
+// Quit stops the program (Q).
+func (t *Terminal) Quit() {
+	panic(stop{})
+}
+
+// Run runs a program until it ends, quits or runs out of input. It returns
+// io.EOF if the input ran out and otherwise the first output error.
+func (t *Terminal) Run(program func()) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			s, ok := r.(stop)
+			if !ok {
+				panic(r)
+			}
+			err = s.err
+		}
+		if err == nil {
+			err = t.err
+		}
+	}()
+	program()
+	return nil
+}
@@ -0,0 +1,103 @@
+package focal
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestAnswer(t *testing.T) {
+	vars := map[string]float64{"K": 100, "P": 0}
+	tests := []struct {
+		in   string
+		want float64
+	}{
+		{"150\n", 150},
+		{" -3 \n", -3},
+		{"K*2\n", 200},
+		{"FSQT(K)\n", 10},
+		{"P\n", 0},
+		{"YES\n", 2569},
+		{"no\n", 155},
+		{"1E2\n", 100},
+		{"\n", 0},
+		{"?\n", 0},
+	}
+	for _, tt := range tests {
+		if got := Answer(tt.in, vars, nil); got != tt.want {
+			t.Errorf("%q: want %g, got %g", tt.in, tt.want, got)
+		}
+	}
+	r := NewRandom()
+	if got, want := Answer("FRAN()", nil, NewRandom()), r(); got != want {
+		t.Errorf("FRAN: want %g, got %g", want, got)
+	}
+}
+
+// Fields of lunar-lander.fc as retrofocal prints them.
+func TestSprint(t *testing.T) {
+	tests := []struct {
+		f    Format
+		x    float64
+		want string
+	}{
+		{Format{Width: 3}, 0, "    0"},
+		{Format{Width: 3}, 120, "  120"},
+		{Format{Width: 4}, 5016, "  5016"},
+		{Format{Width: 6, Digits: 2}, 3600, "  3600.00"},
+		{Format{Width: 6, Digits: 2}, -21.2, "  -21.20"},
+		{Format{Width: 6, Digits: 1}, 16000, "  16000.0"},
+		{Format{Width: 7, Digits: 2}, 220.3, "   220.30"},
+		{Format{Width: 7, Digits: 2}, 0, "     0.00"},
+	}
+	for _, tt := range tests {
+		if got := tt.f.Sprint(tt.x); got != tt.want {
+			t.Errorf("%s of %g: want %q, got %q", tt.f, tt.x, tt.want, got)
+		}
+	}
+}
+
+func TestTerminal(t *testing.T) {
+	var out bytes.Buffer
+	tty := NewTerminal(strings.NewReader("12.5\n-3\nYES\n\nbogus\n"), &out)
+	var got []float64
+	err := tty.Run(func() {
+		tty.Text("K=")
+		for {
+			got = append(got, tty.Ask(nil))
+			tty.Format(3, 0)
+			tty.Number(got[len(got)-1])
+			tty.Newline()
+		}
+	})
+	if !errors.Is(err, io.EOF) {
+		t.Errorf("want EOF, got %v", err)
+	}
+	want := []float64{12.5, -3, 2569, 0, 35929}
+	if len(got) != len(want) {
+		t.Fatalf("want %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("answer %d: want %g, got %g", i, want[i], got[i])
+		}
+	}
+	if !strings.HasPrefix(out.String(), "K=:   12\n:   -3\n") {
+		t.Errorf("got %q", out.String())
+	}
+}
+
+func TestQuit(t *testing.T) {
+	var out bytes.Buffer
+	tty := NewTerminal(strings.NewReader(""), &out)
+	err := tty.Run(func() {
+		tty.Text("CONTROL OUT")
+		tty.Quit()
+		tty.Text("not reached")
+	})
+	if err != nil || out.String() != "CONTROL OUT" {
+		t.Errorf("got %v, %q", err, out.String())
+	}
+}
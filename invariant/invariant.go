@@ -0,0 +1,182 @@
+// Package invariant checks the physics of package lunar against laws any
+// flight of lunar-lander.fc obeys, whatever the pilot does: the capsule
+// never burns more fuel than it carries, time only moves forward, the
+// surface is reached once, coasting follows the closed-form kinematics of
+// constant gravity and the verdict matches the impact velocity.
+//
+// Schedule draws random legal burn rates, Check flies them and reports the
+// first law broken. The tests fly thousands of schedules on every preset.
+//
+// Two laws hold only as the 1969 program keeps them, because package
+// lunar reproduces it faithfully. The braking step of line 08.10 does not
+// check the fuel like 03.40 does, so it may burn a few pounds more than
+// the tanks hold; the next 03.10 then ends the burn and the landing
+// reports negative fuel, as FOCAL does. And the loop of 07.10 takes one
+// more step, of length 0, when the previous one ended exactly on the
+// surface. Check allows the overdraw on the last powered step and the
+// empty step on the surface, and nothing else.
+package invariant
+
+import (
+	"fmt"
+	"math"
+	"math/rand/v2"
+
+	"gitlab.com/jhinrichsen/lunar-lander/lunar"
+)
+
+// Law names an invariant.
+type Law string
+
+// The laws Check enforces.
+const (
+	DryMass   Law = "mass never below the dry mass N"
+	Fuel      Law = "fuel never increases"
+	Time      Law = "elapsed time strictly increases"
+	Touchdown Law = "altitude reaches zero exactly once"
+	FreeFall  Law = "coasting matches closed-form kinematics"
+	Verdict   Law = "verdict consistent with the impact velocity W"
+)
+
+// Violation is a broken law.
+type Violation struct {
+	Law Law
+	// Step counts the steps of line 06.10 from 1, 0 for the flight as a
+	// whole.
+	Step   int
+	Detail string
+}
+
+func (v *Violation) Error() string {
+	if v.Step == 0 {
+		return fmt.Sprintf("%s: %s", v.Law, v.Detail)
+	}
+	return fmt.Sprintf("%s: step %d: %s", v.Law, v.Step, v.Detail)
+}
+
+// maxIntervals ends a flight that does not come down.
+const maxIntervals = 10000
+
+// tol is the relative tolerance of floating point comparisons.
+const tol = 1e-9
+
+// near reports whether a and b agree within tol relative to scale.
+func near(a, b, scale float64) bool {
+	return math.Abs(a-b) <= tol*math.Max(1, scale)
+}
+
+// Schedule returns n random legal burn rates of sc. A third are 0, a
+// third the limits MinK or MaxK, the rest anything between.
+func Schedule(rng *rand.Rand, sc lunar.Scenario, n int) []float64 {
+	ks := make([]float64, n)
+	for i := range ks {
+		switch rng.IntN(6) {
+		case 0, 1:
+			ks[i] = 0
+		case 2:
+			ks[i] = sc.MinK
+		case 3:
+			ks[i] = sc.MaxK
+		default:
+			ks[i] = sc.MinK + rng.Float64()*(sc.MaxK-sc.MinK)
+		}
+	}
+	return ks
+}
+
+// check collects the first violation.
+type check struct {
+	err  *Violation
+	step int
+}
+
+func (c *check) fail(law Law, format string, args ...any) {
+	if c.err == nil {
+		c.err = &Violation{Law: law, Step: c.step, Detail: fmt.Sprintf(format, args...)}
+	}
+}
+
+// Check flies sc on ks, coasting once they run out, and returns the
+// first violation of a law, or nil. The laws are those of the vertical
+// flight over the plane of the FOCAL program with a sound engine; Check
+// rejects a scenario with terrain, drift or malfunctions.
+func Check(sc lunar.Scenario, ks []float64) error {
+	if sc.Site != nil || sc.Horizontal != nil || sc.Malfunctions != nil {
+		return fmt.Errorf("scenario %q: laws hold for the vertical flight over the plane with a sound engine only", sc.Name)
+	}
+	var c check
+	s := sc.State()
+	prev := s
+	s.Observe = func(now *lunar.State) {
+		c.step++
+		if prev.M < prev.N && now.K > 0 && now.S > 0 {
+			c.fail(DryMass, "burning K=%g with M=%g below N=%g", now.K, prev.M, prev.N)
+		}
+		if now.M > prev.M {
+			c.fail(Fuel, "M rose from %g to %g", prev.M, now.M)
+		}
+		// the empty step of 07.10 on the surface, give or take rounding
+		onSurface := math.Abs(now.S) <= tol && math.Abs(prev.A) <= tol
+		if !(now.L > prev.L) && !onSurface {
+			c.fail(Time, "L went from %g to %g", prev.L, now.L)
+		}
+		if prev.A <= 0 && !onSurface {
+			c.fail(Touchdown, "flying on from A=%g after reaching the surface", prev.A)
+		}
+		if now.K == 0 {
+			a := prev.A - prev.V*now.S - now.G*now.S*now.S/2
+			v := prev.V + now.G*now.S
+			if !near(now.A, a, prev.A) || !near(now.V, v, math.Abs(prev.V)) {
+				c.fail(FreeFall, "coasting %gs from A=%g V=%g gave A=%g V=%g, want A=%g V=%g",
+					now.S, prev.A, prev.V, now.A, now.V, a, v)
+			}
+		}
+		prev = *now
+	}
+	var ev lunar.Event
+	for i := 0; ev != lunar.Landed; i++ {
+		k := 0.0
+		if i < len(ks) {
+			k = ks[i]
+		}
+		if i == maxIntervals {
+			c.fail(Touchdown, "still flying at A=%g after %d intervals", s.A, i)
+		}
+		if c.err != nil {
+			return c.err
+		}
+		switch ev = s.Fly(k, sc.Interval); ev {
+		case lunar.Landed:
+			if math.Abs(s.A) > tol {
+				c.fail(Touchdown, "touched down at A=%g", s.A)
+			}
+		case lunar.FuelOut:
+			c.step = 0
+			// the empty capsule falls from A at V to the surface
+			a, v, l := s.A, s.V, s.L
+			s.FreeFall()
+			if want := math.Sqrt(v*v + 2*a*s.G); !near(s.V, want, want) || !near(s.W, 3600*want, 3600*want) {
+				c.fail(FreeFall, "falling from A=%g at V=%g hit at V=%g, want %g", a, v, s.V, want)
+			}
+			if !(s.L > l) {
+				c.fail(Time, "free fall from L=%g ended at L=%g", l, s.L)
+			}
+			ev = lunar.Landed
+		}
+	}
+	c.step = 0
+	if !near(s.W, 3600*s.V, 3600*math.Abs(s.V)) {
+		c.fail(Verdict, "W=%g but 3600*V=%g", s.W, 3600*s.V)
+	}
+	v := sc.Thresholds.Judge(s.W)
+	if v < lunar.Lost && s.W >= sc.Thresholds[v] || v > 0 && s.W < sc.Thresholds[v-1] {
+		c.fail(Verdict, "W=%g judged %v", s.W, v)
+	}
+	if r := lunar.Simulate(sc, lunar.Schedule(ks, 0)); r.Verdict != v || r.Impact != s.W {
+		c.fail(Verdict, "Simulate judged %v at W=%g, the flight %v at W=%g", r.Verdict, r.Impact, v, s.W)
+	}
+	if c.err != nil {
+		return c.err
+	}
+	return nil
+}
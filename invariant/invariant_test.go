@@ -0,0 +1,106 @@
+package invariant
+
+import (
+	"errors"
+	"math/rand/v2"
+	"strings"
+	"testing"
+
+	"gitlab.com/jhinrichsen/lunar-lander/lunar"
+)
+
+func TestLaws(t *testing.T) {
+	rng := rand.New(rand.NewPCG(1969, 7))
+	for _, name := range lunar.Presets() {
+		sc, err := lunar.Open(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for range 3000 {
+			ks := Schedule(rng, sc, 1+rng.IntN(40))
+			if err := Check(sc, ks); err != nil {
+				t.Fatalf("%s %v: %v", name, ks, err)
+			}
+		}
+	}
+}
+
+func TestSchedule(t *testing.T) {
+	rng := rand.New(rand.NewPCG(1, 2))
+	sc := lunar.Classic()
+	var zero, limits int
+	for _, k := range Schedule(rng, sc, 6000) {
+		if !sc.Valid(k) {
+			t.Fatalf("illegal K %v", k)
+		}
+		switch k {
+		case 0:
+			zero++
+		case sc.MinK, sc.MaxK:
+			limits++
+		}
+	}
+	if zero < 1800 || limits < 1800 {
+		t.Fatalf("want a third each of 0 and the limits, got %d and %d", zero, limits)
+	}
+}
+
+func TestPerfect(t *testing.T) {
+	ks := []float64{0, 0, 0, 0, 0, 0, 200, 200, 200, 200, 200, 0, 0, 100, 200, 200, 0, 0, 71, 37}
+	if err := Check(lunar.Classic(), ks); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRejects(t *testing.T) {
+	for _, name := range []string{"../scenarios/crosswind.json", "../scenarios/tranquility.json", "../scenarios/malfunction.json"} {
+		sc, err := lunar.Open(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := Check(sc, nil); err == nil {
+			t.Errorf("%s: want the scenario rejected", name)
+		}
+	}
+}
+
+// TestOverdraw flies into the quirk of 08.10: the braking step burns more
+// fuel than is left. The original reports FUEL LEFT: -6.47 LBS too.
+func TestOverdraw(t *testing.T) {
+	// found by TestLaws, the fractions matter
+	ks := []float64{
+		200, 200, 195.07849350456664, 0,
+		0, 36.462308844652014, 8, 8,
+		0, 118.94159964655898, 183.48560956497641, 0,
+		200, 0, 72.75850918007455, 8,
+		8, 156.62257077946424, 0, 0,
+		8, 200,
+	}
+	r := lunar.Simulate(lunar.Classic(), lunar.Schedule(ks, 0))
+	if r.Fuel >= 0 || !r.FuelOut {
+		t.Fatalf("want fuel out below zero, got %+v", r)
+	}
+	if err := Check(lunar.Classic(), ks); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestNoLanding(t *testing.T) {
+	sc := lunar.Classic()
+	sc.Interval = 0
+	err := Check(sc, nil)
+	var v *Violation
+	if !errors.As(err, &v) || v.Law != Touchdown {
+		t.Fatalf("want %q, got %v", Touchdown, err)
+	}
+	if !strings.HasPrefix(err.Error(), "altitude reaches zero exactly once: still flying") {
+		t.Fatalf("want the law first, got %q", err)
+	}
+}
+
+func TestViolationError(t *testing.T) {
+	v := &Violation{Law: Fuel, Step: 3, Detail: "M rose from 1 to 2"}
+	if got, want := v.Error(), "fuel never increases: step 3: M rose from 1 to 2"; got != want {
+		t.Fatalf("want %q, got %q", want, got)
+	}
+}
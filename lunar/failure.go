@@ -0,0 +1,222 @@
+package lunar
+
+import (
+	"math"
+	"math/rand/v2"
+	"slices"
+
+	"gitlab.com/jhinrichsen/lunar-lander/typefmt"
+)
+
+// Fault is the kind of a failure.
+type Fault string
+
+const (
+	EngineOut     Fault = "engine-out"     // the engine burns nothing
+	StuckThrottle Fault = "stuck-throttle" // the engine keeps burning the K it burned when it stuck
+	FuelLeak      Fault = "fuel-leak"      // fuel escapes without thrust
+	ThrustLoss    Fault = "thrust-loss"    // the engine delivers less thrust per lb of fuel
+)
+
+// Failure is a malfunction during the descent. It strikes at a time in
+// At..At+Within with a chance, and clears after Duration seconds or never.
+type Failure struct {
+	Kind     Fault   `json:"kind"`
+	At       float64 `json:"at"`       // earliest time it strikes (secs)
+	Within   float64 `json:"within"`   // random delay after At (secs), 0 for exactly at At
+	Chance   float64 `json:"chance"`   // probability that it strikes at all, 0 counts as 1
+	Duration float64 `json:"duration"` // secs until it clears, 0 for the rest of the flight
+	Rate     float64 `json:"rate"`     // fuel-leak: lbs/sec lost
+	Thrust   float64 `json:"thrust"`   // thrust-loss: fraction of Z left
+
+	k float64 // K when the throttle stuck
+}
+
+// validate checks that f makes sense.
+func (f *Failure) validate(check func(ok bool, format string, args ...any)) {
+	switch f.Kind {
+	case EngineOut, StuckThrottle:
+	case FuelLeak:
+		check(f.Rate > 0, "fuel leak rate %g must be positive", f.Rate)
+	case ThrustLoss:
+		check(f.Thrust >= 0 && f.Thrust < 1, "thrust %g left after a thrust loss outside 0..1", f.Thrust)
+	default:
+		check(false, "unknown failure %q", f.Kind)
+	}
+	check(f.At >= 0 && f.Within >= 0, "failure %s at %g+%g secs before the start", f.Kind, f.At, f.Within)
+	check(f.Chance >= 0 && f.Chance <= 1, "chance %g of failure %s outside 0..1", f.Chance, f.Kind)
+	check(f.Duration >= 0, "failure %s lasts a negative %g secs", f.Kind, f.Duration)
+}
+
+// Malfunctions are the failures of a scenario. Times and chances are
+// drawn from Seed, so the same seed always yields the same failures.
+type Malfunctions struct {
+	Seed     uint64    `json:"seed"`
+	Failures []Failure `json:"failures"`
+}
+
+// validate checks the failures of m.
+func (m *Malfunctions) validate(check func(ok bool, format string, args ...any)) {
+	for i := range m.Failures {
+		m.Failures[i].validate(check)
+	}
+}
+
+// Draw returns the failures that strike a flight in the order they strike.
+func (m *Malfunctions) Draw() []Failure {
+	rng := rand.New(rand.NewPCG(m.Seed, m.Seed))
+	var fs []Failure
+	for _, f := range m.Failures {
+		// draw both numbers for each failure, so one failure does not
+		// change the draws of the next
+		at, chance := f.At+f.Within*rng.Float64(), rng.Float64()
+		if f.Chance != 0 && chance >= f.Chance {
+			continue
+		}
+		f.At, f.Within, f.Chance = at, 0, 1
+		fs = append(fs, f)
+	}
+	slices.SortStableFunc(fs, func(a, b Failure) int {
+		switch {
+		case a.At < b.At:
+			return -1
+		case a.At > b.At:
+			return 1
+		}
+		return 0
+	})
+	return fs
+}
+
+// Faults injects the malfunctions of a scenario into a flight.
+type Faults struct {
+	// Announce, if set, is called when failure f strikes or clears at
+	// time l.
+	Announce func(f Failure, cleared bool, l float64)
+
+	pending []Failure // yet to strike, by time
+	active  []Failure
+}
+
+// Faults returns the malfunctions of a flight of sc, none if sc has no
+// Malfunctions.
+func (sc Scenario) Faults() *Faults {
+	if sc.Malfunctions == nil {
+		return &Faults{}
+	}
+	return &Faults{pending: sc.Malfunctions.Draw()}
+}
+
+// Active returns the failures in effect.
+func (f *Faults) Active() []Failure {
+	return f.active
+}
+
+// next returns the time of the next failure to strike or clear.
+func (f *Faults) next() (l float64, ok bool) {
+	l = math.Inf(1)
+	if len(f.pending) > 0 {
+		l = f.pending[0].At
+	}
+	for _, a := range f.active {
+		if a.Duration > 0 {
+			l = min(l, a.At+a.Duration)
+		}
+	}
+	return l, !math.IsInf(l, 1)
+}
+
+// update clears and strikes the failures due by time l, the pilot burning
+// k.
+func (f *Faults) update(l, k float64) {
+	for {
+		next, ok := f.next()
+		if !ok || next > l {
+			return
+		}
+		if i := slices.IndexFunc(f.active, func(a Failure) bool {
+			return a.Duration > 0 && a.At+a.Duration == next
+		}); i >= 0 {
+			a := f.active[i]
+			f.active = slices.Delete(f.active, i, i+1)
+			if f.Announce != nil {
+				f.Announce(a, true, next)
+			}
+			continue
+		}
+		a := f.pending[0]
+		f.pending = f.pending[1:]
+		a.k = k
+		f.active = append(f.active, a)
+		if f.Announce != nil {
+			f.Announce(a, false, next)
+		}
+	}
+}
+
+// effect returns the flow of fuel out of the tanks for burn rate k and the
+// fraction of the thrust constant Z that flow yields. A leak adds to the
+// flow but not to the thrust.
+func (f *Faults) effect(k float64) (flow, thrust float64) {
+	thrust = 1
+	var leak float64
+	out := false
+	for _, a := range f.active {
+		switch a.Kind {
+		case EngineOut:
+			out = true
+		case StuckThrottle:
+			k = a.k
+		case FuelLeak:
+			leak += a.Rate
+		case ThrustLoss:
+			thrust *= a.Thrust
+		}
+	}
+	if out {
+		k = 0
+	}
+	if leak > 0 {
+		thrust *= k / (k + leak)
+	}
+	return k + leak, thrust
+}
+
+// Fly flies one decision interval of t seconds from time l with the pilot
+// burning k. fly flies the capsule at flow k with the thrust constant
+// reduced to the fraction thrust for t seconds; Fly calls it once for each
+// part of the interval between failures striking and clearing.
+func (f *Faults) Fly(l, k, t float64, fly func(k, thrust, t float64) Event) Event {
+	for {
+		f.update(l, k)
+		d, last := t, true
+		next, ok := f.next()
+		if ok && next < l+t {
+			d, last = next-l, false
+		}
+		flow, thrust := f.effect(k)
+		ev := fly(flow, thrust, d)
+		if ev != Flying || last {
+			return ev
+		}
+		l, t = next, t-d
+	}
+}
+
+// announcements are the messages of each fault as it strikes and clears.
+var announcements = map[Fault][2]string{
+	EngineOut:     {"ENGINE OUT AT", "ENGINE RESTART AT"},
+	StuckThrottle: {"THROTTLE STUCK AT", "THROTTLE FREE AT"},
+	FuelLeak:      {"FUEL LEAK AT", "LEAK SEALED AT"},
+	ThrustLoss:    {"THRUST LOSS AT", "THRUST RESTORED AT"},
+}
+
+// announce types a failure striking or clearing at time l in the style of
+// line 04.10.
+func announce(t *typefmt.Writer, f Failure, cleared bool, l float64) {
+	msg := announcements[f.Kind][0]
+	if cleared {
+		msg = announcements[f.Kind][1]
+	}
+	t.Type(msg, l, " SECS\n")
+}
@@ -0,0 +1,164 @@
+package lunar
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func malfunction(fs ...Failure) Scenario {
+	sc := Classic()
+	sc.Malfunctions = &Malfunctions{Failures: fs}
+	return sc
+}
+
+func TestDraw(t *testing.T) {
+	m := Malfunctions{Seed: 7, Failures: []Failure{
+		{Kind: EngineOut, At: 100, Within: 50},
+		{Kind: FuelLeak, At: 10, Rate: 1},
+		{Kind: ThrustLoss, At: 0, Chance: .5, Thrust: .5},
+		{Kind: StuckThrottle, At: 20, Chance: 1e-9},
+	}}
+	fs := m.Draw()
+	if fmt.Sprint(fs) != fmt.Sprint(m.Draw()) {
+		t.Fatalf("want the same failures for the same seed, got %v and %v", fs, m.Draw())
+	}
+	var kinds []Fault
+	for i, f := range fs {
+		kinds = append(kinds, f.Kind)
+		if i > 0 && fs[i-1].At > f.At {
+			t.Errorf("want failures by time, got %v", fs)
+		}
+		if f.Within != 0 || f.Chance != 1 {
+			t.Errorf("want %s drawn, got %+v", f.Kind, f)
+		}
+	}
+	if got := fmt.Sprint(kinds); got != "[fuel-leak engine-out]" && got != "[thrust-loss fuel-leak engine-out]" {
+		t.Errorf("want leak and engine out, maybe thrust loss, got %s", got)
+	}
+	if at := fs[len(fs)-1].At; at < 100 || at > 150 {
+		t.Errorf("want the engine out within 100..150 secs, got %g", at)
+	}
+	// over many seeds a chance of one half strikes about half of the time
+	n := 0
+	for seed := range uint64(1000) {
+		m.Seed = seed
+		if m.Draw()[0].Kind == ThrustLoss {
+			n++
+		}
+	}
+	if n < 450 || n > 550 {
+		t.Errorf("want about 500 thrust losses in 1000 flights, got %d", n)
+	}
+}
+
+func TestFaultsFly(t *testing.T) {
+	sc := malfunction(
+		Failure{Kind: ThrustLoss, At: 5, Thrust: .5},
+		Failure{Kind: EngineOut, At: 12, Duration: 3},
+		Failure{Kind: FuelLeak, At: 14, Rate: 50},
+		Failure{Kind: StuckThrottle, At: 25, Duration: 10},
+	)
+	f := sc.Faults()
+	var got []string
+	f.Announce = func(f Failure, cleared bool, l float64) {
+		got = append(got, fmt.Sprintf("%s %t %g", f.Kind, cleared, l))
+	}
+	fly := func(k, thrust, t float64) Event {
+		got = append(got, fmt.Sprintf("K=%g Z*%.4g T=%g", k, thrust, t))
+		return Flying
+	}
+	for _, kl := range [][2]float64{{100, 0}, {100, 10}, {30, 20}, {60, 30}} {
+		f.Fly(kl[1], kl[0], 10, fly)
+	}
+	want := []string{
+		"K=100 Z*1 T=5", "thrust-loss false 5", "K=100 Z*0.5 T=5",
+		"K=100 Z*0.5 T=2", "engine-out false 12", "K=0 Z*0.5 T=2",
+		"fuel-leak false 14", "K=50 Z*0 T=1", "engine-out true 15", "K=150 Z*0.3333 T=5",
+		"K=80 Z*0.1875 T=5", "stuck-throttle false 25", "K=80 Z*0.1875 T=5",
+		"K=80 Z*0.1875 T=5", "stuck-throttle true 35", "K=110 Z*0.2727 T=5",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("want\n%s\ngot\n%s", strings.Join(want, "\n"), strings.Join(got, "\n"))
+	}
+	if a := f.Active(); len(a) != 2 || a[0].Kind != ThrustLoss || a[1].Kind != FuelLeak {
+		t.Errorf("want thrust loss and leak left, got %v", a)
+	}
+}
+
+func TestSimulateFailures(t *testing.T) {
+	coast := Simulate(Classic(), Schedule(nil, 0))
+	r := Simulate(malfunction(Failure{Kind: EngineOut}), Schedule(good, 0))
+	if r.Time != coast.Time || r.Impact != coast.Impact {
+		t.Errorf("want to coast without engine, got %+v", r)
+	}
+	// a leak without thrust falls like the coast and loses its rate
+	r = Simulate(malfunction(Failure{Kind: FuelLeak, Rate: 10}), Schedule(nil, 0))
+	if r.Time != coast.Time || r.Impact != coast.Impact || !near(r.Fuel, coast.Fuel-10*r.Time, 1e-9) {
+		t.Errorf("want to coast losing 10 lbs/sec, got %+v", r)
+	}
+	weak := Classic()
+	weak.Thrust *= .9
+	want := Simulate(weak, Schedule(good, 0))
+	r = Simulate(malfunction(Failure{Kind: ThrustLoss, Thrust: .9}), Schedule(good, 0))
+	if r != want {
+		t.Errorf("want %+v with 90 percent thrust, got %+v", want, r)
+	}
+	// a throttle stuck at 0 until the tanks run dry
+	r = Simulate(malfunction(Failure{Kind: StuckThrottle}), Schedule(good, 0))
+	if r.Time != coast.Time || r.Impact != coast.Impact {
+		t.Errorf("want a throttle stuck at 0 to coast, got %+v", r)
+	}
+	sc := planar()
+	sc.Malfunctions = &Malfunctions{Failures: []Failure{{Kind: EngineOut}}}
+	if r := SimulatePlanar(sc, pitched(good, 7, 10)); r.Time != coast.Time || r.Range != sc.Horizontal.Drift*r.Time {
+		t.Errorf("want to coast and drift without engine, got %+v", r)
+	}
+}
+
+func TestReadMalfunctions(t *testing.T) {
+	sc, err := LoadScenario("../scenarios/malfunction.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sc.Malfunctions == nil || sc.Malfunctions.Seed != 1969 || len(sc.Malfunctions.Failures) != 4 {
+		t.Fatalf("want four failures seeded 1969, got %+v", sc.Malfunctions)
+	}
+	for _, js := range []string{
+		`{"malfunctions": {"failures": [{"kind": "meteor"}]}}`,
+		`{"malfunctions": {"failures": [{"kind": "fuel-leak"}]}}`,
+		`{"malfunctions": {"failures": [{"kind": "thrust-loss", "thrust": 1}]}}`,
+		`{"malfunctions": {"failures": [{"kind": "engine-out", "at": -1}]}}`,
+		`{"malfunctions": {"failures": [{"kind": "engine-out", "chance": 2}]}}`,
+		`{"malfunctions": {"failures": [{"kind": "engine-out", "duration": -5}]}}`,
+		`{"malfunctions": {"failures": [{"kind": "engine-out", "when": 5}]}}`,
+	} {
+		if _, err := ReadScenario(strings.NewReader(js)); err == nil {
+			t.Errorf("%s: want error", js)
+		}
+	}
+}
+
+func TestGameMalfunctions(t *testing.T) {
+	sc, err := LoadScenario("../scenarios/malfunction.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var in strings.Builder
+	for _, k := range good {
+		in.WriteString(num(k) + "\n")
+	}
+	in.WriteString("NO\n")
+	var out bytes.Buffer
+	NewGame(sc, strings.NewReader(in.String()), &out).Run()
+	for _, s := range []string{
+		"K=:FUEL LEAK AT    80.04 SECS\n",
+		"K=:ENGINE OUT AT    92.21 SECS\nENGINE RESTART AT    97.21 SECS\n",
+		"ON THE MOON AT   132.05 SECS\n",
+	} {
+		if !strings.Contains(out.String(), s) {
+			t.Errorf("missing %q in\n%s", s, out.String())
+		}
+	}
+}
@@ -0,0 +1,37 @@
+package lunar
+
+// Flight is a descent by the rules of a scenario: the state over the
+// terrain of its site, drifting if it has a Horizontal extension, with its
+// malfunctions striking on the way. Game, Simulate, Record and the tools
+// all fly one, so they agree on every scenario.
+type Flight struct {
+	Planar
+	Faults *Faults
+
+	sc Scenario
+}
+
+// Flight returns a flight of sc at its start.
+func (sc Scenario) Flight() *Flight {
+	return &Flight{Planar: sc.Planar(), Faults: sc.Faults(), sc: sc}
+}
+
+// Fly flies one decision interval at burn rate k with the engine pitched
+// by p degrees. Without a Horizontal extension the capsule flies the
+// vertical loop of the FOCAL program and p must be 0.
+func (f *Flight) Fly(k, p float64) Event {
+	return f.Faults.Fly(f.L, k, f.sc.Interval, func(k, thrust, t float64) Event {
+		if f.sc.Horizontal == nil {
+			f.Z = f.sc.Thrust * thrust
+			return f.State.Fly(k, t)
+		}
+		f.z = f.sc.Thrust * thrust
+		return f.Planar.Fly(k, p, t)
+	})
+}
+
+// Verdict grades the landing by impact velocity and, where the scenario
+// has them, drift, tilt and site.
+func (f *Flight) Verdict() Verdict {
+	return f.sc.judge(&f.Planar)
+}
@@ -0,0 +1,193 @@
+package lunar
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"gitlab.com/jhinrichsen/lunar-lander/focal"
+	"gitlab.com/jhinrichsen/lunar-lander/trace"
+	"gitlab.com/jhinrichsen/lunar-lander/typefmt"
+)
+
+// Game plays the terminal dialogue of lunar-lander.fc for a scenario.
+// The output is byte-identical to retrofocal for the classic scenario.
+type Game struct {
+	Scenario Scenario
+	Units    Units
+
+	// Trace, if set, records the variables after each FOCAL line.
+	Trace *trace.Recorder
+
+	s   *Flight
+	p   float64 // P, the answer to TRY AGAIN
+	x   float64 // X, the dot counter of 02.72
+	in  *bufio.Scanner
+	out io.Writer
+	tty *typefmt.Writer
+	rnd func() float64 // FRAN() in answers
+}
+
+// NewGame creates a game reading answers from in and typing to out.
+func NewGame(sc Scenario, in io.Reader, out io.Writer) *Game {
+	return &Game{
+		Scenario: sc,
+		in:       bufio.NewScanner(in),
+		out:      out,
+		tty:      typefmt.NewWriter(out),
+		rnd:      focal.NewRandom(),
+	}
+}
+
+// Run plays until the pilot declines another try or input runs out.
+func (g *Game) Run() {
+	g.Scenario.Intro(g.out)
+	for {
+		// 01.20 T "FIRST RADAR CHECK COMING UP"!!!;E
+		fmt.Fprint(g.out, "FIRST RADAR CHECK COMING UP\n\n\n")
+		// 01.30-01.40
+		fmt.Fprintln(g.out, "COMMENCE LANDING PROCEDURE")
+		if g.Scenario.Horizontal != nil {
+			g.Units.planarHeader(g.out)
+		} else {
+			g.Units.header(g.out)
+		}
+		g.fly()
+		if !g.tryAgain() {
+			return
+		}
+	}
+}
+
+// fly plays one descent from line 01.50 to the verdict.
+func (g *Game) fly() {
+	g.Trace.Erase(120)
+	g.s = g.Scenario.Flight()
+	g.s.Faults.Announce = func(f Failure, cleared bool, l float64) { announce(g.tty, f, cleared, l) }
+	g.p, g.x = 0, 0
+	g.s.Trace = g.Trace
+	g.s.trace(150, "fly")
+	for {
+		ev := Flying
+		if g.Scenario.Horizontal != nil {
+			g.Units.planarStatus(g.tty, &g.s.Planar)
+			k, ok := g.askK()
+			if !ok {
+				return
+			}
+			p, ok := g.askP()
+			if !ok {
+				return
+			}
+			ev = g.s.Fly(k, p)
+		} else {
+			g.Units.status(g.tty, &g.s.State)
+			k, ok := g.askK()
+			if !ok {
+				return
+			}
+			ev = g.s.Fly(k, 0)
+		}
+		switch ev {
+		case Flying:
+			continue
+		case FuelOut:
+			// 04.10 T "FUEL OUT AT",L," SECS"!
+			g.tty.Type("FUEL OUT AT", g.s.L, " SECS\n")
+			g.s.FreeFall()
+		}
+		g.landing()
+		return
+	}
+}
+
+// askK reads burn rates until one is possible (lines 02.20-02.73).
+func (g *Game) askK() (float64, bool) {
+	for {
+		if !g.in.Scan() {
+			return 0, false
+		}
+		k := g.answer()
+		// 02.20 A K;S T=10, the FOCAL variable takes impossible
+		// rates as well.
+		g.s.K, g.s.T = k, g.Scenario.Interval
+		g.s.trace(220, "askK")
+		if g.Scenario.Valid(k) {
+			return k, true
+		}
+		// 02.72 T "NOT POSSIBLE";F X=1,51;T "."
+		// 02.73 T "K=";A K;G 2.7
+		fmt.Fprintf(g.out, "NOT POSSIBLE%sK=:", strings.Repeat(".", 51))
+		g.x = 52
+	}
+}
+
+// askP reads pitches until one is possible, after K in two dimensions.
+func (g *Game) askP() (float64, bool) {
+	fmt.Fprint(g.out, " P=:")
+	for {
+		if !g.in.Scan() {
+			return 0, false
+		}
+		p := g.answer()
+		if g.Scenario.ValidPitch(p) {
+			return p, true
+		}
+		// refused like K, with the 51 dots of 02.72
+		fmt.Fprintf(g.out, "NOT POSSIBLE%sP=:", strings.Repeat(".", 51))
+	}
+}
+
+// landing types the touchdown report and verdict (lines 05.10-05.83).
+func (g *Game) landing() {
+	g.tty.Type("ON THE MOON AT", g.s.L, " SECS\n")
+	g.Units.impact(g.tty, g.s.W, g.s.Fuel())
+	if g.Scenario.Horizontal != nil {
+		g.Units.planarImpact(g.tty, g.s.Drift(), g.s.P)
+	}
+	if g.Scenario.Site != nil {
+		g.Units.siteReport(g.tty, g.Scenario.Site, g.s.X)
+	}
+	fmt.Fprintln(g.out, g.s.Verdict())
+	if g.Scenario.Thresholds.Judge(g.s.W) == Lost {
+		g.Units.crater(g.tty, g.s.W*.277777)
+	}
+}
+
+// answer evaluates an ASK answer like FOCAL: an expression in the
+// program's variables, in which YES and NO read as the numbers 0YES and 0NO.
+func (g *Game) answer() float64 {
+	vars := g.s.Vars()
+	vars["P"], vars["X"] = g.p, g.x
+	return focal.Answer(g.in.Text(), vars, g.rnd)
+}
+
+// yes and no are the constants 0YES and 0NO of 05.92 and 05.94.
+var (
+	yes, _ = focal.ReadNumber("0YES")
+	no, _  = focal.ReadNumber("0NO")
+)
+
+// tryAgain asks for another descent (lines 05.90-05.98).
+func (g *Game) tryAgain() bool {
+	fmt.Fprint(g.out, "\n\n\n\nTRY AGAIN?\n")
+	for {
+		fmt.Fprint(g.out, "(ANS. YES OR NO):")
+		if !g.in.Scan() {
+			break
+		}
+		// 05.92 I (P-0NO)5.94,5.98
+		// 05.94 I (P-0YES)5.92,1.2,5.92
+		g.p = g.answer()
+		switch g.p {
+		case yes:
+			return true
+		case no:
+			fmt.Fprint(g.out, "CONTROL OUT\n\n\n")
+			return false
+		}
+	}
+	fmt.Fprint(g.out, "CONTROL OUT\n\n\n")
+	return false
+}
@@ -0,0 +1,198 @@
+package lunar
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+
+	"gitlab.com/jhinrichsen/lunar-lander/focal"
+	"gitlab.com/jhinrichsen/lunar-lander/trace"
+)
+
+// TestGameTranscripts compares against transcripts of retrofocal
+// lunar-lander.fc for the same input, and against the metric and SI
+// rendering of those runs.
+func TestGameTranscripts(t *testing.T) {
+	for _, units := range []Units{Imperial, Metric, SI} {
+		for _, name := range []string{"perfect", "good", "crash"} {
+			t.Run(units.String()+"/"+name, func(t *testing.T) {
+				in, err := os.ReadFile("testdata/" + name + ".in")
+				if err != nil {
+					t.Fatal(err)
+				}
+				golden := "testdata/" + name + ".out"
+				if units != Imperial {
+					golden = "testdata/" + name + "." + units.String() + ".out"
+				}
+				want, err := os.ReadFile(golden)
+				if err != nil {
+					t.Fatal(err)
+				}
+				var out bytes.Buffer
+				g := NewGame(Classic(), bytes.NewReader(in), &out)
+				g.Units = units
+				g.Run()
+				if got := out.String(); got != string(want) {
+					t.Errorf("want\n%s\ngot\n%s", want, got)
+				}
+			})
+		}
+	}
+}
+
+func TestParseUnits(t *testing.T) {
+	for _, u := range []Units{Imperial, Metric, SI} {
+		if got, err := ParseUnits(u.String()); err != nil || got != u {
+			t.Errorf("ParseUnits(%q): got %v, %v", u, got, err)
+		}
+	}
+	if _, err := ParseUnits("furlongs"); err == nil {
+		t.Error("want error for unknown units")
+	}
+}
+
+// TestGameTryAgain restarts at line 01.20, skipping the briefing.
+func TestGameTryAgain(t *testing.T) {
+	in := strings.Repeat("200\n", 8) + "YES\n" + strings.Repeat("200\n", 8) + "NO\n"
+	var out bytes.Buffer
+	NewGame(Classic(), strings.NewReader(in), &out).Run()
+	got := out.String()
+	if n := strings.Count(got, "CONTROL CALLING"); n != 1 {
+		t.Errorf("want briefing once, got %d times", n)
+	}
+	if n := strings.Count(got, "FIRST RADAR CHECK"); n != 2 {
+		t.Errorf("want two descents, got %d", n)
+	}
+}
+
+// lander parses lunar-lander.fc.
+func lander(t *testing.T) *focal.Program {
+	t.Helper()
+	f, err := os.Open("../lunar-lander.fc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	p, err := focal.Parse(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+// focalTrace runs lunar-lander.fc on in and returns its trace.
+func focalTrace(t *testing.T, in string) []trace.Event {
+	t.Helper()
+	var b bytes.Buffer
+	err := trace.Focal(lander(t), focal.NewTerminal(strings.NewReader(in), io.Discard), &b)
+	if err != nil && !errors.Is(err, io.EOF) {
+		t.Fatal(err)
+	}
+	es, err := trace.Read(&b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return es
+}
+
+// TestGameTrace checks the engine against the FOCAL program variable by
+// variable, including impossible rates, running out of fuel and a retry.
+func TestGameTrace(t *testing.T) {
+	inputs := map[string]string{
+		"retry": "5\n201\n" + strings.Repeat("200\n", 12) + "YES\n" + strings.Repeat("0\n", 12) + "NO\n",
+	}
+	for _, name := range []string{"perfect", "good", "crash"} {
+		in, err := os.ReadFile("testdata/" + name + ".in")
+		if err != nil {
+			t.Fatal(err)
+		}
+		inputs[name] = string(in)
+	}
+	for name, in := range inputs {
+		t.Run(name, func(t *testing.T) {
+			var b bytes.Buffer
+			g := NewGame(Classic(), strings.NewReader(in), io.Discard)
+			g.Trace = trace.NewRecorder(&b)
+			g.Run()
+			if err := g.Trace.Flush(); err != nil {
+				t.Fatal(err)
+			}
+			got, err := trace.Read(&b)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := focalTrace(t, in)
+			if m := trace.Diff(want, got, 1e-9); m != nil {
+				t.Error(m.Format(want, got))
+			}
+		})
+	}
+}
+
+// TestGameAnswers answers with FOCAL expressions, which ASK evaluates in
+// the program's variables.
+func TestGameAnswers(t *testing.T) {
+	p := lander(t)
+	for name, in := range map[string]string{
+		"arithmetic": "2*100\n(150+50)\n" + strings.Repeat("K\n", 10) + "no\n",
+		"variables":  "Q\nX\nX\nX-42\nM/N\nA\nK+1\n" + strings.Repeat("0\n", 10) + "NO\n",
+		"letters":    "K=3\nYES\n" + strings.Repeat("200\n", 12) + "MAYBE\nP\n0YES\n" + strings.Repeat("0\n", 12) + "N.O.\nNO;\n",
+		"random":     "FRAN()*200\n" + strings.Repeat("0\n", 12) + "FRAN()\nNO\n",
+	} {
+		t.Run(name, func(t *testing.T) {
+			var want, got bytes.Buffer
+			err := focal.NewInterp(p, focal.NewTerminal(strings.NewReader(in), &want)).Run()
+			if err != nil && !errors.Is(err, io.EOF) {
+				t.Fatal(err)
+			}
+			NewGame(Classic(), strings.NewReader(in), &got).Run()
+			if got.String() != want.String() {
+				t.Errorf("want\n%s\ngot\n%s", want.String(), got.String())
+			}
+		})
+	}
+}
+
+// TestGameInterval runs lunar-lander.fc with the 10 of lines 01.06 and
+// 02.20 replaced by other decision intervals.
+func TestGameInterval(t *testing.T) {
+	src, err := os.ReadFile("../lunar-lander.fc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, interval := range []float64{MinInterval, 3, 25, MaxInterval} {
+		t.Run(num(interval), func(t *testing.T) {
+			sc := Classic()
+			sc.Interval = interval
+			// coast for a minute, burn hard, then gently, and answer
+			// as many K prompts as the flight has
+			var ks []string
+			for l := 0.0; l < 200; l += interval {
+				ks = append(ks, map[bool]string{true: "0", false: "200"}[l < 60])
+			}
+			ks = append(ks, slices.Repeat([]string{"30"}, 300)...)
+			var probe bytes.Buffer
+			NewGame(sc, strings.NewReader(strings.Join(ks, "\n")+"\n"), &probe).Run()
+			in := strings.Join(ks[:strings.Count(probe.String(), "K=:")], "\n") + "\nNO\n"
+			s := strings.Replace(string(src), "EACH 10 SECS", "EACH "+num(interval)+" SECS", 1)
+			s = strings.Replace(s, "S T=10", "S T="+num(interval), 1)
+			p, err := focal.Parse(strings.NewReader(s))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var want, got bytes.Buffer
+			err = focal.NewInterp(p, focal.NewTerminal(strings.NewReader(in), &want)).Run()
+			if err != nil && !errors.Is(err, io.EOF) {
+				t.Fatal(err)
+			}
+			NewGame(sc, strings.NewReader(in), &got).Run()
+			if got.String() != want.String() {
+				t.Errorf("want\n%s\ngot\n%s", want.String(), got.String())
+			}
+		})
+	}
+}
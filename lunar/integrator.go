@@ -0,0 +1,106 @@
+package lunar
+
+import "math"
+
+// Integrator advances altitude and velocity over one step, the job of
+// subroutine 9: given A, V and M at the start, the burn rate K and the
+// step S of a state, it returns the altitude I and velocity J at the end.
+// A nil Integrator of a State is the Taylor series of the FOCAL program.
+type Integrator interface {
+	Step(s *State) (i, j float64)
+}
+
+// Taylor is subroutine 9 as written: ln(1-Q) and its integral cut after
+// five terms of their series in Q = S*K/M. The error grows like Q^6, so
+// long steps at full burn on a light capsule are the least accurate.
+type Taylor struct{}
+
+// Step implements Integrator.
+func (Taylor) Step(s *State) (i, j float64) {
+	return taylorI(s), taylorJ(s)
+}
+
+// taylorJ is line 09.10.
+func taylorJ(s *State) float64 {
+	q := s.S * s.K / s.M
+	return s.V + s.G*s.S + s.Z*(-q-q*q/2-q*q*q/3-q*q*q*q/4-q*q*q*q*q/5)
+}
+
+// taylorI is line 09.40.
+func taylorI(s *State) float64 {
+	q := s.S * s.K / s.M
+	return s.A - s.G*s.S*s.S/2 - s.V*s.S + s.Z*s.S*(q/2+q*q/6+q*q*q/12+q*q*q*q/20+q*q*q*q*q/30)
+}
+
+// Exact is the closed form of the rocket equation the series of
+// subroutine 9 approximates. Burning K from mass M for S seconds changes
+// the velocity by Z*ln(1-Q), Tsiolkovsky's law, and the altitude by
+// Z*S*(Q+(1-Q)*ln(1-Q))/Q, its integral over the step.
+type Exact struct{}
+
+// Step implements Integrator.
+func (Exact) Step(s *State) (i, j float64) {
+	i = s.A - s.G*s.S*s.S/2 - s.V*s.S
+	j = s.V + s.G*s.S
+	q := s.S * s.K / s.M
+	if q == 0 {
+		return i, j
+	}
+	ln := math.Log1p(-q)
+	return i + s.Z*s.S*(q+(1-q)*ln)/q, j + s.Z*ln
+}
+
+// RK integrates the equations of motion numerically with the classical
+// Runge-Kutta method of fourth order, halving and doubling its step so
+// the error estimated by step doubling stays below Tol. It checks Exact
+// without sharing a formula with it.
+type RK struct {
+	// Tol is the largest error per step relative to the magnitude of
+	// altitude and velocity, 1e-12 if 0.
+	Tol float64
+}
+
+// Step implements Integrator.
+func (rk RK) Step(s *State) (i, j float64) {
+	tol := rk.Tol
+	if tol == 0 {
+		tol = 1e-12
+	}
+	// d/dt (a, v) = (-v, G - Z*K/(M-K*t))
+	f := func(t, a, v float64) (float64, float64) {
+		return -v, s.G - s.Z*s.K/(s.M-s.K*t)
+	}
+	rk4 := func(t, a, v, h float64) (float64, float64) {
+		a1, v1 := f(t, a, v)
+		a2, v2 := f(t+h/2, a+h/2*a1, v+h/2*v1)
+		a3, v3 := f(t+h/2, a+h/2*a2, v+h/2*v2)
+		a4, v4 := f(t+h, a+h*a3, v+h*v3)
+		return a + h/6*(a1+2*a2+2*a3+a4), v + h/6*(v1+2*v2+2*v3+v4)
+	}
+	a, v := s.A, s.V
+	for t, h := 0.0, s.S; t < s.S; {
+		last := h >= s.S-t
+		if last {
+			h = s.S - t
+		}
+		a1, v1 := rk4(t, a, v, h)
+		am, vm := rk4(t, a, v, h/2)
+		a2, v2 := rk4(t+h/2, am, vm, h/2)
+		e := max(math.Abs(a2-a1)/(1+math.Abs(a2)), math.Abs(v2-v1)/(1+math.Abs(v2)))
+		// the error of RK4 shrinks with the fifth power of the step
+		grow := 4.0
+		if e > 0 {
+			grow = min(4, max(.1, .9*math.Pow(tol/e, .2)))
+		}
+		if e <= tol || h < 1e-9*s.S {
+			t += h
+			if last {
+				t = s.S
+			}
+			// Richardson extrapolation of the two estimates
+			a, v = a2+(a2-a1)/15, v2+(v2-v1)/15
+		}
+		h *= grow
+	}
+	return a, v
+}
@@ -0,0 +1,78 @@
+package lunar
+
+import (
+	"math"
+	"testing"
+)
+
+// step is the first interval of the good landing at full burn on a light
+// capsule, where the series is least accurate.
+func step() State {
+	return State{A: 100, V: 1, M: 17000, G: .001, Z: 1.8, K: 200, S: 10}
+}
+
+func TestTaylorIsSub9(t *testing.T) {
+	s := step()
+	s.sub9()
+	if i, j := (Taylor{}).Step(&s); i != s.I || j != s.J {
+		t.Errorf("want I=%g J=%g, got %g %g", s.I, s.J, i, j)
+	}
+}
+
+func TestExact(t *testing.T) {
+	s := step()
+	i, j := Exact{}.Step(&s)
+	// Tsiolkovsky: Z*ln(M/(M-S*K)) less gravity loss
+	if want := s.V + s.G*s.S - s.Z*math.Log(s.M/(s.M-s.S*s.K)); !near(j, want, 1e-15) {
+		t.Errorf("want J=%g, got %g", want, j)
+	}
+	ti, tj := Taylor{}.Step(&s)
+	// the series is short by about Z*Q^6/6 and Z*S*Q^5/42
+	q := s.S * s.K / s.M
+	if d := tj - j; !near(d, s.Z*math.Pow(q, 6)/6, s.Z*math.Pow(q, 7)/4) {
+		t.Errorf("J error %g, want about %g", d, s.Z*math.Pow(q, 6)/6)
+	}
+	if d := i - ti; !near(d, s.Z*s.S*math.Pow(q, 6)/42, s.Z*s.S*math.Pow(q, 7)/20) {
+		t.Errorf("I error %g, want about %g", d, s.Z*s.S*math.Pow(q, 6)/42)
+	}
+}
+
+func TestCoast(t *testing.T) {
+	s := step()
+	s.K = 0
+	for _, in := range []Integrator{Taylor{}, Exact{}, RK{}} {
+		i, j := in.Step(&s)
+		if !near(i, s.A-s.V*s.S-s.G*s.S*s.S/2, 1e-12) || !near(j, s.V+s.G*s.S, 1e-12) {
+			t.Errorf("%T: coasting gave I=%g J=%g", in, i, j)
+		}
+	}
+}
+
+func TestRK(t *testing.T) {
+	for _, s := range []State{step(), {A: 5, V: .01, M: 32500, G: .001, Z: 1.8, K: 8, S: .3}} {
+		i, j := RK{}.Step(&s)
+		ei, ej := Exact{}.Step(&s)
+		if !near(i, ei, 1e-11) || !near(j, ej, 1e-12) {
+			t.Errorf("want I=%.15g J=%.15g, got %.15g %.15g", ei, ej, i, j)
+		}
+	}
+}
+
+func TestSimulateWith(t *testing.T) {
+	if r := SimulateWith(Classic(), Taylor{}, Schedule(good, 0)); r != Simulate(Classic(), Schedule(good, 0)) {
+		t.Errorf("Taylor differs from subroutine 9: %+v", r)
+	}
+	// without the truncation the good landing comes down a little faster
+	r := SimulateWith(Classic(), Exact{}, Schedule(good, 0))
+	if !near(r.Impact, 21.39, .005) || r.Verdict != Poor {
+		t.Errorf("want 21.39 MPH, got %+v", r)
+	}
+	rk := SimulateWith(Classic(), RK{}, Schedule(good, 0))
+	if !near(rk.Impact, r.Impact, 1e-6) {
+		t.Errorf("RK landed at %g MPH, Exact at %g", rk.Impact, r.Impact)
+	}
+	// the perfect landing is tuned to the truncation and runs dry
+	if r := SimulateWith(Classic(), Exact{}, Schedule(perfect, 0)); !r.FuelOut || r.Verdict != Lost {
+		t.Errorf("want the perfect schedule lost without the series, got %+v", r)
+	}
+}
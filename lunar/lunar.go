@@ -0,0 +1,308 @@
+// Package lunar implements the physics of the FOCAL lunar-lander.fc simulation
+// as a reusable engine, independent of any terminal dialogue.
+// Variable names follow the FOCAL program so every statement can be traced
+// back to its line.
+package lunar
+
+import (
+	"math"
+
+	"gitlab.com/jhinrichsen/lunar-lander/focal"
+	"gitlab.com/jhinrichsen/lunar-lander/trace"
+)
+
+// State holds the FOCAL variables of the lunar program.
+type State struct {
+	A float64 // Altitude (miles)
+	V float64 // Velocity (miles/sec, positive down)
+	M float64 // Total mass (lbs)
+	N float64 // Dry mass (lbs)
+	G float64 // Gravity constant
+	Z float64 // Thrust constant
+	L float64 // Elapsed time (secs)
+	T float64 // Time remaining in current interval
+	K float64 // Fuel burn rate (lbs/sec)
+	S float64 // Time step
+	I float64 // New altitude (from subroutine 9)
+	J float64 // New velocity (from subroutine 9)
+	Q float64 // Fuel fraction S*K/M (from subroutine 9)
+	W float64 // Scratch in line 08.10, impact velocity in MPH after landing
+
+	// Ground is the height of the surface under the capsule (miles), 0
+	// for the plane of the FOCAL program.
+	Ground float64
+
+	// Integrator, if set, replaces the Taylor series of subroutine 9.
+	Integrator Integrator
+
+	// Observe, if set, is called after every step of line 06.10.
+	Observe func(s *State)
+
+	// Trace, if set, records the variables after each FOCAL line.
+	Trace *trace.Recorder
+}
+
+// observe notifies the observer, if any.
+func (s *State) observe() {
+	if s.Observe != nil {
+		s.Observe(s)
+	}
+}
+
+// Vars returns the FOCAL variables by name.
+func (s *State) Vars() map[string]float64 {
+	return map[string]float64{
+		"A": s.A, "V": s.V, "M": s.M, "N": s.N, "G": s.G, "Z": s.Z, "L": s.L,
+		"T": s.T, "K": s.K, "S": s.S, "I": s.I, "J": s.J, "Q": s.Q, "W": s.W,
+	}
+}
+
+// trace records the variables after FOCAL line n.
+func (s *State) trace(n focal.Num, what string) {
+	if s.Trace != nil {
+		s.Trace.Record(n, what, s.Vars())
+	}
+}
+
+// Event tells why Fly returned.
+type Event int
+
+const (
+	// Flying means the interval is over and a new K is due (line 03.10 to 2.1).
+	Flying Event = iota
+	// FuelOut means the tanks are empty (line 03.10 to 4.1), FreeFall
+	// completes the flight.
+	FuelOut
+	// Landed means the capsule is on the moon (line 07.10 to 5.1).
+	Landed
+)
+
+// Fuel returns the fuel left in lbs.
+func (s *State) Fuel() float64 {
+	return s.M - s.N
+}
+
+// sub9 calculates new velocity J and altitude I (lines 09.10-09.40).
+func (s *State) sub9() {
+	s.Q = s.S * s.K / s.M
+	if s.Integrator != nil {
+		s.I, s.J = s.Integrator.Step(s)
+		s.trace(940, "sub9")
+		return
+	}
+	s.J = taylorJ(s)
+	s.trace(910, "sub9")
+	s.I = taylorI(s)
+	s.trace(940, "sub9")
+}
+
+// sub6 advances the state by one step (line 06.10).
+func (s *State) sub6() {
+	s.L = s.L + s.S
+	s.T = s.T - s.S
+	s.M = s.M - s.S*s.K
+	s.A = s.I
+	s.V = s.J
+	s.trace(610, "sub6")
+	s.observe()
+}
+
+// Fly burns fuel at rate k for one decision interval of t seconds
+// (lines 03.10-03.80).
+func (s *State) Fly(k, t float64) Event {
+	s.K = k
+	s.T = t
+	s.trace(220, "Fly")
+	for {
+		// 03.10 I (M-N-.001)4.1;I (T-.001)2.1;S S=T
+		if s.M-s.N < .001 {
+			return FuelOut
+		}
+		if s.T < .001 {
+			return Flying
+		}
+		s.S = s.T
+		s.trace(310, "Fly")
+		// 03.40 I ((N+S*K)-M)3.5,3.5;S S=(M-N)/K
+		if s.N+s.S*s.K-s.M > 0 {
+			s.S = (s.M - s.N) / s.K
+			s.trace(340, "Fly")
+		}
+		// 03.50 D 9;I (I)7.1,7.1;I (V)3.8,3.8;I (J)8.1
+		s.sub9()
+		if s.I <= s.Ground {
+			s.touchdown()
+			return Landed
+		}
+		if s.V > 0 && s.J < 0 {
+			if s.brake() {
+				return Landed
+			}
+			continue
+		}
+		// 03.80 D 6;G 3.1
+		s.sub6()
+	}
+}
+
+// brake approaches the point where velocity turns upwards (lines
+// 08.10-08.30). It reports whether the capsule touched down meanwhile.
+func (s *State) brake() bool {
+	for {
+		// 08.10
+		s.W = (1 - s.M*s.G/(s.Z*s.K)) / 2
+		s.S = s.M*s.V/(s.Z*s.K*(s.W+math.Sqrt(s.W*s.W+s.V/s.Z))) + .05
+		s.trace(810, "brake")
+		s.sub9()
+		// 08.30 I (I)7.1,7.1;D 6;I (-J)3.1,3.1;I (V)3.1,3.1,8.1
+		if s.I <= s.Ground {
+			s.touchdown()
+			return true
+		}
+		s.sub6()
+		if s.J >= 0 || s.V <= 0 {
+			return false
+		}
+	}
+}
+
+// touchdown narrows the last step down to the surface (lines 07.10-07.30).
+func (s *State) touchdown() {
+	for s.S >= .005 {
+		a := s.A - s.Ground
+		s.S = 2 * a / (s.V + math.Sqrt(s.V*s.V+2*a*(s.G-s.Z*s.K/s.M)))
+		s.trace(710, "touchdown")
+		s.sub9()
+		s.sub6()
+	}
+	s.W = 3600 * s.V
+	s.trace(510, "touchdown")
+}
+
+// FreeFall drops the empty capsule to the surface (line 04.40).
+func (s *State) FreeFall() {
+	a := s.A - s.Ground
+	s.S = (math.Sqrt(s.V*s.V+2*a*s.G) - s.V) / s.G
+	s.V = s.V + s.G*s.S
+	s.L = s.L + s.S
+	s.trace(440, "FreeFall")
+	s.W = 3600 * s.V
+	s.trace(510, "FreeFall")
+}
+
+// Verdict grades a landing (lines 05.40-05.83).
+type Verdict int
+
+const (
+	Perfect Verdict = iota
+	Good
+	Poor
+	Damage
+	Crash
+	Lost
+)
+
+var verdicts = [...]string{
+	"PERFECT LANDING !-(LUCKY)",
+	"GOOD LANDING-(COULD BE BETTER)",
+	"CONGRATULATIONS ON A POOR LANDING",
+	"CRAFT DAMAGE. GOOD LUCK",
+	"CRASH LANDING-YOU'VE 5 HRS OXYGEN",
+	"SORRY,BUT THERE WERE NO SURVIVORS-YOU BLEW IT!",
+}
+
+// String returns the message FOCAL types for the verdict.
+func (v Verdict) String() string {
+	return verdicts[v]
+}
+
+// Thresholds are the impact velocities in MPH each verdict but Lost stays
+// below, in ascending order.
+type Thresholds [Lost]float64
+
+// Lunar are the thresholds of lines 05.40-05.81.
+var Lunar = Thresholds{1, 10, 22, 40, 60}
+
+// Judge grades an impact velocity w in MPH. Like 05.40 I (1-W)5.5,5.5 a
+// velocity on a threshold earns the next grade.
+func (th Thresholds) Judge(w float64) Verdict {
+	for i, max := range th {
+		if w < max {
+			return Verdict(i)
+		}
+	}
+	return Lost
+}
+
+// Result summarises a completed flight.
+type Result struct {
+	Time      float64 // L at touchdown (secs)
+	Impact    float64 // W, impact velocity (MPH)
+	Fuel      float64 // M-N at touchdown (lbs)
+	FuelOut   bool    // tanks ran dry before touchdown
+	FuelOutAt float64 // L when the tanks ran dry (secs)
+	Intervals int     // number of K values consumed
+	Verdict   Verdict
+}
+
+// Simulate flies sc until touchdown, asking burn for one K value after the
+// other, starting at 0. Like line 02.72, an invalid burn rate is rejected
+// and the next value is asked for the same interval. The engine stays
+// upright, over the terrain and with the drift of sc if it has them.
+func Simulate(sc Scenario, burn func(interval int) float64) Result {
+	return simulate(sc.Flight(), sc, upright(burn)).Result
+}
+
+// SimulateWith is Simulate with subroutine 9 replaced by in.
+func SimulateWith(sc Scenario, in Integrator, burn func(interval int) float64) Result {
+	f := sc.Flight()
+	f.Integrator = in
+	return simulate(f, sc, upright(burn)).Result
+}
+
+// upright pitches the engine of burn by 0 degrees.
+func upright(burn func(interval int) float64) func(int) (k, p float64) {
+	return func(i int) (float64, float64) {
+		return burn(i), 0
+	}
+}
+
+// simulate flies f by the rules of sc.
+func simulate(f *Flight, sc Scenario, burn func(interval int) (k, p float64)) PlanarResult {
+	var r PlanarResult
+	for {
+		k, p := burn(r.Intervals)
+		r.Intervals++
+		if !sc.Valid(k) || !sc.ValidPitch(p) {
+			continue
+		}
+		switch f.Fly(k, p) {
+		case Flying:
+			continue
+		case FuelOut:
+			r.FuelOut = true
+			r.FuelOutAt = f.L
+			f.FreeFall()
+		}
+		r.Time = f.L
+		r.Impact = f.W
+		r.Fuel = f.Fuel()
+		r.Range, r.Drift, r.Tilt = f.X, f.Drift(), f.P
+		if sc.Site != nil {
+			r.Slope, r.Miss = sc.Site.Profile.Slope(5280*f.X), sc.Site.Miss(f.X)
+		}
+		r.Verdict = f.Verdict()
+		return r
+	}
+}
+
+// Schedule returns a burn function that replays ks and continues with
+// trailing K once ks is exhausted.
+func Schedule(ks []float64, trailing float64) func(int) float64 {
+	return func(i int) float64 {
+		if i < len(ks) {
+			return ks[i]
+		}
+		return trailing
+	}
+}
@@ -0,0 +1,61 @@
+package lunar
+
+import (
+	"math"
+	"testing"
+)
+
+var perfect = []float64{0, 0, 0, 0, 0, 0, 200, 200, 200, 200, 200, 0, 0, 100, 200, 200, 0, 0, 71, 37}
+
+// good is the safe landing sequence of the historical sample output.
+var good = []float64{0, 0, 0, 0, 0, 0, 0, 170, 200, 200, 200, 200, 200, 200, 170, 0, 0, 30, 0, 8, 10, 9, 100}
+
+func near(x, y, eps float64) bool {
+	return math.Abs(x-y) <= eps
+}
+
+func TestPerfectLanding(t *testing.T) {
+	r := Simulate(Classic(), Schedule(perfect, 0))
+	if !near(r.Time, 190.34, .005) || !near(r.Impact, 0.66, .005) || !near(r.Fuel, 277.60, .005) {
+		t.Errorf("want 190.34 secs, 0.66 MPH, 277.60 LBS, got %+v", r)
+	}
+	if r.Verdict != Perfect {
+		t.Errorf("want %q, got %q", Perfect, r.Verdict)
+	}
+}
+
+func TestGoodLandingFuelOut(t *testing.T) {
+	r := Simulate(Classic(), Schedule(good, 0))
+	if !r.FuelOut || !near(r.FuelOutAt, 220.30, .005) {
+		t.Errorf("want fuel out at 220.30 secs, got %+v", r)
+	}
+	if !near(r.Time, 226.11, .005) || !near(r.Impact, 21.35, .005) || r.Fuel != 0 {
+		t.Errorf("want 226.11 secs, 21.35 MPH, no fuel, got %+v", r)
+	}
+	if r.Verdict != Poor {
+		t.Errorf("want %q, got %q", Poor, r.Verdict)
+	}
+}
+
+func TestNotPossible(t *testing.T) {
+	ks := append([]float64{5, 201, -1}, perfect...)
+	r := Simulate(Classic(), Schedule(ks, 0))
+	if r.Intervals != len(perfect)+3 || r.Verdict != Perfect {
+		t.Errorf("want invalid K skipped, got %+v", r)
+	}
+}
+
+func TestJudge(t *testing.T) {
+	tests := []struct {
+		w    float64
+		want Verdict
+	}{
+		{0, Perfect}, {.99, Perfect}, {1, Good}, {1.01, Good}, {9.99, Good}, {10, Poor},
+		{22, Damage}, {40, Crash}, {59.99, Crash}, {60, Lost}, {60.01, Lost},
+	}
+	for _, tt := range tests {
+		if got := Lunar.Judge(tt.w); got != tt.want {
+			t.Errorf("Judge(%g): want %q, got %q", tt.w, tt.want, got)
+		}
+	}
+}
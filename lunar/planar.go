@@ -0,0 +1,257 @@
+package lunar
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+
+	"gitlab.com/jhinrichsen/lunar-lander/typefmt"
+)
+
+// Horizontal extends a scenario to two dimensions: the capsule drifts
+// sideways, and the pilot pitches the engine away from the vertical to
+// cancel the drift, at the cost of vertical thrust. A landing is graded by
+// the worst of impact velocity, horizontal speed and tilt.
+type Horizontal struct {
+	Drift    float64    `json:"drift"`    // U, horizontal velocity (miles/sec)
+	MaxPitch float64    `json:"maxPitch"` // largest pitch either way (degrees)
+	Drifts   Thresholds `json:"drifts"`   // verdict limits of the horizontal speed (MPH)
+	Tilts    Thresholds `json:"tilts"`    // verdict limits of the pitch at touchdown (degrees)
+}
+
+// ClassicHorizontal returns the two-dimensional extension of the classic
+// scenario: 36 MPH of drift, legs that take a few MPH sideways and a tilt
+// of a few degrees.
+func ClassicHorizontal() Horizontal {
+	return Horizontal{
+		Drift:    .01,
+		MaxPitch: 90,
+		Drifts:   Thresholds{1, 3, 6, 12, 30},
+		Tilts:    Thresholds{2, 5, 10, 20, 45},
+	}
+}
+
+// UnmarshalJSON decodes h, fields missing from b keep their classic values.
+func (h *Horizontal) UnmarshalJSON(b []byte) error {
+	type plain Horizontal
+	p := plain(ClassicHorizontal())
+	if err := json.Unmarshal(b, &p); err != nil {
+		return err
+	}
+	*h = Horizontal(p)
+	return nil
+}
+
+// validate checks that the limits of h make sense.
+func (h *Horizontal) validate(check func(ok bool, format string, args ...any)) {
+	check(h.MaxPitch > 0 && h.MaxPitch <= 90, "max pitch %g outside 0..90 degrees", h.MaxPitch)
+	for i := 1; i < len(h.Drifts); i++ {
+		check(h.Drifts[i-1] < h.Drifts[i], "drift thresholds %v not ascending", h.Drifts)
+		check(h.Tilts[i-1] < h.Tilts[i], "tilt thresholds %v not ascending", h.Tilts)
+	}
+}
+
+// Planar is the state of the two-dimensional lander. The vertical motion
+// is the one of the FOCAL program with the thrust constant Z reduced to
+// its vertical part Z*cos(P), so lines 03.10 to 08.30 work unchanged; the
+// horizontal motion takes the sideways part Z*sin(P) of the same burn.
+type Planar struct {
+	State
+	X float64 // Horizontal position (miles)
+	U float64 // Horizontal velocity (miles/sec)
+	P float64 // Pitch of the thrust from vertical towards +X (degrees)
+
+	z    float64 // Z of the scenario
+	x, u float64 // X and U at the end of the step of the last subroutine 9
+	site *Site
+}
+
+// Planar returns the initial two-dimensional state. Without a Horizontal
+// extension the capsule does not drift, without a Site the ground is flat.
+func (sc Scenario) Planar() Planar {
+	p := Planar{State: sc.State(), z: sc.Thrust, site: sc.Site}
+	if sc.Horizontal != nil {
+		p.U = sc.Horizontal.Drift
+	}
+	p.ground()
+	return p
+}
+
+// ground puts the surface of the site under the capsule.
+func (pl *Planar) ground() {
+	if pl.site != nil {
+		pl.Ground = pl.site.Ground(pl.X)
+	}
+}
+
+// ValidPitch reports whether the engine can be pitched by p degrees.
+// Without a Horizontal extension the engine stays upright.
+func (sc Scenario) ValidPitch(p float64) bool {
+	if sc.Horizontal == nil {
+		return p == 0
+	}
+	return math.Abs(p) <= sc.Horizontal.MaxPitch
+}
+
+// planarStep splits subroutine 9 into the vertical and horizontal part of
+// the burn.
+type planarStep struct {
+	p  *Planar
+	in Integrator
+}
+
+// Step implements Integrator.
+func (ps planarStep) Step(s *State) (i, j float64) {
+	in := ps.in
+	if in == nil {
+		in = Taylor{}
+	}
+	// the burn alone: from rest, without gravity, at full thrust; the
+	// ground stays the one under the start of the step
+	b := State{M: s.M, K: s.K, S: s.S, Z: ps.p.z}
+	da, dv := in.Step(&b)
+	sin, cos := math.Sincos(ps.p.P * math.Pi / 180)
+	ps.p.x = ps.p.X + ps.p.U*s.S + sin*da
+	ps.p.u = ps.p.U - sin*dv
+	return s.A - s.G*s.S*s.S/2 - s.V*s.S + cos*da, s.V + s.G*s.S + cos*dv
+}
+
+// Fly burns fuel at rate k with the engine pitched by p degrees for one
+// decision interval of t seconds. The terrain under the capsule counts as
+// level during each step of the integration.
+func (pl *Planar) Fly(k, p, t float64) Event {
+	obs, in := pl.Observe, pl.Integrator
+	defer func() { pl.Observe, pl.Integrator = obs, in }()
+	pl.Observe = func(s *State) {
+		pl.X, pl.U = pl.x, pl.u
+		pl.ground()
+		if obs != nil {
+			obs(s)
+		}
+	}
+	pl.Integrator = planarStep{pl, in}
+	pl.P = p
+	pl.Z = pl.z * math.Cos(p*math.Pi/180)
+	return pl.State.Fly(k, t)
+}
+
+// FreeFall drops the empty capsule to the surface, drifting on. Over
+// terrain it falls again from where the tanks ran dry to the ground under
+// the point of impact until that point settles.
+func (pl *Planar) FreeFall() {
+	from := *pl
+	for range 20 {
+		pl.State = from.State
+		pl.State.FreeFall()
+		x := from.X + from.U*(pl.L-from.L)
+		settled := x == pl.X
+		pl.X = x
+		if pl.site == nil || settled {
+			return
+		}
+		from.Ground = pl.site.Ground(x)
+	}
+}
+
+// Drift returns the horizontal speed in MPH.
+func (pl *Planar) Drift() float64 {
+	return 3600 * math.Abs(pl.U)
+}
+
+// JudgePlanar grades a two-dimensional landing by the worst of the impact
+// velocity w and the horizontal speed h in MPH and the tilt in degrees.
+func (sc Scenario) JudgePlanar(w, h, tilt float64) Verdict {
+	v := sc.Thresholds.Judge(w)
+	if sc.Horizontal != nil {
+		v = max(v, sc.Horizontal.Drifts.Judge(h), sc.Horizontal.Tilts.Judge(math.Abs(tilt)))
+	}
+	return v
+}
+
+// judge grades the landing of pl by impact velocity, drift, tilt and the
+// site.
+func (sc Scenario) judge(pl *Planar) Verdict {
+	v := sc.JudgePlanar(pl.W, pl.Drift(), pl.P)
+	if sc.Site != nil {
+		v = max(v, sc.Site.Judge(pl.X))
+	}
+	return v
+}
+
+// PlanarResult summarises a completed two-dimensional flight.
+type PlanarResult struct {
+	Result
+	Range float64 // X at touchdown (miles)
+	Drift float64 // horizontal speed at touchdown (MPH)
+	Tilt  float64 // pitch at touchdown (degrees)
+	Slope float64 // slope of the ground at touchdown (degrees)
+	Miss  float64 // distance outside the landing zone (feet)
+}
+
+// SimulatePlanar flies sc in two dimensions like Simulate, asking burn for
+// one K and pitch after the other. A burn rate or pitch out of range is
+// rejected and the next pair is asked for the same interval.
+func SimulatePlanar(sc Scenario, burn func(interval int) (k, p float64)) PlanarResult {
+	return simulate(sc.Flight(), sc, burn)
+}
+
+// planarHeader types the column titles of the two-dimensional game.
+func (u Units) planarHeader(w io.Writer) {
+	switch u {
+	case Metric:
+		fmt.Fprintln(w, " TIME    ALTITUDE    VELOCITY  RANGE   DRIFT     FUEL")
+		fmt.Fprintln(w, " SECS   KM+METRES      KM/H   METRES   KM/H       KG   RATE,PITCH")
+	case SI:
+		fmt.Fprintln(w, " TIME    ALTITUDE    VELOCITY  RANGE   DRIFT     FUEL")
+		fmt.Fprintln(w, " SECS     METRES        M/S   METRES    M/S       KG   RATE,PITCH")
+	default:
+		fmt.Fprintln(w, " TIME    ALTITUDE    VELOCITY  RANGE   DRIFT     FUEL")
+		fmt.Fprintln(w, " SECS   MILES+FEET      MPH     FEET     MPH      LBS   RATE,PITCH")
+	}
+}
+
+// planarStatus types a telemetry row of the two-dimensional game: the
+// columns of status narrowed to leave room for range and drift, then the
+// prompt for K.
+func (u Units) planarStatus(t *typefmt.Writer, s *Planar) {
+	var (
+		f3  = typefmt.Format{Width: 3}
+		f4  = typefmt.Format{Width: 4}
+		f5  = typefmt.Format{Width: 5}
+		f8  = typefmt.Format{Width: 8}
+		f51 = typefmt.Format{Width: 5, Digits: 1}
+		f62 = typefmt.Format{Width: 6, Digits: 2}
+		f61 = typefmt.Format{Width: 6, Digits: 1}
+		f72 = typefmt.Format{Width: 7, Digits: 2}
+	)
+	switch u {
+	case Metric:
+		km := math.Trunc(s.A * kmPerMile)
+		t.Type(f3, s.L, " ", km, " ", f4, s.A*MetresPerMile-1000*km,
+			f62, " ", 3600*s.V*kmPerMile, f5, s.X*MetresPerMile,
+			f51, " ", 3600*s.U*kmPerMile, f61, " ", s.Fuel()*KgPerLb)
+	case SI:
+		t.Type(f3, s.L, " ", f8, s.A*MetresPerMile, f72, " ", s.V*MetresPerMile,
+			f5, s.X*MetresPerMile, f51, " ", s.U*MetresPerMile, f61, " ", s.Fuel()*KgPerLb)
+	default:
+		miles := math.Trunc(s.A)
+		t.Type(f3, s.L, " ", miles, " ", f4, 5280*(s.A-miles),
+			f62, " ", 3600*s.V, f5, 5280*s.X, f51, " ", 3600*s.U, f61, " ", s.Fuel())
+	}
+	t.Type("   K=:", f72)
+}
+
+// planarImpact types the horizontal speed and tilt at touchdown after
+// the impact report, h in MPH.
+func (u Units) planarImpact(t *typefmt.Writer, h, tilt float64) {
+	switch u {
+	case Metric:
+		t.Type("DRIFT VELOCITY OF", h*kmPerMile, "KM/H\n")
+	case SI:
+		t.Type("DRIFT VELOCITY OF", h*MetresPerMile/3600, "M/S\n")
+	default:
+		t.Type("DRIFT VELOCITY OF", h, "M.P.H.\n")
+	}
+	t.Type("TILT OF", tilt, " DEGREES\n")
+}
@@ -0,0 +1,139 @@
+package lunar
+
+import (
+	"bytes"
+	"math"
+	"strings"
+	"testing"
+)
+
+func planar() Scenario {
+	sc := Classic()
+	h := ClassicHorizontal()
+	sc.Horizontal = &h
+	return sc
+}
+
+// pitched flies ks upright but for pitch p in interval n.
+func pitched(ks []float64, n int, p float64) func(int) (float64, float64) {
+	return func(i int) (float64, float64) {
+		k := Schedule(ks, 0)(i)
+		if i == n {
+			return k, p
+		}
+		return k, 0
+	}
+}
+
+func TestPlanarUpright(t *testing.T) {
+	sc := planar()
+	for _, ks := range [][]float64{perfect, good} {
+		want := Simulate(sc, Schedule(ks, 0))
+		r := SimulatePlanar(sc, pitched(ks, 0, 0))
+		if r.Time != want.Time || r.Impact != want.Impact || r.Fuel != want.Fuel {
+			t.Errorf("want %+v upright, got %+v", want, r.Result)
+		}
+		if !near(r.Range, sc.Horizontal.Drift*r.Time, 1e-12) || r.Drift != 36 {
+			t.Errorf("want a drift of 36 MPH all the way, got %+v", r)
+		}
+	}
+}
+
+func TestPlanarCancelDrift(t *testing.T) {
+	// leaning 6 degrees back in the first interval of the burn
+	r := SimulatePlanar(planar(), pitched(good, 7, -6))
+	if r.Drift > 1 || r.Tilt != 0 {
+		t.Errorf("want the drift cancelled, got %+v", r)
+	}
+	// the vertical thrust lost to the pitch brakes less
+	if !near(r.Impact, 14.09, .005) || r.Verdict != Poor {
+		t.Errorf("want a poor landing at 14.09 MPH, got %+v", r)
+	}
+}
+
+func TestPlanarSymmetric(t *testing.T) {
+	sc := planar()
+	a, b := sc.Planar(), sc.Planar()
+	a.Fly(200, 30, 10)
+	b.Fly(200, -30, 10)
+	if a.A != b.A || a.V != b.V || a.M != b.M {
+		t.Errorf("pitch direction changes the vertical motion: %+v, %+v", a.State, b.State)
+	}
+	u := sc.Horizontal.Drift
+	if !near(a.U-u, u-b.U, 1e-15) || a.U <= u {
+		t.Errorf("want opposite changes of U, got %g and %g", a.U-u, b.U-u)
+	}
+	// the pitched burn follows the rocket equation on both axes
+	dv := -sc.Thrust * math.Log(1-10*200/sc.Mass)
+	if !near(a.U-u, dv/2, 1e-6) {
+		t.Errorf("want U to gain %g, got %g", dv/2, a.U-u)
+	}
+}
+
+func TestJudgePlanar(t *testing.T) {
+	sc := planar()
+	tests := []struct {
+		w, h, tilt float64
+		want       Verdict
+	}{
+		{.5, .5, 1, Perfect}, {.5, 2, 1, Good}, {.5, .5, -15, Damage},
+		{30, .5, 0, Damage}, {.5, 31, 0, Lost}, {.5, .5, 60, Lost},
+	}
+	for _, tt := range tests {
+		if got := sc.JudgePlanar(tt.w, tt.h, tt.tilt); got != tt.want {
+			t.Errorf("JudgePlanar(%g, %g, %g): want %q, got %q", tt.w, tt.h, tt.tilt, tt.want, got)
+		}
+	}
+	if v := Classic().JudgePlanar(.5, 100, 80); v != Perfect {
+		t.Errorf("want drift and tilt ignored upright, got %q", v)
+	}
+}
+
+func TestReadScenarioHorizontal(t *testing.T) {
+	sc, err := ReadScenario(strings.NewReader(`{"horizontal": {"drift": 0.005, "maxPitch": 45}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := ClassicHorizontal()
+	want.Drift, want.MaxPitch = .005, 45
+	if sc.Horizontal == nil || *sc.Horizontal != want {
+		t.Errorf("want %+v, got %+v", want, sc.Horizontal)
+	}
+	if sc.ValidPitch(50) || !sc.ValidPitch(-45) {
+		t.Error("want pitches up to 45 degrees")
+	}
+	for _, js := range []string{
+		`{"horizontal": {"maxPitch": 100}}`,
+		`{"horizontal": {"tilts": [1, 2, 3, 5, 4]}}`,
+	} {
+		if _, err := ReadScenario(strings.NewReader(js)); err == nil {
+			t.Errorf("%s: want error", js)
+		}
+	}
+}
+
+func TestGamePlanar(t *testing.T) {
+	var in strings.Builder
+	for i := range 21 {
+		if i == 7 {
+			in.WriteString("170\n95\n-6\n")
+			continue
+		}
+		in.WriteString(num(good[i]) + "\n0\n")
+	}
+	in.WriteString("NO\n")
+	var out bytes.Buffer
+	NewGame(planar(), strings.NewReader(in.String()), &out).Run()
+	for _, s := range []string{
+		" SECS   MILES+FEET      MPH     FEET     MPH      LBS   RATE,PITCH\n" +
+			"    0   120      0   3600.00      0    36.0   16000.0   K=: P=:",
+		"   70    47   2904   3852.00   3696    36.0   16000.0   K=: P=:NOT POSSIBLE" +
+			strings.Repeat(".", 51) + "P=:   80",
+		"DRIFT VELOCITY OF     0.39M.P.H.\nTILT OF     0.00 DEGREES\nCONGRATULATIONS ON A POOR LANDING\n",
+		"(ANS. YES OR NO):CONTROL OUT",
+	} {
+		if !strings.Contains(out.String(), s) {
+			t.Errorf("missing %q in\n%s", s, out.String())
+		}
+	}
+}
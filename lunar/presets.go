@@ -0,0 +1,124 @@
+package lunar
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+)
+
+// The FOCAL program measures distance in miles, time in seconds and mass
+// in lbs, so G is a surface gravity in miles/sec² and Z an exhaust velocity
+// in miles/sec.
+const (
+	MetresPerMile = 1609.344
+	KgPerLb       = 0.45359237
+)
+
+// gravity converts a surface gravity from m/s² to miles/sec².
+func gravity(ms2 float64) float64 {
+	return ms2 / MetresPerMile
+}
+
+// presets are scenarios for other gravitational bodies. The classic moon
+// keeps the rounded constants of line 01.50.
+var presets = map[string]Scenario{
+	"moon": Classic(),
+
+	// Mars, 3.721 m/s². Entry 40 miles up after aerobraking, the thin
+	// air is not modelled. Legs are built for rougher fields.
+	"mars": {
+		Altitude: 40,
+		Velocity: .25,
+		Mass:     32500,
+		DryMass:  16500,
+		Gravity:  gravity(3.721),
+		Thrust:   1.8,
+		MinK:     8,
+		MaxK:     200,
+		Interval: 10,
+
+		Thresholds: Thresholds{2, 12, 25, 45, 70},
+	},
+
+	// Europa, 1.315 m/s². Landing on ice, a hard touchdown cracks the
+	// crust, so the verdicts are stricter than on the moon.
+	"europa": {
+		Altitude: 100,
+		Velocity: .8,
+		Mass:     32500,
+		DryMass:  16500,
+		Gravity:  gravity(1.315),
+		Thrust:   1.8,
+		MinK:     8,
+		MaxK:     200,
+		Interval: 10,
+
+		Thresholds: Thresholds{.5, 6, 15, 30, 45},
+	},
+
+	// Ceres, 0.28 m/s², the largest body of the asteroid belt. Little
+	// gravity to hold the capsule, anything faster than a walk bounces off.
+	"asteroid": {
+		Altitude: 20,
+		Velocity: .05,
+		Mass:     20500,
+		DryMass:  16500,
+		Gravity:  gravity(.28),
+		Thrust:   1.8,
+		MinK:     1,
+		MaxK:     50,
+		Interval: 10,
+
+		Thresholds: Thresholds{.5, 2, 5, 10, 20},
+	},
+
+	// Earth test range, 9.80665 m/s². A drop from a carrier aircraft
+	// over the desert with fire crews standing by.
+	"earth": {
+		Altitude: 3,
+		Velocity: .05,
+		Mass:     32500,
+		DryMass:  16500,
+		Gravity:  gravity(9.80665),
+		Thrust:   1.8,
+		MinK:     8,
+		MaxK:     200,
+		Interval: 5,
+
+		Thresholds: Thresholds{3, 12, 25, 40, 60},
+	},
+}
+
+func init() {
+	for name, sc := range presets {
+		sc.Name = name
+		presets[name] = sc
+	}
+}
+
+// Presets returns the names of all preset scenarios in alphabetical order.
+func Presets() []string {
+	return slices.Sorted(maps.Keys(presets))
+}
+
+// Preset returns the preset scenario of a gravitational body.
+func Preset(name string) (Scenario, bool) {
+	sc, ok := presets[name]
+	return sc, ok
+}
+
+// Open returns the preset called name, or else loads the scenario file name.
+// An empty name opens the classic scenario.
+func Open(name string) (Scenario, error) {
+	if name == "" {
+		return Classic(), nil
+	}
+	if sc, ok := Preset(name); ok {
+		return sc, nil
+	}
+	sc, err := LoadScenario(name)
+	if err != nil {
+		return sc, fmt.Errorf("no preset %q and %w", name, err)
+	}
+	return sc, nil
+}
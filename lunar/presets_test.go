@@ -0,0 +1,47 @@
+package lunar
+
+import (
+	"math"
+	"testing"
+)
+
+func TestPresets(t *testing.T) {
+	for _, name := range Presets() {
+		sc, ok := Preset(name)
+		if !ok || sc.Name != name {
+			t.Errorf("preset %q: got %+v", name, sc)
+		}
+		if err := sc.Validate(); err != nil {
+			t.Errorf("preset %q: %v", name, err)
+		}
+	}
+}
+
+// TestMoonGravity checks the unit conversion against the rounded G of
+// line 01.50.
+func TestMoonGravity(t *testing.T) {
+	if g := gravity(1.62); math.Abs(g-.001) > 1e-5 {
+		t.Errorf("want 1.62 m/s² close to .001 miles/sec², got %g", g)
+	}
+}
+
+func TestPresetVerdicts(t *testing.T) {
+	mars, _ := Preset("mars")
+	if got := mars.Thresholds.Judge(11); got != Good {
+		t.Errorf("want 11 MPH good on mars, got %q", got)
+	}
+	if got := Lunar.Judge(11); got != Poor {
+		t.Errorf("want 11 MPH poor on the moon, got %q", got)
+	}
+}
+
+func TestOpen(t *testing.T) {
+	for _, name := range []string{"", "earth", "../scenarios/classic.json", "../scenarios/high-gate.json", "../scenarios/crosswind.json", "../scenarios/tranquility.json", "../scenarios/malfunction.json"} {
+		if _, err := Open(name); err != nil {
+			t.Errorf("%q: %v", name, err)
+		}
+	}
+	if _, err := Open("pluto"); err == nil {
+		t.Error("want error for unknown preset")
+	}
+}
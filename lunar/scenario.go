@@ -0,0 +1,189 @@
+package lunar
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+// Scenario describes the initial conditions and rules of a flight, i.e.
+// everything lines 01.50, 02.20 and 02.70 hard-code.
+type Scenario struct {
+	Name     string  `json:"name"`
+	Altitude float64 `json:"altitude"` // A (miles)
+	Velocity float64 `json:"velocity"` // V (miles/sec, positive down)
+	Mass     float64 `json:"mass"`     // M, capsule plus fuel (lbs)
+	DryMass  float64 `json:"dryMass"`  // N (lbs)
+	Gravity  float64 `json:"gravity"`  // G (miles/sec²)
+	Thrust   float64 `json:"thrust"`   // Z (miles/sec)
+	MinK     float64 `json:"minK"`     // smallest burn rate besides 0 (lbs/sec)
+	MaxK     float64 `json:"maxK"`     // largest burn rate (lbs/sec)
+	Interval float64 `json:"interval"` // T, decision interval (secs)
+
+	Thresholds Thresholds `json:"thresholds"` // verdict limits (MPH)
+
+	// Horizontal, if set, flies the scenario in two dimensions.
+	Horizontal *Horizontal `json:"horizontal,omitempty"`
+	// Site, if set, puts terrain and a landing zone under the track.
+	Site *Site `json:"site,omitempty"`
+
+	// Malfunctions, if set, strike during the descent.
+	Malfunctions *Malfunctions `json:"malfunctions,omitempty"`
+}
+
+// The range of the decision interval in whole seconds, the unit of the
+// time column of the status rows. Line 02.20 sets T=10.
+const (
+	MinInterval = 1
+	MaxInterval = 60
+)
+
+// Classic returns the scenario of lunar-lander.fc.
+func Classic() Scenario {
+	return Scenario{
+		Name:     "classic",
+		Altitude: 120,
+		Velocity: 1,
+		Mass:     32500,
+		DryMass:  16500,
+		Gravity:  .001,
+		Thrust:   1.8,
+		MinK:     8,
+		MaxK:     200,
+		Interval: 10,
+
+		Thresholds: Lunar,
+	}
+}
+
+// State returns the initial state (line 01.50).
+func (sc Scenario) State() State {
+	return State{
+		A: sc.Altitude,
+		V: sc.Velocity,
+		M: sc.Mass,
+		N: sc.DryMass,
+		G: sc.Gravity,
+		Z: sc.Thrust,
+	}
+}
+
+// Valid reports whether k is an acceptable burn rate (line 02.70).
+func (sc Scenario) Valid(k float64) bool {
+	return k == 0 || (k >= sc.MinK && k <= sc.MaxK)
+}
+
+// Validate checks that a flight of sc is physically meaningful.
+func (sc Scenario) Validate() error {
+	var errs []error
+	check := func(ok bool, format string, args ...any) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+	check(sc.Altitude > 0, "altitude %g must be positive", sc.Altitude)
+	check(sc.DryMass > 0, "dry mass %g must be positive", sc.DryMass)
+	check(sc.Mass >= sc.DryMass, "mass %g below dry mass %g", sc.Mass, sc.DryMass)
+	check(sc.Gravity > 0, "gravity %g must be positive", sc.Gravity)
+	check(sc.Thrust > 0, "thrust %g must be positive", sc.Thrust)
+	check(sc.MinK > 0 && sc.MinK <= sc.MaxK, "K range %g..%g is empty", sc.MinK, sc.MaxK)
+	check(sc.Interval >= MinInterval && sc.Interval <= MaxInterval && sc.Interval == math.Trunc(sc.Interval),
+		"interval %g not a whole number of secs in %d..%d", sc.Interval, MinInterval, MaxInterval)
+	for i := 1; i < len(sc.Thresholds); i++ {
+		check(sc.Thresholds[i-1] < sc.Thresholds[i], "thresholds %v not ascending", sc.Thresholds)
+	}
+	if sc.Horizontal != nil {
+		sc.Horizontal.validate(check)
+	}
+	if sc.Site != nil {
+		sc.Site.validate(check)
+		check(sc.Altitude > sc.Site.Ground(0), "altitude %g below the terrain", sc.Altitude)
+	}
+	if sc.Malfunctions != nil {
+		sc.Malfunctions.validate(check)
+	}
+	return errors.Join(errs...)
+}
+
+// ReadScenario decodes a JSON scenario. Fields missing from r keep their
+// classic values. A terrain file is read relative to the working directory.
+func ReadScenario(r io.Reader) (Scenario, error) {
+	return readScenario(r, ".")
+}
+
+// readScenario decodes a JSON scenario with files relative to dir.
+func readScenario(r io.Reader, dir string) (Scenario, error) {
+	sc := Classic()
+	sc.Name = ""
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&sc); err != nil {
+		return sc, err
+	}
+	if sc.Site != nil {
+		if err := sc.Site.load(dir); err != nil {
+			return sc, err
+		}
+	}
+	return sc, sc.Validate()
+}
+
+// LoadScenario reads a JSON scenario file. A terrain file is read relative
+// to the scenario file.
+func LoadScenario(filename string) (Scenario, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return Scenario{}, err
+	}
+	defer f.Close()
+	sc, err := readScenario(f, filepath.Dir(filename))
+	if err != nil {
+		return sc, fmt.Errorf("%s: %w", filename, err)
+	}
+	return sc, nil
+}
+
+// Randomize returns a practice variant of sc: altitude, velocity and fuel
+// vary by up to ±20 percent. The same seed always yields the same variant.
+func Randomize(sc Scenario, seed uint64) Scenario {
+	rng := rand.New(rand.NewPCG(seed, seed))
+	vary := func(x float64) float64 {
+		return x * (0.8 + 0.4*rng.Float64())
+	}
+	sc.Name = fmt.Sprintf("%s-%d", sc.Name, seed)
+	sc.Altitude = vary(sc.Altitude)
+	sc.Velocity = vary(sc.Velocity)
+	sc.Mass = sc.DryMass + math.Round(vary(sc.Mass-sc.DryMass))
+	return sc
+}
+
+// FreeFallTime returns the time until impact without burning fuel.
+func (sc Scenario) FreeFallTime() float64 {
+	v, a, g := sc.Velocity, sc.Altitude, sc.Gravity
+	return (math.Sqrt(v*v+2*a*g) - v) / g
+}
+
+// num prints x the short way, 10 rather than 10.000.
+func num(x float64) string {
+	return strconv.FormatFloat(x, 'f', -1, 64)
+}
+
+// Intro writes the briefing of lines 01.04-01.11 with the numbers of sc.
+// The free fall impact time is an estimate, rounded up to ten seconds.
+func (sc Scenario) Intro(w io.Writer) {
+	fmt.Fprintln(w, "CONTROL CALLING LUNAR MODULE. MANUAL CONTROL IS NECESSARY")
+	fmt.Fprintf(w, "YOU MAY RESET FUEL RATE K EACH %s SECS TO 0 OR ANY VALUE\n", num(sc.Interval))
+	fmt.Fprintf(w, "BETWEEN %s & %s LBS/SEC. YOU'VE %.0f LBS FUEL. ESTIMATED\n",
+		num(sc.MinK), num(sc.MaxK), sc.Mass-sc.DryMass)
+	fmt.Fprintf(w, "FREE FALL IMPACT TIME-%.0f SECS. CAPSULE WEIGHT-%.0f LBS\n",
+		math.Ceil(sc.FreeFallTime()/10)*10, sc.Mass)
+	if sc.Site != nil {
+		sc.Site.intro(w)
+	}
+}
@@ -0,0 +1,78 @@
+package lunar
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestClassicIntro(t *testing.T) {
+	const want = "CONTROL CALLING LUNAR MODULE. MANUAL CONTROL IS NECESSARY\n" +
+		"YOU MAY RESET FUEL RATE K EACH 10 SECS TO 0 OR ANY VALUE\n" +
+		"BETWEEN 8 & 200 LBS/SEC. YOU'VE 16000 LBS FUEL. ESTIMATED\n" +
+		"FREE FALL IMPACT TIME-120 SECS. CAPSULE WEIGHT-32500 LBS\n"
+	var buf bytes.Buffer
+	Classic().Intro(&buf)
+	if got := buf.String(); got != want {
+		t.Errorf("want\n%s\ngot\n%s", want, got)
+	}
+}
+
+func TestIntroRecomputed(t *testing.T) {
+	sc := Classic()
+	sc.Altitude = 30
+	sc.Mass = 20500
+	sc.Interval = 5
+	var buf bytes.Buffer
+	sc.Intro(&buf)
+	for _, want := range []string{"EACH 5 SECS", "YOU'VE 4000 LBS", "TIME-30 SECS", "WEIGHT-20500 LBS"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("want %q in\n%s", want, buf.String())
+		}
+	}
+}
+
+func TestReadScenario(t *testing.T) {
+	sc, err := ReadScenario(strings.NewReader(`{"name": "short", "altitude": 60, "interval": 5}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Classic()
+	want.Name, want.Altitude, want.Interval = "short", 60, 5
+	if sc != want {
+		t.Errorf("want %+v, got %+v", want, sc)
+	}
+}
+
+func TestReadScenarioInvalid(t *testing.T) {
+	for _, js := range []string{
+		`{"mass": 100}`,
+		`{"minK": 300}`,
+		`{"fuel": 100}`,
+		`{"interval": 0}`,
+		`{"interval": 0.5}`,
+		`{"interval": 2.5}`,
+		`{"interval": 61}`,
+		`{"thresholds": [1, 10, 22, 40, 30]}`,
+	} {
+		if _, err := ReadScenario(strings.NewReader(js)); err == nil {
+			t.Errorf("%s: want error", js)
+		}
+	}
+}
+
+func TestRandomize(t *testing.T) {
+	a, b := Randomize(Classic(), 42), Randomize(Classic(), 42)
+	if a != b {
+		t.Errorf("same seed, different scenarios: %+v, %+v", a, b)
+	}
+	if c := Randomize(Classic(), 43); a == c {
+		t.Error("different seeds, same scenario")
+	}
+	if err := a.Validate(); err != nil {
+		t.Error(err)
+	}
+	if a.Altitude < 96 || a.Altitude > 144 {
+		t.Errorf("altitude %g out of ±20%%", a.Altitude)
+	}
+}
@@ -0,0 +1,83 @@
+package lunar
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+)
+
+// Sample is one telemetry reading in the units of the FOCAL TYPE statements.
+type Sample struct {
+	Time     float64 // L (secs)
+	Altitude float64 // A (miles)
+	Velocity float64 // 3600*V (MPH)
+	Fuel     float64 // M-N (lbs)
+	K        float64 // burn rate (lbs/sec)
+}
+
+func sample(s *State) Sample {
+	return Sample{Time: s.L, Altitude: s.A, Velocity: 3600 * s.V, Fuel: s.Fuel(), K: s.K}
+}
+
+// Record flies sc like Simulate and returns a sample after every step of
+// the integration, starting with the initial state and ending on the
+// surface.
+func Record(sc Scenario, burn func(interval int) float64) ([]Sample, Result) {
+	f := sc.Flight()
+	ss := []Sample{sample(&f.State)}
+	f.Observe = func(s *State) {
+		ss = append(ss, sample(s))
+	}
+	r := simulate(f, sc, upright(burn)).Result
+	if last := ss[len(ss)-1]; last.Time < r.Time {
+		// Free fall after the tanks ran dry (line 04.40).
+		ss = append(ss, Sample{Time: r.Time, Altitude: f.Ground, Velocity: r.Impact, Fuel: r.Fuel})
+	}
+	return ss, r
+}
+
+var telemetryHeader = []string{"time", "altitude", "velocity", "fuel", "k"}
+
+// WriteTelemetry writes samples as CSV with a header line.
+func WriteTelemetry(w io.Writer, ss []Sample) error {
+	cw := csv.NewWriter(w)
+	cw.Write(telemetryHeader)
+	for _, s := range ss {
+		rec := make([]string, 0, len(telemetryHeader))
+		for _, f := range []float64{s.Time, s.Altitude, s.Velocity, s.Fuel, s.K} {
+			rec = append(rec, strconv.FormatFloat(f, 'g', -1, 64))
+		}
+		cw.Write(rec)
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// ReadTelemetry reads samples written by WriteTelemetry.
+func ReadTelemetry(r io.Reader) ([]Sample, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = len(telemetryHeader)
+	recs, err := cr.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(recs) == 0 {
+		return nil, fmt.Errorf("telemetry: missing header")
+	}
+	var ss []Sample
+	for i, rec := range recs[1:] {
+		var fs [5]float64
+		for j, field := range rec {
+			if fs[j], err = strconv.ParseFloat(field, 64); err != nil {
+				return nil, fmt.Errorf("telemetry line %d: %w", i+2, err)
+			}
+			if math.IsNaN(fs[j]) || math.IsInf(fs[j], 0) {
+				return nil, fmt.Errorf("telemetry line %d: %s %q not finite", i+2, telemetryHeader[j], field)
+			}
+		}
+		ss = append(ss, Sample{fs[0], fs[1], fs[2], fs[3], fs[4]})
+	}
+	return ss, nil
+}
@@ -0,0 +1,51 @@
+package lunar
+
+import (
+	"bytes"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestRecord(t *testing.T) {
+	ss, r := Record(Classic(), Schedule(good, 0))
+	if first := ss[0]; first.Time != 0 || first.Altitude != 120 || first.Velocity != 3600 {
+		t.Errorf("want initial state first, got %+v", first)
+	}
+	last := ss[len(ss)-1]
+	if last.Time != r.Time || last.Altitude != 0 || last.Velocity != r.Impact {
+		t.Errorf("want touchdown last, got %+v for %+v", last, r)
+	}
+	for i := 1; i < len(ss); i++ {
+		if ss[i].Time <= ss[i-1].Time {
+			t.Fatalf("time not increasing at sample %d: %+v", i, ss[i-1:i+1])
+		}
+	}
+}
+
+func TestTelemetryRoundTrip(t *testing.T) {
+	ss, _ := Record(Classic(), Schedule(perfect, 0))
+	var buf bytes.Buffer
+	if err := WriteTelemetry(&buf, ss); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(buf.String(), "time,altitude,velocity,fuel,k\n") {
+		t.Errorf("want header, got %q", buf.String()[:40])
+	}
+	got, err := ReadTelemetry(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(got, ss) {
+		t.Error("samples differ after round trip")
+	}
+}
+
+func TestReadTelemetryInvalid(t *testing.T) {
+	for _, s := range []string{"", "time,altitude,velocity,fuel,k\n1,2,3,4\n", "time,altitude,velocity,fuel,k\n1,2,x,4,5\n",
+		"time,altitude,velocity,fuel,k\n1,NaN,3,4,5\n", "time,altitude,velocity,fuel,k\n1,2,-Inf,4,5\n"} {
+		if _, err := ReadTelemetry(strings.NewReader(s)); err == nil {
+			t.Errorf("%q: want error", s)
+		}
+	}
+}
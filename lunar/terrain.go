@@ -0,0 +1,209 @@
+package lunar
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gitlab.com/jhinrichsen/lunar-lander/typefmt"
+)
+
+// Point is a sample of a terrain profile, both in feet.
+type Point struct {
+	X float64 // distance along the track
+	H float64 // height of the surface above the plane A=0
+}
+
+// Profile is the height of the terrain along the track, linearly
+// interpolated between its points and level beyond its ends.
+type Profile []Point
+
+// ReadProfile reads a profile, one point per line: distance along the
+// track and height, in feet, separated by blanks. Distances must ascend.
+// Blank lines and lines starting with # are ignored.
+func ReadProfile(r io.Reader) (Profile, error) {
+	var p Profile
+	sc := bufio.NewScanner(r)
+	for n := 1; sc.Scan(); n++ {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		f := strings.Fields(line)
+		if len(f) != 2 {
+			return nil, fmt.Errorf("line %d: want distance and height, got %q", n, line)
+		}
+		x, err := strconv.ParseFloat(f[0], 64)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", n, err)
+		}
+		h, err := strconv.ParseFloat(f[1], 64)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", n, err)
+		}
+		if len(p) > 0 && x <= p[len(p)-1].X {
+			return nil, fmt.Errorf("line %d: distance %g does not ascend", n, x)
+		}
+		p = append(p, Point{x, h})
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	if len(p) == 0 {
+		return nil, errors.New("empty profile")
+	}
+	return p, nil
+}
+
+// LoadProfile reads a profile file.
+func LoadProfile(filename string) (Profile, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	p, err := ReadProfile(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", filename, err)
+	}
+	return p, nil
+}
+
+// segment returns the index i of the points p[i-1], p[i] around x, 0 or
+// len(p) beyond the ends.
+func (p Profile) segment(x float64) int {
+	return sort.Search(len(p), func(i int) bool { return p[i].X > x })
+}
+
+// Height returns the height in feet at x feet along the track, 0 for an
+// empty profile.
+func (p Profile) Height(x float64) float64 {
+	i := p.segment(x)
+	switch {
+	case len(p) == 0:
+		return 0
+	case i == 0:
+		return p[0].H
+	case i == len(p):
+		return p[i-1].H
+	}
+	a, b := p[i-1], p[i]
+	return a.H + (b.H-a.H)*(x-a.X)/(b.X-a.X)
+}
+
+// Slope returns the slope in degrees at x feet along the track, rising
+// towards +X if positive.
+func (p Profile) Slope(x float64) float64 {
+	i := p.segment(x)
+	if i == 0 || i == len(p) {
+		return 0
+	}
+	a, b := p[i-1], p[i]
+	return math.Atan((b.H-a.H)/(b.X-a.X)) * 180 / math.Pi
+}
+
+// Site is the terrain under the track and the designated landing zone. A
+// landing is graded by the worst of the verdicts of the scenario and of
+// the slope and the distance to the zone at the point of touchdown.
+type Site struct {
+	// Terrain names the profile file, relative to the scenario file.
+	Terrain string  `json:"terrain"`
+	Profile Profile `json:"-"`
+
+	Zone   [2]float64 `json:"zone"`   // first and last foot of the landing zone along the track
+	Slopes Thresholds `json:"slopes"` // verdict limits of the slope (degrees)
+	Misses Thresholds `json:"misses"` // verdict limits of the distance outside the zone (feet)
+}
+
+// ClassicSite returns a site with the limits of Apollo: the legs take a
+// slope of 12 degrees, and a landing close to the zone is still good. A
+// miss of less than a foot counts as inside the zone.
+// Terrain and zone are left to the scenario.
+func ClassicSite() Site {
+	return Site{
+		Slopes: Thresholds{2, 6, 12, 20, 30},
+		Misses: Thresholds{1, 200, 1000, 3000, 10000},
+	}
+}
+
+// UnmarshalJSON decodes s, fields missing from b keep their classic values.
+func (s *Site) UnmarshalJSON(b []byte) error {
+	type plain Site
+	p := plain(ClassicSite())
+	if err := json.Unmarshal(b, &p); err != nil {
+		return err
+	}
+	*s = Site(p)
+	return nil
+}
+
+// load reads the terrain file of s relative to dir.
+func (s *Site) load(dir string) error {
+	if s.Terrain == "" {
+		return nil
+	}
+	name := s.Terrain
+	if !filepath.IsAbs(name) {
+		name = filepath.Join(dir, name)
+	}
+	p, err := LoadProfile(name)
+	s.Profile = p
+	return err
+}
+
+// validate checks that zone and limits of s make sense.
+func (s *Site) validate(check func(ok bool, format string, args ...any)) {
+	check(s.Zone[0] < s.Zone[1], "landing zone %g..%g is empty", s.Zone[0], s.Zone[1])
+	for i := 1; i < len(s.Slopes); i++ {
+		check(s.Slopes[i-1] < s.Slopes[i], "slope thresholds %v not ascending", s.Slopes)
+		check(s.Misses[i-1] < s.Misses[i], "miss thresholds %v not ascending", s.Misses)
+	}
+}
+
+// Ground returns the height of the surface in miles x miles along the
+// track, the unit of A.
+func (s *Site) Ground(x float64) float64 {
+	return s.Profile.Height(x*5280) / 5280
+}
+
+// Miss returns how many feet x miles along the track lies outside the
+// landing zone, 0 inside.
+func (s *Site) Miss(x float64) float64 {
+	ft := x * 5280
+	return max(0, s.Zone[0]-ft, ft-s.Zone[1])
+}
+
+// Judge grades the point of touchdown x miles along the track.
+func (s *Site) Judge(x float64) Verdict {
+	return max(s.Slopes.Judge(math.Abs(s.Profile.Slope(x*5280))), s.Misses.Judge(s.Miss(x)))
+}
+
+// intro types the landing zone after the briefing.
+func (s *Site) intro(w io.Writer) {
+	fmt.Fprintf(w, "LANDING ZONE %s TO %s FT DOWNRANGE\n", num(s.Zone[0]), num(s.Zone[1]))
+}
+
+// siteReport types the point of touchdown after the impact report, x in
+// miles along the track. A miss the verdict forgives, below Misses[0],
+// counts as inside the zone.
+func (u Units) siteReport(t *typefmt.Writer, s *Site, x float64) {
+	ft, unit := 5280*x, " FT"
+	scale := 1.0
+	if u != Imperial {
+		scale, unit = metresPerFoot, " M"
+	}
+	t.Type("LANDED", ft*scale, unit, " DOWNRANGE ON A", math.Abs(s.Profile.Slope(ft)), " DEG SLOPE\n")
+	if miss := s.Miss(x); miss >= s.Misses[0] {
+		t.Type(miss*scale, unit, " OUTSIDE THE LANDING ZONE\n")
+		return
+	}
+	t.Type("INSIDE THE LANDING ZONE\n")
+}
@@ -0,0 +1,173 @@
+package lunar
+
+import (
+	"bytes"
+	"math"
+	"strings"
+	"testing"
+
+	"gitlab.com/jhinrichsen/lunar-lander/typefmt"
+)
+
+func TestReadProfile(t *testing.T) {
+	p, err := ReadProfile(strings.NewReader("# x h\n0 100\n\n1000\t0\n2000 1000\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct{ x, h, slope float64 }{
+		{-500, 100, 0}, {0, 100, -5.710593137499643}, {500, 50, -5.710593137499643},
+		{1500, 500, 45}, {2000, 1000, 0}, {9000, 1000, 0},
+	}
+	for _, tt := range tests {
+		if h, s := p.Height(tt.x), p.Slope(tt.x); h != tt.h || !near(s, tt.slope, 1e-12) {
+			t.Errorf("at %g: want height %g slope %g, got %g %g", tt.x, tt.h, tt.slope, h, s)
+		}
+	}
+	for _, s := range []string{"", "# only\n", "0 1 2\n", "0 x\n", "0 0\n0 1\n"} {
+		if _, err := ReadProfile(strings.NewReader(s)); err == nil {
+			t.Errorf("%q: want error", s)
+		}
+	}
+}
+
+func TestSiteJudge(t *testing.T) {
+	s := ClassicSite()
+	s.Zone = [2]float64{1000, 2000}
+	s.Profile = Profile{{0, 0}, {1500, 0}, {1600, 10}, {3000, 10}}
+	tests := []struct {
+		ft, miss float64
+		want     Verdict
+	}{
+		{1200, 0, Perfect}, {1550, 0, Good}, {2100, 100, Good},
+		{500, 500, Poor}, {4000, 2000, Damage}, {-8000, 9000, Crash}, {-9000, 10000, Lost},
+	}
+	for _, tt := range tests {
+		x := tt.ft / 5280
+		if m, v := s.Miss(x), s.Judge(x); !near(m, tt.miss, 1e-9) || v != tt.want {
+			t.Errorf("at %g ft: want %g ft outside, %q, got %g, %q", tt.ft, tt.miss, tt.want, m, v)
+		}
+	}
+}
+
+// TestSiteReport checks that the report agrees with the verdict on a miss
+// below a foot.
+func TestSiteReport(t *testing.T) {
+	s := ClassicSite()
+	s.Zone = [2]float64{1000, 2000}
+	tests := []struct {
+		ft   float64
+		want string
+		v    Verdict
+	}{
+		{1500, "INSIDE THE LANDING ZONE\n", Perfect},
+		{2000.5, "INSIDE THE LANDING ZONE\n", Perfect},
+		{2001, "    1.0000 FT OUTSIDE THE LANDING ZONE\n", Good},
+	}
+	for _, tt := range tests {
+		var out bytes.Buffer
+		x := tt.ft / 5280
+		Imperial.siteReport(typefmt.NewWriter(&out), &s, x)
+		if !strings.HasSuffix(out.String(), tt.want) || s.Judge(x) != tt.v {
+			t.Errorf("at %g ft: want %q, %q, got %q, %q", tt.ft, tt.want, tt.v, out.String(), s.Judge(x))
+		}
+	}
+}
+
+func TestTerrainTouchdown(t *testing.T) {
+	sc := Classic()
+	sc.Site = &Site{Profile: Profile{{0, 1000}}, Zone: [2]float64{-1, 1}, Slopes: Lunar, Misses: Lunar}
+	s := sc.Planar()
+	if s.Ground != 1000./5280 {
+		t.Fatalf("want ground at 1000 ft, got %g miles", s.Ground)
+	}
+	var ev Event
+	for i := 0; ev == Flying; i++ {
+		ev = s.Fly(Schedule(good, 0)(i), 0, sc.Interval)
+	}
+	if ev != Landed || math.Abs(s.A-s.Ground)*5280 > .01 {
+		t.Errorf("want touchdown on the plateau, got %v at %g ft", ev, s.A*5280)
+	}
+	// the plateau comes a few seconds sooner than the plain
+	if r := SimulatePlanar(sc, pitched(good, 0, 0)); r.Time > 226 || r.Verdict == Perfect {
+		t.Errorf("want an earlier and harder landing, got %+v", r)
+	}
+}
+
+func TestTerrainFreeFall(t *testing.T) {
+	sc := planar()
+	// rising one foot in two from the start of the track
+	sc.Site = &Site{Profile: Profile{{0, 0}, {5280, 2640}}, Zone: [2]float64{0, 1}, Slopes: Lunar, Misses: Lunar}
+	s := sc.Planar()
+	s.A, s.V = 1, 0
+	s.FreeFall()
+	if flat := math.Sqrt(2 / s.G); !(s.L < flat) {
+		t.Errorf("want the fall shorter than %g secs on the plain, got %g", flat, s.L)
+	}
+	if !near(s.X, s.U*s.L, 1e-12) {
+		t.Errorf("want to drift on during the fall, got X=%g after %g secs", s.X, s.L)
+	}
+	if a := 1 - s.G*s.L*s.L/2; !near(a, s.X/2, 1e-9) {
+		t.Errorf("want to end on the slope at %g miles, got %g", s.X/2, a)
+	}
+}
+
+func TestLoadScenarioSite(t *testing.T) {
+	sc, err := LoadScenario("../scenarios/tranquility.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sc.Site == nil || len(sc.Site.Profile) == 0 || sc.Site.Zone != [2]float64{9000, 11000} || sc.Site.Slopes != ClassicSite().Slopes {
+		t.Fatalf("want the tranquility site, got %+v", sc.Site)
+	}
+	for _, js := range []string{
+		`{"site": {"terrain": "missing.terrain", "zone": [0, 1]}}`,
+		`{"site": {"zone": [1, 0]}}`,
+		`{"altitude": 0.1, "site": {"terrain": "../scenarios/tranquility.terrain", "zone": [0, 1]}}`,
+	} {
+		if _, err := ReadScenario(strings.NewReader(js)); err == nil {
+			t.Errorf("%s: want error", js)
+		}
+	}
+}
+
+func TestGameSite(t *testing.T) {
+	sc, err := LoadScenario("../scenarios/tranquility.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var in strings.Builder
+	for _, k := range good {
+		in.WriteString(num(k) + "\n0\n")
+	}
+	in.WriteString("NO\n")
+	var out bytes.Buffer
+	NewGame(sc, strings.NewReader(in.String()), &out).Run()
+	for _, s := range []string{
+		"CAPSULE WEIGHT-32500 LBS\nLANDING ZONE 9000 TO 11000 FT DOWNRANGE\n",
+		"LANDED  11566.52 FT DOWNRANGE ON A    23.43 DEG SLOPE\n   566.52 FT OUTSIDE THE LANDING ZONE\n",
+		"SORRY,BUT THERE WERE NO SURVIVORS-YOU BLEW IT!\n",
+	} {
+		if !strings.Contains(out.String(), s) {
+			t.Errorf("missing %q in\n%s", s, out.String())
+		}
+	}
+}
+
+// TestSimulateSite flies the tranquility site through Simulate as the
+// tools do and through SimulatePlanar as the game does.
+func TestSimulateSite(t *testing.T) {
+	sc, err := LoadScenario("../scenarios/tranquility.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ks := []float64{0, 0, 0, 0, 0, 0, 0, 164.31}
+	r := Simulate(sc, Schedule(ks, 200))
+	p := SimulatePlanar(sc, func(i int) (float64, float64) { return Schedule(ks, 200)(i), 0 })
+	if r != p.Result || r.Verdict != Lost {
+		t.Errorf("want the same loss both ways, got %+v and %+v", r, p.Result)
+	}
+	// the softest landing landing-window finds on the plane
+	if !near(p.Range*5280, 7747, 1) || !near(p.Slope, -11.31, .01) || !near(p.Miss, 1253, 1) {
+		t.Errorf("want about 7747 ft downrange on an 11.31 deg slope, got %+v", p)
+	}
+}
@@ -0,0 +1,103 @@
+package lunar
+
+import (
+	"fmt"
+	"io"
+	"math"
+
+	"gitlab.com/jhinrichsen/lunar-lander/typefmt"
+)
+
+// Units selects how the game displays telemetry. The simulation itself
+// always runs in the miles, seconds and lbs of the FOCAL program.
+type Units int
+
+const (
+	// Imperial prints miles+feet, MPH and LBS like the FOCAL TYPE statements.
+	Imperial Units = iota
+	// Metric prints kilometres+metres, km/h and kg.
+	Metric
+	// SI prints metres, m/s and kg.
+	SI
+)
+
+var unitNames = [...]string{"imperial", "metric", "si"}
+
+func (u Units) String() string {
+	return unitNames[u]
+}
+
+// ParseUnits returns the units called name.
+func ParseUnits(name string) (Units, error) {
+	for i, s := range unitNames {
+		if s == name {
+			return Units(i), nil
+		}
+	}
+	return Imperial, fmt.Errorf("unknown units %q, want imperial, metric or si", name)
+}
+
+const (
+	metresPerFoot = .3048
+	kmPerMile     = MetresPerMile / 1000
+)
+
+// header types the column titles of line 01.30-01.40.
+func (u Units) header(w io.Writer) {
+	switch u {
+	case Metric:
+		fmt.Fprintln(w, "TIME,SECS   ALTITUDE,KM+METRES    VELOCITY,KM/H  FUEL,KG    FUEL RATE")
+	case SI:
+		fmt.Fprintln(w, "TIME,SECS   ALTITUDE,METRES   VELOCITY,M/S   FUEL,KG   FUEL RATE")
+	default:
+		fmt.Fprintln(w, "TIME,SECS   ALTITUDE,MILES+FEET   VELOCITY,MPH   FUEL,LBS   FUEL RATE")
+	}
+}
+
+// status types a telemetry row (lines 02.10-02.20) and sets the %7.02 of
+// line 02.70 for the landing report.
+func (u Units) status(t *typefmt.Writer, s *State) {
+	var (
+		f3  = typefmt.Format{Width: 3}
+		f4  = typefmt.Format{Width: 4}
+		f6  = typefmt.Format{Width: 6}
+		f62 = typefmt.Format{Width: 6, Digits: 2}
+		f61 = typefmt.Format{Width: 6, Digits: 1}
+		f72 = typefmt.Format{Width: 7, Digits: 2}
+	)
+	switch u {
+	case Metric:
+		km := math.Trunc(s.A * kmPerMile)
+		t.Type("    ", f3, s.L, "       ", km, "  ", f4, s.A*MetresPerMile-1000*km,
+			f62, "       ", 3600*s.V*kmPerMile, "    ", f61, s.Fuel()*KgPerLb)
+	case SI:
+		t.Type("    ", f3, s.L, "          ", f6, s.A*MetresPerMile,
+			f72, "      ", s.V*MetresPerMile, "  ", f61, s.Fuel()*KgPerLb)
+	default:
+		miles := math.Trunc(s.A)
+		t.Type("    ", f3, s.L, "       ", miles, "  ", f4, 5280*(s.A-miles),
+			f62, "       ", 3600*s.V, "    ", f61, s.Fuel())
+	}
+	t.Type("      K=:", f72)
+}
+
+// impact types velocity and fuel at touchdown (line 05.20), w in MPH.
+func (u Units) impact(t *typefmt.Writer, w, fuel float64) {
+	switch u {
+	case Metric:
+		t.Type("IMPACT VELOCITY OF", w*kmPerMile, "KM/H\n", "FUEL LEFT:", fuel*KgPerLb, " KG\n")
+	case SI:
+		t.Type("IMPACT VELOCITY OF", w*MetresPerMile/3600, "M/S\n", "FUEL LEFT:", fuel*KgPerLb, " KG\n")
+	default:
+		t.Type("IMPACT VELOCITY OF", w, "M.P.H.\n", "FUEL LEFT:", fuel, " LBS\n")
+	}
+}
+
+// crater types the crater depth of line 05.83, ft in feet.
+func (u Units) crater(t *typefmt.Writer, ft float64) {
+	if u == Imperial {
+		t.Type("IN FACT YOU BLASTED A NEW LUNAR CRATER", ft, " FT.DEEP\n")
+		return
+	}
+	t.Type("IN FACT YOU BLASTED A NEW LUNAR CRATER", ft*metresPerFoot, " M DEEP\n")
+}
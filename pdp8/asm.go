@@ -0,0 +1,294 @@
+package pdp8
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// symbols are the permanent symbols of PAL-8: memory references, the
+// I/O transfers Disassemble knows and the operate microinstructions.
+// Operands are combined by inclusive or, so SZA CLA is 7440|7200.
+var symbols = func() map[string]Word {
+	m := map[string]Word{
+		"CDF": 06201, "CIF": 06202,
+		"NOP": 07000, "CLA": 07200, "CLL": 07100, "CMA": 07040, "CML": 07020,
+		"IAC": 07001, "RAR": 07010, "RAL": 07004, "RTR": 07012, "RTL": 07006,
+		"BSW": 07002, "CIA": 07041, "STL": 07120, "STA": 07240, "GLK": 07204,
+		"SMA": 07500, "SZA": 07440, "SNL": 07420, "SPA": 07510, "SNA": 07450,
+		"SZL": 07430, "SKP": 07410, "OSR": 07404, "HLT": 07402, "LAS": 07604,
+		"MQA": 07501, "MQL": 07421, "CAM": 07621, "SWP": 07521,
+	}
+	for i, s := range mri {
+		m[s] = Word(i) << 9
+	}
+	for w, s := range iots {
+		m[s] = w
+	}
+	return m
+}()
+
+// asm is the state of an assembler pass.
+type asm struct {
+	final   bool // second pass: undefined symbols are errors
+	syms    map[string]Word
+	labels  map[string]bool // defined in this pass
+	field   int
+	loc     Word
+	decimal bool
+	blocks  []Block
+}
+
+// Assemble translates a PAL-8 program into blocks of words. It knows the
+// permanent symbols, labels (LOOP,), assignments (N=12), origins (*200),
+// the current location (.), + - and ! as operators, I and Z after a memory
+// reference, and the pseudo-ops FIELD, PAGE, DECIMAL and OCTAL. Numbers are
+// octal unless DECIMAL is on. A / starts a comment, a ; separates
+// statements and a $ ends the program. A memory reference must address
+// page zero or the current page; PAL-8 would link to other pages.
+func Assemble(r io.Reader) ([]Block, error) {
+	var lines []string
+	sc := bufio.NewScanner(r)
+	for sc.Scan() {
+		lines = append(lines, sc.Text())
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	syms := map[string]Word{}
+	for _, final := range []bool{false, true} {
+		a := &asm{final: final, syms: syms, labels: map[string]bool{}, loc: 0200}
+		if err := a.pass(lines); err != nil {
+			return nil, err
+		}
+		if final {
+			return a.blocks, nil
+		}
+	}
+	panic("unreachable")
+}
+
+func (a *asm) pass(lines []string) error {
+	for i, line := range lines {
+		if c := strings.IndexByte(line, '/'); c >= 0 {
+			line = line[:c]
+		}
+		for _, s := range strings.Split(line, ";") {
+			end, err := a.stmt(strings.ToUpper(strings.TrimSpace(s)))
+			if err != nil {
+				return fmt.Errorf("line %d: %w", i+1, err)
+			}
+			if end {
+				return nil
+			}
+		}
+	}
+	return nil
+}
+
+// stmt assembles one statement and reports whether it was the $ ending the
+// program.
+func (a *asm) stmt(s string) (bool, error) {
+	// labels
+	for {
+		i := strings.IndexByte(s, ',')
+		if i < 0 || !isSymbol(strings.TrimSpace(s[:i])) {
+			break
+		}
+		label := strings.TrimSpace(s[:i])
+		if a.labels[label] {
+			return false, fmt.Errorf("label %s defined twice", label)
+		}
+		a.labels[label] = true
+		if err := a.define(label, a.loc); err != nil {
+			return false, err
+		}
+		s = strings.TrimSpace(s[i+1:])
+	}
+	switch {
+	case s == "":
+		return false, nil
+	case s == "$":
+		return true, nil
+	case s[0] == '*':
+		v, err := a.expr(s[1:])
+		a.loc = v
+		return false, err
+	}
+	if name, x, ok := strings.Cut(s, "="); ok && isSymbol(strings.TrimSpace(name)) {
+		v, err := a.expr(x)
+		if err != nil {
+			return false, err
+		}
+		return false, a.define(strings.TrimSpace(name), v)
+	}
+	op, arg, _ := strings.Cut(s, " ")
+	arg = strings.TrimSpace(arg)
+	switch op {
+	case "DECIMAL", "OCTAL":
+		a.decimal = op == "DECIMAL"
+		return false, nil
+	case "FIELD":
+		v, err := a.expr(arg)
+		if err != nil {
+			return false, err
+		}
+		if v > 7 {
+			return false, fmt.Errorf("no field %o", v)
+		}
+		a.field, a.loc = int(v), 0200
+		return false, nil
+	case "PAGE":
+		if arg == "" {
+			if a.loc&0177 != 0 {
+				a.loc = (a.loc + 0200) & 07600
+			}
+			return false, nil
+		}
+		v, err := a.expr(arg)
+		a.loc = v << 7 & Mask
+		return false, err
+	}
+	w, err := a.word(op, arg)
+	if err != nil {
+		return false, err
+	}
+	a.emit(w)
+	return false, nil
+}
+
+func (a *asm) define(name string, v Word) error {
+	if _, ok := symbols[name]; ok {
+		return fmt.Errorf("%s is a permanent symbol", name)
+	}
+	a.syms[name] = v
+	return nil
+}
+
+// word assembles an instruction or a data word.
+func (a *asm) word(op, arg string) (Word, error) {
+	w, ok := symbols[op]
+	if !ok || w >= 06000 {
+		return a.expr(op + " " + arg)
+	}
+	for {
+		f, rest, _ := strings.Cut(arg, " ")
+		switch f {
+		case "I":
+			w |= indirect
+		case "Z":
+			// page zero, the default for addresses below 0200
+		default:
+			addr, err := a.expr(arg)
+			if err != nil {
+				return 0, err
+			}
+			switch {
+			case addr&07600 == 0:
+				return w | addr, nil
+			case addr&07600 == a.loc&07600:
+				return w | currentPage | addr&0177, nil
+			case !a.final:
+				return w, nil
+			}
+			return 0, fmt.Errorf("%s %04o: not on page zero or the page of %04o", op, addr, a.loc)
+		}
+		arg = strings.TrimSpace(rest)
+	}
+}
+
+func (a *asm) emit(w Word) {
+	n := len(a.blocks)
+	if n == 0 || a.blocks[n-1].Field != a.field ||
+		(a.blocks[n-1].Origin+Word(len(a.blocks[n-1].Words)))&Mask != a.loc {
+		a.blocks = append(a.blocks, Block{Field: a.field, Origin: a.loc})
+		n++
+	}
+	a.blocks[n-1].Words = append(a.blocks[n-1].Words, w&Mask)
+	a.loc = (a.loc + 1) & Mask
+}
+
+// expr evaluates operands joined by + - ! or blanks, which or like !, from
+// left to right.
+func (a *asm) expr(s string) (Word, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, fmt.Errorf("missing operand")
+	}
+	var v Word
+	op := byte('!')
+	for s != "" {
+		switch s[0] {
+		case ' ', '\t':
+			s = s[1:]
+			continue
+		case '+', '-', '!':
+			op = s[0]
+			s = s[1:]
+			continue
+		}
+		i := 1
+		for i < len(s) && !strings.ContainsRune(" \t+-!", rune(s[i])) {
+			i++
+		}
+		x, err := a.operand(s[:i])
+		if err != nil {
+			return 0, err
+		}
+		switch op {
+		case '+':
+			v += x
+		case '-':
+			v -= x
+		default:
+			v |= x
+		}
+		v &= Mask
+		op = '!'
+		s = s[i:]
+	}
+	return v, nil
+}
+
+func (a *asm) operand(s string) (Word, error) {
+	switch {
+	case s == ".":
+		return a.loc, nil
+	case s[0] >= '0' && s[0] <= '9':
+		base := 8
+		if a.decimal {
+			base = 10
+		}
+		n, err := strconv.ParseUint(s, base, 16)
+		if err != nil || n > Mask {
+			return 0, fmt.Errorf("bad number %s", s)
+		}
+		return Word(n), nil
+	case isSymbol(s):
+		if w, ok := symbols[s]; ok {
+			return w, nil
+		}
+		if w, ok := a.syms[s]; ok {
+			return w, nil
+		}
+		if !a.final {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("undefined symbol %s", s)
+	}
+	return 0, fmt.Errorf("bad operand %q", s)
+}
+
+func isSymbol(s string) bool {
+	if s == "" || s[0] < 'A' || s[0] > 'Z' {
+		return false
+	}
+	for _, c := range s {
+		if (c < 'A' || c > 'Z') && (c < '0' || c > '9') {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,87 @@
+package pdp8
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+// hello types H on the teletype and halts.
+const hello = `/ TYPE H
+*200
+START,	CLA CLL		/ clear AC and link
+	TAD CHAR
+	TLS; TSF	/ print, wait
+	JMP .-1
+	HLT
+	JMP START
+CHAR,	310		/ H
+PTR,	CHAR
+	TAD I PTR
+$
+	HLT		/ not assembled
+`
+
+func TestAssemble(t *testing.T) {
+	bs, err := Assemble(strings.NewReader(hello))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Word{07300, 01207, 06046, 06041, 05203, 07402, 05200, 0310, 0207, 01610}
+	if len(bs) != 1 || bs[0].Origin != 0200 || !slices.Equal(bs[0].Words, want) {
+		t.Errorf("want %o at 0200, got %v", want, bs)
+	}
+}
+
+func TestAssemblePseudoOps(t *testing.T) {
+	src := `N=12
+	DECIMAL
+	12; -1
+	OCTAL
+	N+N
+	TAD Z 17
+	TAD I 17
+	PAGE
+	DCA X
+X,	0
+	FIELD 1
+	CDF 10
+	PAGE 20
+	JMP .
+	*7777
+	SZA CLA
+	K=N!1
+	K`
+	bs, err := Assemble(strings.NewReader(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Block{
+		{0, 0200, []Word{014, 07777, 024, 01017, 01417}},
+		{0, 0400, []Word{03201, 0}},
+		{1, 0200, []Word{06211}},
+		{1, 04000, []Word{05200}},
+		{1, 07777, []Word{07640, 013}}, // wraps to 0000
+	}
+	if !equal(bs, want) {
+		t.Errorf("want %v, got %v", want, bs)
+	}
+}
+
+func TestAssembleErrors(t *testing.T) {
+	for src, want := range map[string]string{
+		"*200\nJMP 400": "line 2: JMP 0400: not on page zero or the page of 0200",
+		"TAD X":         "line 1: undefined symbol X",
+		"A, 0\nA, 1":    "line 2: label A defined twice",
+		"CLA=1":         "line 1: CLA is a permanent symbol",
+		"8":             "line 1: bad number 8",
+		"FIELD 10":      "line 1: no field 10",
+		"JMP I":         "line 1: missing operand",
+		"TAD (5)":       `line 1: bad operand "(5)"`,
+		"DECIMAL\n4096": "line 2: bad number 4096",
+	} {
+		if _, err := Assemble(strings.NewReader(src)); err == nil || err.Error() != want {
+			t.Errorf("%q: want %q, got %v", src, want, err)
+		}
+	}
+}
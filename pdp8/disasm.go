@@ -0,0 +1,169 @@
+package pdp8
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Memory reference instructions, opcodes 0 to 5.
+var mri = [...]string{"AND", "TAD", "ISZ", "DCA", "JMS", "JMP"}
+
+// Memory reference bits.
+const (
+	indirect    = 0400
+	currentPage = 0200
+)
+
+// iots names the I/O transfers of the console teletype, the reader and
+// punch, interrupts and memory extension.
+var iots = map[Word]string{
+	06001: "ION", 06002: "IOF",
+	06011: "RSF", 06012: "RRB", 06014: "RFC",
+	06021: "PSF", 06022: "PCF", 06024: "PPC", 06026: "PLS",
+	06031: "KSF", 06032: "KCC", 06034: "KRS", 06036: "KRB",
+	06041: "TSF", 06042: "TCF", 06044: "TPC", 06046: "TLS",
+	06214: "RDF", 06224: "RIF", 06234: "RIB", 06244: "RMF",
+}
+
+// micro is a microinstruction bit of an operate group.
+type micro struct {
+	bit  Word
+	name string
+}
+
+// Operate microinstructions in PAL-8 order. Group 1 and 2 share CLA.
+var (
+	group1 = []micro{{0200, "CLA"}, {0100, "CLL"}, {0040, "CMA"}, {0020, "CML"}, {0001, "IAC"}}
+	group2 = []micro{{0200, "CLA"}, {0004, "OSR"}, {0002, "HLT"}}
+	group3 = []micro{{0200, "CLA"}, {0100, "MQA"}, {0020, "MQL"}}
+	// skips of group 2 by sense bit 0010
+	skips = [2][]micro{
+		{{0100, "SMA"}, {0040, "SZA"}, {0020, "SNL"}},
+		{{0100, "SPA"}, {0040, "SNA"}, {0020, "SZL"}},
+	}
+)
+
+// Disassemble returns the PAL-8 form of w at address pc. Memory references
+// name the absolute address, words no mnemonic covers print in octal.
+func Disassemble(w Word, pc Word) string {
+	w &= Mask
+	op := w >> 9
+	switch {
+	case op <= 5:
+		addr := w & 0177
+		if w&currentPage != 0 {
+			if pc&07600 == 0 {
+				// page 0 is also the current page, PAL-8 would pick the
+				// page zero form
+				break
+			}
+			addr |= pc & 07600
+		}
+		s := mri[op] + " "
+		if w&indirect != 0 {
+			s += "I "
+		}
+		return s + fmt.Sprintf("%04o", addr)
+	case op == 6:
+		if s, ok := iots[w]; ok {
+			return s
+		}
+		if w&07700 == 06200 && w&7 >= 1 && w&7 <= 3 {
+			return [...]string{1: "CDF", 2: "CIF", 3: "CDF CIF"}[w&7] + fmt.Sprintf(" %o0", w>>3&7)
+		}
+	case w&0400 == 0:
+		return operate(w, 07000, group1, rotate(w))
+	case w&1 == 0:
+		sense := 0
+		if w&0010 != 0 {
+			sense = 1
+		}
+		var names []string
+		for _, m := range skips[sense] {
+			if w&m.bit != 0 {
+				names = append(names, m.name)
+			}
+		}
+		if sense == 1 && w&0160 == 0 {
+			names = append(names, "SKP")
+		}
+		return operate(w&^0170, 07400, group2, names)
+	default:
+		return operate(w, 07401, group3, nil)
+	}
+	return fmt.Sprintf("%04o", w)
+}
+
+// rotate names the rotation of group 1 instruction w.
+func rotate(w Word) []string {
+	switch w & 0016 {
+	case 0:
+		return nil
+	case 0010:
+		return []string{"RAR"}
+	case 0004:
+		return []string{"RAL"}
+	case 0012:
+		return []string{"RTR"}
+	case 0006:
+		return []string{"RTL"}
+	case 0002:
+		return []string{"BSW"}
+	}
+	return []string{"?"}
+}
+
+// operate names the microinstructions of w, whose group is base. Names in
+// extra come from bits handled by the caller. Bits without a name make the
+// word print in octal.
+func operate(w, base Word, ms []micro, extra []string) string {
+	if len(extra) == 1 && extra[0] == "?" {
+		return fmt.Sprintf("%04o", w)
+	}
+	var names []string
+	rest := w &^ base
+	if base == 07000 {
+		rest &^= 0016
+	}
+	for _, m := range ms {
+		if rest&m.bit != 0 {
+			names = append(names, m.name)
+			rest &^= m.bit
+		}
+	}
+	if rest != 0 {
+		return fmt.Sprintf("%04o", w)
+	}
+	// IAC comes after complements but before rotations.
+	names = append(names, extra...)
+	switch {
+	case base == 07000 && len(names) == 0:
+		return "NOP"
+	case len(names) == 0 || base != 07000 && len(names) == 1 && names[0] == "CLA":
+		// CLA alone assembles to group 1
+		return fmt.Sprintf("%04o", w)
+	}
+	return strings.Join(names, " ")
+}
+
+// Listing writes bs as a PAL-8 program that assembles to the same words:
+// an origin per block, one instruction per line with its address and
+// octal value as comment.
+func Listing(w io.Writer, bs []Block) error {
+	out := bufio.NewWriter(w)
+	f := -1
+	for _, b := range bs {
+		if b.Field != f {
+			f = b.Field
+			fmt.Fprintf(out, "\tFIELD %o\n", f)
+		}
+		fmt.Fprintf(out, "*%04o\n", b.Origin)
+		for i, x := range b.Words {
+			pc := (b.Origin + Word(i)) & Mask
+			fmt.Fprintf(out, "\t%-16s/%05o %04o\n", Disassemble(x, pc), b.Addr(i), x)
+		}
+	}
+	return out.Flush()
+}
@@ -0,0 +1,81 @@
+package pdp8
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestDisassemble(t *testing.T) {
+	tests := []struct {
+		w, pc Word
+		want  string
+	}{
+		{01020, 0200, "TAD 0020"},
+		{01420, 0200, "TAD I 0020"},
+		{05205, 0200, "JMP 0205"},
+		{05777, 04321, "JMP I 4377"},
+		{03200, 0000, "3200"}, // current page 0 reads as page zero
+		{07000, 0, "NOP"},
+		{07300, 0, "CLA CLL"},
+		{07041, 0, "CMA IAC"},
+		{07205, 0, "CLA IAC RAL"},
+		{07012, 0, "RTR"},
+		{07016, 0, "7016"},
+		{07440, 0, "SZA"},
+		{07450, 0, "SNA"},
+		{07610, 0, "CLA SKP"},
+		{07640, 0, "CLA SZA"},
+		{07402, 0, "HLT"},
+		{07600, 0, "7600"},
+		{07621, 0, "CLA MQL"},
+		{07601, 0, "7601"},
+		{06046, 0, "TLS"},
+		{06211, 0, "CDF 10"},
+		{06223, 0, "CDF CIF 20"},
+		{06400, 0, "6400"},
+	}
+	for _, tt := range tests {
+		if got := Disassemble(tt.w, tt.pc); got != tt.want {
+			t.Errorf("%04o at %04o: want %q, got %q", tt.w, tt.pc, tt.want, got)
+		}
+	}
+}
+
+// TestListingAssembles disassembles every possible word and focal69.bin
+// and assembles the listings back.
+func TestListingAssembles(t *testing.T) {
+	all := Block{Origin: 0}
+	for w := range Word(010000) {
+		all.Words = append(all.Words, w)
+	}
+	high := Block{Field: 2, Origin: 04400, Words: all.Words[:0200]}
+	_, focal := focal69(t)
+	for name, bs := range map[string][]Block{
+		"all":     {all},
+		"high":    {high},
+		"focal69": focal,
+	} {
+		var b bytes.Buffer
+		if err := Listing(&b, bs); err != nil {
+			t.Fatal(err)
+		}
+		got, err := Assemble(&b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !equal(got, bs) {
+			t.Errorf("%s: the listing assembles to something else", name)
+		}
+	}
+}
+
+func TestListing(t *testing.T) {
+	var b bytes.Buffer
+	if err := Listing(&b, []Block{{Field: 1, Origin: 0200, Words: []Word{07300, 01205}}}); err != nil {
+		t.Fatal(err)
+	}
+	want := "\tFIELD 1\n*0200\n\tCLA CLL         /10200 7300\n\tTAD 0205        /10201 1205\n"
+	if b.String() != want {
+		t.Errorf("want %q, got %q", want, b.String())
+	}
+}
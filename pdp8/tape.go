@@ -0,0 +1,218 @@
+// Package pdp8 reads and writes PDP-8 paper tapes, disassembles memory
+// images into PAL-8 and assembles small PAL-8 programs, enough to look
+// inside simh/focal69.bin and to build tapes for the simulator.
+//
+// Tapes carry 12 bit words as two frames of six bits. Both formats start
+// and end with leader and trailer frames of 0200. A frame with 0100 set
+// starts an origin, the address the following words load at.
+//
+// RIM, the read-in mode of the front panel loader, gives every word its
+// own origin: origin, word, origin, word. BIN, the format of the binary
+// loader, gives an origin once per block, sets memory fields with single
+// frames 11FFF000 and ends with a checksum word, the sum of all frames
+// before it modulo 4096. Frames between two rubouts, 0377, are comments.
+package pdp8
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Word is a 12 bit PDP-8 word.
+type Word uint16
+
+// Mask keeps the 12 bits of a word.
+const Mask = 07777
+
+// Block is a run of words that load from Origin on into a memory field.
+type Block struct {
+	Field  int // 0 to 7
+	Origin Word
+	Words  []Word
+}
+
+// Addr returns the 15 bit address of word i, field and address within the
+// field.
+func (b *Block) Addr(i int) int {
+	return b.Field<<12 | int(b.Origin+Word(i))&Mask
+}
+
+// Tape frames.
+const (
+	leader  = 0200
+	rubout  = 0377
+	origin  = 0100
+	field   = 0300
+	dataBit = 0077
+)
+
+// ErrChecksum tells that a BIN tape does not add up.
+var ErrChecksum = errors.New("pdp8: checksum mismatch")
+
+// frames returns the frames between leader and trailer, leaving out
+// comments between rubouts.
+func frames(r io.Reader) ([]byte, error) {
+	b, err := io.ReadAll(bufio.NewReader(r))
+	if err != nil {
+		return nil, err
+	}
+	var fs []byte
+	comment := false
+	for _, c := range b {
+		switch {
+		case c == rubout:
+			comment = !comment
+		case comment:
+		case c == leader:
+			if len(fs) > 0 {
+				return fs, nil
+			}
+		default:
+			fs = append(fs, c)
+		}
+	}
+	if len(fs) == 0 {
+		return nil, errors.New("pdp8: no data on tape")
+	}
+	return fs, nil
+}
+
+// ReadBIN reads a BIN tape and checks its checksum.
+func ReadBIN(r io.Reader) ([]Block, error) {
+	fs, err := frames(r)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		bs       []Block
+		f        int
+		sum, pre Word // the sum before the last word
+		last     = -1 // the block of the last word
+	)
+	for i := 0; i < len(fs); i++ {
+		c := fs[i]
+		if c&field == field {
+			f = int(c>>3) & 7
+			continue
+		}
+		if c&leader != 0 || i+1 == len(fs) || fs[i+1]&0300 != 0 {
+			return nil, fmt.Errorf("pdp8: bad frame %04o at %d", c, i)
+		}
+		w := Word(c&dataBit)<<6 | Word(fs[i+1]&dataBit)
+		pre = sum
+		sum = (sum + Word(c) + Word(fs[i+1])) & Mask
+		i++
+		if c&origin != 0 {
+			bs = append(bs, Block{Field: f, Origin: w})
+			last = -1
+			continue
+		}
+		if len(bs) == 0 {
+			return nil, errors.New("pdp8: word before the first origin")
+		}
+		b := &bs[len(bs)-1]
+		b.Words = append(b.Words, w)
+		last = len(bs) - 1
+	}
+	if last < 0 {
+		return nil, errors.New("pdp8: no checksum")
+	}
+	b := &bs[last]
+	check := b.Words[len(b.Words)-1]
+	b.Words = b.Words[:len(b.Words)-1]
+	if check != pre {
+		return nil, fmt.Errorf("%w: tape says %04o, frames add up to %04o", ErrChecksum, check, pre)
+	}
+	return bs, nil
+}
+
+// ReadRIM reads a RIM tape. Words loading at consecutive addresses form a
+// block.
+func ReadRIM(r io.Reader) ([]Block, error) {
+	fs, err := frames(r)
+	if err != nil {
+		return nil, err
+	}
+	if len(fs)%4 != 0 {
+		return nil, fmt.Errorf("pdp8: RIM tape of %d frames, want pairs of origin and word", len(fs))
+	}
+	var bs []Block
+	for i := 0; i < len(fs); i += 4 {
+		a, w := fs[i:i+2], fs[i+2:i+4]
+		if a[0]&0300 != origin || a[1]&0300 != 0 || w[0]&0300 != 0 || w[1]&0300 != 0 {
+			return nil, fmt.Errorf("pdp8: bad RIM frames % o at %d", fs[i:i+4], i)
+		}
+		addr := Word(a[0]&dataBit)<<6 | Word(a[1])
+		word := Word(w[0])<<6 | Word(w[1])
+		if n := len(bs); n > 0 {
+			if b := &bs[n-1]; (b.Origin+Word(len(b.Words)))&Mask == addr {
+				b.Words = append(b.Words, word)
+				continue
+			}
+		}
+		bs = append(bs, Block{Origin: addr, Words: []Word{word}})
+	}
+	return bs, nil
+}
+
+// tape collects the frames of a tape.
+type tape struct {
+	b   []byte
+	sum Word
+}
+
+func (t *tape) word(w Word, flags byte) {
+	hi, lo := flags|byte(w>>6&dataBit), byte(w&dataBit)
+	t.b = append(t.b, hi, lo)
+	t.sum = (t.sum + Word(hi) + Word(lo)) & Mask
+}
+
+func (t *tape) leader(n int) {
+	for range n {
+		t.b = append(t.b, leader)
+	}
+}
+
+// WriteBIN writes bs as a BIN tape with n frames of leader and trailer.
+// Every block gets an origin; field settings start the tape and mark
+// where the field changes.
+func WriteBIN(w io.Writer, bs []Block, n int) error {
+	var t tape
+	t.leader(n)
+	f := -1
+	for _, b := range bs {
+		if b.Field != f {
+			f = b.Field
+			t.b = append(t.b, byte(field|f<<3))
+		}
+		t.word(b.Origin, origin)
+		for _, x := range b.Words {
+			t.word(x, 0)
+		}
+	}
+	t.word(t.sum, 0)
+	t.leader(n)
+	_, err := w.Write(t.b)
+	return err
+}
+
+// WriteRIM writes bs as a RIM tape with n frames of leader and trailer.
+// RIM knows no fields, all blocks must be in field 0.
+func WriteRIM(w io.Writer, bs []Block, n int) error {
+	var t tape
+	t.leader(n)
+	for _, b := range bs {
+		if b.Field != 0 {
+			return fmt.Errorf("pdp8: RIM cannot load field %d", b.Field)
+		}
+		for i, x := range b.Words {
+			t.word((b.Origin+Word(i))&Mask, origin)
+			t.word(x, 0)
+		}
+	}
+	t.leader(n)
+	_, err := w.Write(t.b)
+	return err
+}
@@ -0,0 +1,159 @@
+package pdp8
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"slices"
+	"testing"
+)
+
+func focal69(t *testing.T) ([]byte, []Block) {
+	t.Helper()
+	b, err := os.ReadFile("../simh/focal69.bin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	bs, err := ReadBIN(bytes.NewReader(b))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b, bs
+}
+
+func equal(a, b []Block) bool {
+	return slices.EqualFunc(a, b, func(x, y Block) bool {
+		return x.Field == y.Field && x.Origin == y.Origin && slices.Equal(x.Words, y.Words)
+	})
+}
+
+func TestReadBIN(t *testing.T) {
+	_, bs := focal69(t)
+	want := []struct {
+		origin Word
+		n      int
+	}{{0, 03120}, {03206, 012}, {04300, 03300}}
+	if len(bs) != len(want) {
+		t.Fatalf("want %d blocks, got %d", len(want), len(bs))
+	}
+	for i, w := range want {
+		if bs[i].Field != 0 || bs[i].Origin != w.origin || len(bs[i].Words) != w.n {
+			t.Errorf("block %d: want %04o+%o, got field %d %04o+%o", i, w.origin, w.n, bs[i].Field, bs[i].Origin, len(bs[i].Words))
+		}
+	}
+	// the last block fills memory up to 7577
+	if b := bs[2]; b.Addr(len(b.Words)-1) != 07577 {
+		t.Errorf("want the last word at 7577, got %05o", b.Addr(len(b.Words)-1))
+	}
+}
+
+func TestWriteBIN(t *testing.T) {
+	tape, bs := focal69(t)
+	var b bytes.Buffer
+	if err := WriteBIN(&b, bs, 20); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(b.Bytes(), tape) {
+		t.Error("writing focal69.bin back changes it")
+	}
+}
+
+func TestReadBINChecksum(t *testing.T) {
+	tape, _ := focal69(t)
+	tape = slices.Clone(tape)
+	tape[100] ^= 1
+	if _, err := ReadBIN(bytes.NewReader(tape)); !errors.Is(err, ErrChecksum) {
+		t.Errorf("want a checksum error, got %v", err)
+	}
+}
+
+func TestReadBINFields(t *testing.T) {
+	bs := []Block{
+		{Field: 0, Origin: 0200, Words: []Word{07300, 01207}},
+		{Field: 1, Origin: 0200, Words: []Word{07402}},
+		{Field: 1, Origin: 04000, Words: []Word{07777}},
+	}
+	var b bytes.Buffer
+	if err := WriteBIN(&b, bs, 8); err != nil {
+		t.Fatal(err)
+	}
+	// comments between rubouts are skipped
+	tape := append([]byte{0377, 'H', 'I', 0377}, b.Bytes()...)
+	got, err := ReadBIN(bytes.NewReader(tape))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !equal(got, bs) {
+		t.Errorf("want %v, got %v", bs, got)
+	}
+	if a := got[1].Addr(0); a != 010200 {
+		t.Errorf("want address 10200, got %05o", a)
+	}
+}
+
+func TestReadErrors(t *testing.T) {
+	for _, tape := range [][]byte{
+		{0200, 0200},
+		{0200, 001, 002, 0200},        // word before origin
+		{0200, 0102, 000, 0200},       // no checksum
+		{0200, 0102, 000, 001, 0200},  // half a word
+		{0200, 0102, 000, 0101, 0200}, // origin's second frame
+	} {
+		if _, err := ReadBIN(bytes.NewReader(tape)); err == nil {
+			t.Errorf("% o: want an error", tape)
+		}
+	}
+	for _, tape := range [][]byte{
+		{0200, 0102, 000, 001},
+		{0200, 0002, 000, 001, 000},
+	} {
+		if _, err := ReadRIM(bytes.NewReader(tape)); err == nil {
+			t.Errorf("% o: want an error", tape)
+		}
+	}
+}
+
+func TestRIM(t *testing.T) {
+	_, bs := focal69(t)
+	var b bytes.Buffer
+	if err := WriteRIM(&b, bs, 20); err != nil {
+		t.Fatal(err)
+	}
+	if n := 40 + 4*(03120+012+03300); b.Len() != n {
+		t.Errorf("want %d frames, got %d", n, b.Len())
+	}
+	got, err := ReadRIM(&b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !equal(got, bs) {
+		t.Error("focal69 does not survive RIM")
+	}
+	if err := WriteRIM(&b, []Block{{Field: 1, Words: []Word{0}}}, 0); err == nil {
+		t.Error("want an error for field 1")
+	}
+}
+
+// TestShippedRIM reads simh/focal69.rim, made from focal69.bin with
+// pal8 conv.
+func TestShippedRIM(t *testing.T) {
+	_, bs := focal69(t)
+	b, err := os.ReadFile("../simh/focal69.rim")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := ReadRIM(bytes.NewReader(b))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !equal(got, bs) {
+		t.Error("focal69.rim and focal69.bin load different words")
+	}
+	var w bytes.Buffer
+	if err := WriteRIM(&w, got, 20); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(w.Bytes(), b) {
+		t.Error("writing focal69.rim back changes it")
+	}
+}
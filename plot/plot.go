@@ -0,0 +1,260 @@
+// Package plot renders simple line charts as SVG or PNG in pure Go, so
+// charts can be produced offline and in CI without external tools.
+package plot
+
+import (
+	"encoding/xml"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	"gitlab.com/jhinrichsen/lunar-lander/bitfont"
+)
+
+// Series is one line of a chart.
+type Series struct {
+	Name string
+	X, Y []float64
+}
+
+// Chart is a line chart with linear axes.
+type Chart struct {
+	Title          string
+	XLabel, YLabel string
+	Series         []Series
+	Width, Height  int // pixels, default 640x400
+}
+
+// palette colours the series in order.
+var palette = []color.RGBA{
+	{0x1f, 0x5f, 0xbf, 0xff},
+	{0xd0, 0x40, 0x20, 0xff},
+	{0x20, 0x90, 0x40, 0xff},
+	{0x80, 0x40, 0xa0, 0xff},
+}
+
+// margins around the plot area in pixels.
+const (
+	left   = 80
+	right  = 20
+	top    = 40
+	bottom = 50
+)
+
+// frame maps data coordinates onto the plot area.
+type frame struct {
+	w, h           int
+	x0, x1, y0, y1 float64 // data bounds, rounded to ticks
+	xt, yt         []float64
+}
+
+func (c *Chart) frame() (frame, error) {
+	f := frame{w: c.Width, h: c.Height}
+	if f.w == 0 {
+		f.w = 640
+	}
+	if f.h == 0 {
+		f.h = 400
+	}
+	xmin, xmax := math.Inf(1), math.Inf(-1)
+	ymin, ymax := math.Inf(1), math.Inf(-1)
+	for _, s := range c.Series {
+		for i := range min(len(s.X), len(s.Y)) {
+			xmin, xmax = math.Min(xmin, s.X[i]), math.Max(xmax, s.X[i])
+			ymin, ymax = math.Min(ymin, s.Y[i]), math.Max(ymax, s.Y[i])
+		}
+	}
+	if math.IsInf(xmin, 0) {
+		xmin, xmax, ymin, ymax = 0, 1, 0, 1
+	}
+	f.xt = Ticks(xmin, xmax, 8)
+	f.yt = Ticks(ymin, ymax, 6)
+	if len(f.xt) == 0 || len(f.yt) == 0 {
+		return f, fmt.Errorf("plot: no axes for data in %g..%g, %g..%g", xmin, xmax, ymin, ymax)
+	}
+	f.x0, f.x1 = f.xt[0], f.xt[len(f.xt)-1]
+	f.y0, f.y1 = f.yt[0], f.yt[len(f.yt)-1]
+	return f, nil
+}
+
+// px returns the pixel position of data point x, y.
+func (f frame) px(x, y float64) (float64, float64) {
+	pw := float64(f.w - left - right)
+	ph := float64(f.h - top - bottom)
+	return left + (x-f.x0)/(f.x1-f.x0)*pw,
+		float64(top) + (f.y1-y)/(f.y1-f.y0)*ph
+}
+
+// Ticks returns evenly spaced, round tick positions covering lo to hi with
+// about n intervals, in steps of 1, 2 or 5 times a power of ten, none if
+// lo or hi is not finite.
+func Ticks(lo, hi float64, n int) []float64 {
+	if math.IsNaN(lo) || math.IsNaN(hi) || math.IsInf(lo, 0) || math.IsInf(hi, 0) {
+		return nil
+	}
+	if lo == hi {
+		lo, hi = lo-1, hi+1
+	}
+	raw := (hi - lo) / float64(n)
+	mag := math.Pow(10, math.Floor(math.Log10(raw)))
+	step := 10 * mag
+	for _, m := range []float64{1, 2, 5} {
+		if m*mag >= raw {
+			step = m * mag
+			break
+		}
+	}
+	lo, hi = math.Floor(lo/step)*step, math.Ceil(hi/step)*step
+	var ts []float64
+	for i := 0; lo+float64(i)*step <= hi+step/2; i++ {
+		// Snap to the step grid to avoid 0.30000000000000004.
+		ts = append(ts, math.Round((lo+float64(i)*step)/step)*step)
+	}
+	return ts
+}
+
+// label formats a tick value.
+func label(v float64) string {
+	return strconv.FormatFloat(v, 'g', 6, 64)
+}
+
+// SVG writes the chart as an SVG document.
+func (c *Chart) SVG(w io.Writer) error {
+	f, err := c.frame()
+	if err != nil {
+		return err
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" font-family="monospace" font-size="12">`+"\n", f.w, f.h)
+	fmt.Fprintf(&b, `<rect width="%d" height="%d" fill="white"/>`+"\n", f.w, f.h)
+	text := func(x, y float64, anchor, s string, extra string) {
+		fmt.Fprintf(&b, `<text x="%.1f" y="%.1f" text-anchor="%s"%s>`, x, y, anchor, extra)
+		xml.EscapeText(&b, []byte(s))
+		b.WriteString("</text>\n")
+	}
+	for _, t := range f.xt {
+		x, y := f.px(t, f.y0)
+		_, ytop := f.px(t, f.y1)
+		fmt.Fprintf(&b, `<line x1="%.1f" y1="%.1f" x2="%.1f" y2="%.1f" stroke="#ddd"/>`+"\n", x, ytop, x, y)
+		text(x, y+16, "middle", label(t), "")
+	}
+	for _, t := range f.yt {
+		x, y := f.px(f.x0, t)
+		xr, _ := f.px(f.x1, t)
+		fmt.Fprintf(&b, `<line x1="%.1f" y1="%.1f" x2="%.1f" y2="%.1f" stroke="#ddd"/>`+"\n", x, y, xr, y)
+		text(x-6, y+4, "end", label(t), "")
+	}
+	x0, y0 := f.px(f.x0, f.y0)
+	x1, y1 := f.px(f.x1, f.y1)
+	fmt.Fprintf(&b, `<rect x="%.1f" y="%.1f" width="%.1f" height="%.1f" fill="none" stroke="black"/>`+"\n", x0, y1, x1-x0, y0-y1)
+	for i, s := range c.Series {
+		col := palette[i%len(palette)]
+		fmt.Fprintf(&b, `<polyline fill="none" stroke="#%02x%02x%02x" stroke-width="1.5" points="`, col.R, col.G, col.B)
+		for j := range min(len(s.X), len(s.Y)) {
+			x, y := f.px(s.X[j], s.Y[j])
+			fmt.Fprintf(&b, "%.2f,%.2f ", x, y)
+		}
+		b.WriteString(`"/>` + "\n")
+		if s.Name != "" {
+			text(x1-6, y1+16*float64(i+1), "end", s.Name, fmt.Sprintf(` fill="#%02x%02x%02x"`, col.R, col.G, col.B))
+		}
+	}
+	text(float64(f.w)/2, 24, "middle", c.Title, ` font-size="16"`)
+	text(float64(f.w)/2, float64(f.h)-10, "middle", c.XLabel, "")
+	text(16, float64(f.h)/2, "middle", c.YLabel, fmt.Sprintf(` transform="rotate(-90 16 %d)"`, f.h/2))
+	b.WriteString("</svg>\n")
+	_, err = io.WriteString(w, b.String())
+	return err
+}
+
+// PNG writes the chart as a PNG image.
+func (c *Chart) PNG(w io.Writer) error {
+	f, err := c.frame()
+	if err != nil {
+		return err
+	}
+	img := image.NewRGBA(image.Rect(0, 0, f.w, f.h))
+	draw.Draw(img, img.Bounds(), image.White, image.Point{}, draw.Src)
+	grid := color.RGBA{0xdd, 0xdd, 0xdd, 0xff}
+	text := func(x, y int, s string, c color.Color, scale int) {
+		bitfont.Draw(img, x, y, s, c, scale)
+	}
+	for _, t := range f.xt {
+		x, y := f.px(t, f.y0)
+		_, ytop := f.px(t, f.y1)
+		line(img, x, ytop, x, y, grid)
+		s := label(t)
+		text(int(x)-bitfont.Bounds(s, 1).X/2, int(y)+8, s, color.Black, 1)
+	}
+	for _, t := range f.yt {
+		x, y := f.px(f.x0, t)
+		xr, _ := f.px(f.x1, t)
+		line(img, x, y, xr, y, grid)
+		s := label(t)
+		text(int(x)-6-bitfont.Bounds(s, 1).X, int(y)-bitfont.Height/2, s, color.Black, 1)
+	}
+	x0, y0 := f.px(f.x0, f.y0)
+	x1, y1 := f.px(f.x1, f.y1)
+	line(img, x0, y0, x1, y0, color.Black)
+	line(img, x0, y1, x1, y1, color.Black)
+	line(img, x0, y0, x0, y1, color.Black)
+	line(img, x1, y0, x1, y1, color.Black)
+	for i, s := range c.Series {
+		col := palette[i%len(palette)]
+		for j := 1; j < min(len(s.X), len(s.Y)); j++ {
+			ax, ay := f.px(s.X[j-1], s.Y[j-1])
+			bx, by := f.px(s.X[j], s.Y[j])
+			line(img, ax, ay, bx, by, col)
+		}
+		if s.Name != "" {
+			text(int(x1)-6-bitfont.Bounds(s.Name, 1).X, int(y1)+6+12*i, s.Name, col, 1)
+		}
+	}
+	text(f.w/2-bitfont.Bounds(c.Title, 2).X/2, 10, c.Title, color.Black, 2)
+	text(f.w/2-bitfont.Bounds(c.XLabel, 1).X/2, f.h-16, c.XLabel, color.Black, 1)
+	text(4, top-12, c.YLabel, color.Black, 1)
+	return png.Encode(w, img)
+}
+
+// line draws from a to b with Bresenham's algorithm.
+func line(img draw.Image, ax, ay, bx, by float64, c color.Color) {
+	x0, y0 := int(math.Round(ax)), int(math.Round(ay))
+	x1, y1 := int(math.Round(bx)), int(math.Round(by))
+	dx, dy := abs(x1-x0), -abs(y1-y0)
+	sx, sy := 1, 1
+	if x0 > x1 {
+		sx = -1
+	}
+	if y0 > y1 {
+		sy = -1
+	}
+	e := dx + dy
+	for {
+		img.Set(x0, y0, c)
+		if x0 == x1 && y0 == y1 {
+			return
+		}
+		e2 := 2 * e
+		if e2 >= dy {
+			e += dy
+			x0 += sx
+		}
+		if e2 <= dx {
+			e += dx
+			y0 += sy
+		}
+	}
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
@@ -0,0 +1,92 @@
+package plot
+
+import (
+	"bytes"
+	"encoding/xml"
+	"image/png"
+	"io"
+	"math"
+	"slices"
+	"testing"
+)
+
+func TestTicks(t *testing.T) {
+	tests := []struct {
+		lo, hi float64
+		n      int
+		want   []float64
+	}{
+		{0, 120, 6, []float64{0, 20, 40, 60, 80, 100, 120}},
+		{0.1, 0.95, 4, []float64{0, 0.5, 1}},
+		{-97, 3600, 4, []float64{-1000, 0, 1000, 2000, 3000, 4000}},
+		{5, 5, 2, []float64{4, 5, 6}},
+		{0, math.Inf(1), 4, nil},
+		{math.NaN(), 1, 4, nil},
+	}
+	for _, tt := range tests {
+		if got := Ticks(tt.lo, tt.hi, tt.n); !slices.Equal(got, tt.want) {
+			t.Errorf("Ticks(%g, %g, %d): want %v, got %v", tt.lo, tt.hi, tt.n, tt.want, got)
+		}
+	}
+}
+
+var chart = &Chart{
+	Title:  "ALTITUDE <& MORE>",
+	XLabel: "TIME,SECS",
+	YLabel: "MILES",
+	Series: []Series{
+		{Name: "A", X: []float64{0, 10, 20}, Y: []float64{120, 109.9, 99.8}},
+		{Name: "B", X: []float64{0, 10, 20}, Y: []float64{120, 115, 100}},
+	},
+	Width:  320,
+	Height: 200,
+}
+
+func TestSVG(t *testing.T) {
+	var buf bytes.Buffer
+	if err := chart.SVG(&buf); err != nil {
+		t.Fatal(err)
+	}
+	dec := xml.NewDecoder(&buf)
+	polylines := 0
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("malformed SVG: %v", err)
+		}
+		if se, ok := tok.(xml.StartElement); ok && se.Name.Local == "polyline" {
+			polylines++
+		}
+	}
+	if polylines != 2 {
+		t.Errorf("want 2 polylines, got %d", polylines)
+	}
+}
+
+func TestPNG(t *testing.T) {
+	var buf bytes.Buffer
+	if err := chart.PNG(&buf); err != nil {
+		t.Fatal(err)
+	}
+	img, err := png.Decode(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b := img.Bounds(); b.Dx() != 320 || b.Dy() != 200 {
+		t.Errorf("want 320x200, got %v", b)
+	}
+}
+
+func TestNotFinite(t *testing.T) {
+	c := &Chart{Series: []Series{{X: []float64{0, 1}, Y: []float64{0, math.NaN()}}}}
+	if err := c.SVG(io.Discard); err == nil {
+		t.Error("SVG: want an error")
+	}
+	c.Series[0].Y[1] = math.Inf(1)
+	if err := c.PNG(io.Discard); err == nil {
+		t.Error("PNG: want an error")
+	}
+}
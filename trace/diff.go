@@ -0,0 +1,113 @@
+package trace
+
+import (
+	"fmt"
+	"math"
+)
+
+// Mismatch is the first place where two traces disagree.
+type Mismatch struct {
+	Var string
+	// Nth counts the values Var took in both traces, from 0.
+	Nth int
+	// Want and Got are the events that set the value in each trace; an
+	// index of -1 means the trace ended before Var took an nth value.
+	Want, Got           int
+	WantValue, GotValue float64
+}
+
+// value is a change of a variable and the event that made it.
+type value struct {
+	v     float64
+	event int
+}
+
+// history returns the values each variable takes in es.
+func history(es []Event) map[string][]value {
+	h := map[string][]value{}
+	for i, e := range es {
+		for _, c := range e.Changes {
+			h[c.Name] = append(h[c.Name], value{c.Value, i})
+		}
+	}
+	return h
+}
+
+// Equal reports whether a and b agree within the relative tolerance tol,
+// or absolutely for values near 0.
+func Equal(a, b, tol float64) bool {
+	if a == b || (math.IsNaN(a) && math.IsNaN(b)) {
+		return true
+	}
+	return math.Abs(a-b) <= tol*math.Max(1, math.Max(math.Abs(a), math.Abs(b)))
+}
+
+// Diff compares the values variables take in want, usually the trace of
+// the FOCAL program, and got, the trace of a port. Variables only one of
+// them knows, like the loop counter X of 02.72, are ignored. Because only
+// changes are compared, a port may take coarser steps than FOCAL and
+// compute in another order. Diff returns the mismatch that comes first in
+// want, or nil.
+func Diff(want, got []Event, tol float64) *Mismatch {
+	hw, hg := history(want), history(got)
+	var first *Mismatch
+	// where orders mismatches by their position in want; running out of
+	// values counts as the end of want.
+	where := func(m *Mismatch) int {
+		if m.Want < 0 {
+			return len(want)
+		}
+		return m.Want
+	}
+	for name, ws := range hw {
+		gs, ok := hg[name]
+		if !ok {
+			continue
+		}
+		for i := range max(len(ws), len(gs)) {
+			m := &Mismatch{Var: name, Nth: i, Want: -1, Got: -1}
+			if i < len(ws) {
+				m.Want, m.WantValue = ws[i].event, ws[i].v
+			}
+			if i < len(gs) {
+				m.Got, m.GotValue = gs[i].event, gs[i].v
+			}
+			if m.Want >= 0 && m.Got >= 0 && Equal(m.WantValue, m.GotValue, tol) {
+				continue
+			}
+			if first == nil || where(m) < where(first) || (where(m) == where(first) && m.Var < first.Var) {
+				first = m
+			}
+			break
+		}
+	}
+	return first
+}
+
+// Format describes m in terms of the events of want and got.
+func (m *Mismatch) Format(want, got []Event) string {
+	switch {
+	case m.Want < 0:
+		return fmt.Sprintf("%s: port sets %s once more, to %g, after the FOCAL trace ends", got[m.Got].Line, m.Var, m.GotValue)
+	case m.Got < 0:
+		e := want[m.Want]
+		return fmt.Sprintf("%s %s: %s = %g, the port never sets it a %s time", e.Line, e.Stmt, m.Var, m.WantValue, ordinal(m.Nth+1))
+	}
+	e := want[m.Want]
+	return fmt.Sprintf("%s %s: %s = %g, the port has %g at %s (%s value)",
+		e.Line, e.Stmt, m.Var, m.WantValue, m.GotValue, got[m.Got].Line, ordinal(m.Nth+1))
+}
+
+func ordinal(n int) string {
+	suffix := "th"
+	switch {
+	case n%100 >= 11 && n%100 <= 13:
+	case n%10 == 1:
+		suffix = "st"
+	case n%10 == 2:
+		suffix = "nd"
+	case n%10 == 3:
+		suffix = "rd"
+	}
+	return fmt.Sprintf("%d%s", n, suffix)
+}
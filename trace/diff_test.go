@@ -0,0 +1,86 @@
+package trace
+
+import (
+	"strings"
+	"testing"
+)
+
+func events(t *testing.T, s string) []Event {
+	t.Helper()
+	es, err := Read(strings.NewReader(s))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return es
+}
+
+const focalTrace = `01.50	S A=120	A=120
+01.50	S V=1	V=1
+09.10	S Q=S*K/M	Q=0.1
+09.10	S J=V+G*S	J=1.01
+02.72	F X=1,51;T "."	X=1
+06.10	S A=I	A=110
+06.10	S V=J	V=1.01
+`
+
+func TestDiff(t *testing.T) {
+	want := events(t, focalTrace)
+	tests := []struct {
+		name, port, msg string
+	}{
+		{"agree", `01.50	intro	A=120 V=1
+09.10	sub9	J=1.0100000000001
+06.10	sub6	A=110 V=1.01
+`, ""},
+		{"value", `01.50	intro	A=120 V=1
+09.10	sub9	J=1.02
+06.10	sub6	A=110 V=1.02
+`, "09.10 S J=V+G*S: J = 1.01, the port has 1.02 at 09.10 (1st value)"},
+		{"missing", `01.50	intro	A=120 V=1
+09.10	sub9	J=1.01
+`, "06.10 S A=I: A = 110, the port never sets it a 2nd time"},
+		{"extra", `01.50	intro	A=120 V=1
+09.10	sub9	J=1.01
+06.10	sub6	A=110 V=1.01
+06.10	sub6	A=100
+`, "06.10: port sets A once more, to 100, after the FOCAL trace ends"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := events(t, tt.port)
+			m := Diff(want, got, 1e-9)
+			msg := ""
+			if m != nil {
+				msg = m.Format(want, got)
+			}
+			if msg != tt.msg {
+				t.Errorf("want %q, got %q", tt.msg, msg)
+			}
+		})
+	}
+}
+
+func TestEqual(t *testing.T) {
+	for _, tt := range []struct {
+		a, b, tol float64
+		want      bool
+	}{
+		{1, 1, 0, true},
+		{1e6, 1e6 + 1e-4, 1e-9, true},
+		{1e6, 1e6 + 1e-2, 1e-9, false},
+		{0, 1e-10, 1e-9, true},
+		{0, 1e-8, 1e-9, false},
+	} {
+		if got := Equal(tt.a, tt.b, tt.tol); got != tt.want {
+			t.Errorf("Equal(%v, %v, %v) = %v", tt.a, tt.b, tt.tol, got)
+		}
+	}
+}
+
+func TestOrdinal(t *testing.T) {
+	for n, want := range map[int]string{1: "1st", 2: "2nd", 3: "3rd", 4: "4th", 11: "11th", 12: "12th", 21: "21st", 112: "112th"} {
+		if got := ordinal(n); got != want {
+			t.Errorf("ordinal(%d) = %s, want %s", n, got, want)
+		}
+	}
+}
@@ -0,0 +1,179 @@
+// Package trace records executions of lunar-lander.fc as a list of events,
+// one per FOCAL statement or per FOCAL line a Go port implements, each with
+// the variables it changed. Diff aligns a FOCAL trace with a port's and
+// finds the first FOCAL line where they disagree.
+//
+// A trace is text, one event per line: the FOCAL line, the statement and
+// the changes, separated by tabs. A port names what it did instead of the
+// statement.
+//
+//	03.10	S S=T	S=10
+//	03.50	D 9
+//	09.10	S Q=S*K/M	Q=0.0061
+//	09.10	sub9	J=1.01
+package trace
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+
+	"gitlab.com/jhinrichsen/lunar-lander/focal"
+)
+
+// Change is a variable and its new value.
+type Change struct {
+	Name  string
+	Value float64
+}
+
+// Event is an executed statement or line and what it changed.
+type Event struct {
+	Line    focal.Num
+	Stmt    string // as written, or what a port did
+	Changes []Change
+}
+
+func (e Event) String() string {
+	var b strings.Builder
+	b.WriteString(e.Line.String())
+	b.WriteByte('\t')
+	b.WriteString(e.Stmt)
+	for i, c := range e.Changes {
+		if i == 0 {
+			b.WriteByte('\t')
+		} else {
+			b.WriteByte(' ')
+		}
+		b.WriteString(c.Name)
+		b.WriteByte('=')
+		b.WriteString(strconv.FormatFloat(c.Value, 'g', -1, 64))
+	}
+	return b.String()
+}
+
+// Recorder turns snapshots of a program's variables into events holding
+// what changed since the last snapshot, and writes them. Variables start
+// at 0 like in FOCAL.
+type Recorder struct {
+	w    *bufio.Writer
+	last map[string]float64
+	err  error
+}
+
+// NewRecorder returns a recorder writing to w. Call Flush when done.
+func NewRecorder(w io.Writer) *Recorder {
+	return &Recorder{w: bufio.NewWriter(w), last: map[string]float64{}}
+}
+
+// Record writes an event for line n with the variables of vars that
+// changed. A nil recorder records nothing, so ports can trace
+// unconditionally.
+func (r *Recorder) Record(n focal.Num, stmt string, vars map[string]float64) {
+	if r == nil {
+		return
+	}
+	e := Event{Line: n, Stmt: stmt}
+	for _, name := range slices.Sorted(maps.Keys(vars)) {
+		v := vars[name]
+		// NaN never equals itself; staying NaN is no change.
+		if old := r.last[name]; v != old && (v == v || old == old) {
+			e.Changes = append(e.Changes, Change{name, v})
+			r.last[name] = v
+		}
+	}
+	r.write(e)
+}
+
+// Erase records the E of line n: all variables are 0 again. Variables set
+// to 0 this way are not changes, a port that zeroes them one by one records
+// Erase too.
+func (r *Recorder) Erase(n focal.Num) {
+	if r == nil {
+		return
+	}
+	clear(r.last)
+	r.write(Event{Line: n, Stmt: "E"})
+}
+
+func (r *Recorder) write(e Event) {
+	if r.err == nil {
+		_, r.err = fmt.Fprintln(r.w, e)
+	}
+}
+
+// Flush writes buffered events and returns the first write error.
+func (r *Recorder) Flush() error {
+	if r == nil {
+		return nil
+	}
+	if err := r.w.Flush(); r.err == nil {
+		r.err = err
+	}
+	return r.err
+}
+
+// Read reads a trace.
+func Read(r io.Reader) ([]Event, error) {
+	var es []Event
+	sc := bufio.NewScanner(r)
+	for i := 1; sc.Scan(); i++ {
+		if sc.Text() == "" {
+			continue
+		}
+		e, err := parse(sc.Text())
+		if err != nil {
+			return nil, fmt.Errorf("%d: %w", i, err)
+		}
+		es = append(es, e)
+	}
+	return es, sc.Err()
+}
+
+func parse(s string) (Event, error) {
+	fields := strings.Split(s, "\t")
+	if len(fields) < 2 || len(fields) > 3 {
+		return Event{}, fmt.Errorf("want line, statement and changes, got %q", s)
+	}
+	n, err := focal.ParseNum(fields[0])
+	if err != nil {
+		return Event{}, err
+	}
+	e := Event{Line: n, Stmt: fields[1]}
+	if len(fields) == 3 {
+		for _, f := range strings.Fields(fields[2]) {
+			name, val, ok := strings.Cut(f, "=")
+			v, err := strconv.ParseFloat(val, 64)
+			if !ok || err != nil {
+				return Event{}, fmt.Errorf("bad change %q", f)
+			}
+			e.Changes = append(e.Changes, Change{name, v})
+		}
+	}
+	return e, nil
+}
+
+// Focal runs p on tty in the interpreter of package focal and records
+// every statement to w.
+func Focal(p *focal.Program, tty *focal.Terminal, w io.Writer) error {
+	in := focal.NewInterp(p, tty)
+	r := NewRecorder(w)
+	for !in.Done() {
+		n, s, _ := in.Pos()
+		err := in.Step()
+		if _, ok := s.(*focal.Erase); ok {
+			r.Erase(n)
+		} else {
+			r.Record(n, s.String(), in.Vars())
+		}
+		if err != nil {
+			r.Flush()
+			return err
+		}
+	}
+	return r.Flush()
+}
@@ -0,0 +1,75 @@
+package trace
+
+import (
+	"errors"
+	"io"
+	"slices"
+	"strings"
+	"testing"
+
+	"gitlab.com/jhinrichsen/lunar-lander/focal"
+)
+
+func TestRecorder(t *testing.T) {
+	var b strings.Builder
+	r := NewRecorder(&b)
+	r.Record(150, "S A=120", map[string]float64{"A": 120, "V": 0})
+	r.Record(160, "S V=1", map[string]float64{"A": 120, "V": 1})
+	r.Erase(120)
+	r.Record(150, "S A=120", map[string]float64{"A": 120})
+	if err := r.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	want := "01.50\tS A=120\tA=120\n01.60\tS V=1\tV=1\n01.20\tE\n01.50\tS A=120\tA=120\n"
+	if b.String() != want {
+		t.Errorf("want %q, got %q", want, b.String())
+	}
+	var none *Recorder
+	none.Record(150, "", nil)
+	none.Erase(120)
+	if err := none.Flush(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestRead(t *testing.T) {
+	es, err := Read(strings.NewReader("01.50\tS A=120\tA=120 V=-1e-05\n\n03.50\tD 9\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Event{
+		{150, "S A=120", []Change{{"A", 120}, {"V", -1e-5}}},
+		{350, "D 9", nil},
+	}
+	if !slices.EqualFunc(es, want, func(a, b Event) bool { return a.String() == b.String() }) {
+		t.Errorf("want %v, got %v", want, es)
+	}
+	for _, s := range []string{"01.50", "1.5.5\tx", "01.50\tS\tA", "01.50\tS\tA=x", "a\tb\tc\td"} {
+		if _, err := Read(strings.NewReader(s)); err == nil {
+			t.Errorf("%q: want an error", s)
+		}
+	}
+}
+
+func TestFocal(t *testing.T) {
+	p, err := focal.Parse(strings.NewReader("01.10 S A=1;F I=1,2;S A=A*2\n01.20 E;A B\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The FOR steps I after its body.
+	var b strings.Builder
+	err = Focal(p, focal.NewTerminal(strings.NewReader(""), io.Discard), &b)
+	if !errors.Is(err, io.EOF) {
+		t.Errorf("want EOF, got %v", err)
+	}
+	want := `01.10	S A=1	A=1
+01.10	F I=1,2;S A=A*2	I=1
+01.10	S A=A*2	A=2 I=2
+01.10	S A=A*2	A=4 I=3
+01.20	E
+01.20	A B
+`
+	if b.String() != want {
+		t.Errorf("want\n%s\ngot\n%s", want, b.String())
+	}
+}
@@ -0,0 +1,123 @@
+// Package typefmt prints numbers the way the FOCAL-69 TYPE statement does,
+// so a Go port of a FOCAL program types the same columns as the program
+// instead of widths matched to a transcript by trial and error.
+//
+// A format %n.m right aligns a number in n columns with m decimals, %n
+// prints no decimal point and a bare % selects E notation. The format is
+// sticky: lunar-lander.fc sets %7.02 at 02.70, and the times and velocities
+// of lines 04.10 to 05.83 print with it although these lines name no format.
+//
+// Every number starts with a blank, then the sign, a blank for positive
+// numbers, then the digits. The sign column is not counted in n, so
+// 3600*V under %6.02 prints "  556.96" and "  -21.20" alike. A number with
+// more digits before the point than n-m leaves no room for the decimals
+// and prints in E notation. FOCAL has no infinities; Go's print as " Inf",
+// "-Inf" and " NaN" after the leading blank.
+package typefmt
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Format is %Width.Digits, or E notation.
+type Format struct {
+	Width, Digits int
+	E             bool
+}
+
+// Default is the format FOCAL-69 starts with, %8.04.
+var Default = Format{Width: 8, Digits: 4}
+
+func (f Format) String() string {
+	switch {
+	case f.E:
+		return "%"
+	case f.Digits == 0:
+		return fmt.Sprintf("%%%d", f.Width)
+	}
+	return fmt.Sprintf("%%%d.%02d", f.Width, f.Digits)
+}
+
+// Sprint returns x as TYPE prints it under f.
+func (f Format) Sprint(x float64) string {
+	if f.E {
+		return e(x)
+	}
+	digits := strconv.FormatFloat(math.Abs(x), 'f', f.Digits, 64)
+	whole, _, _ := strings.Cut(digits, ".")
+	if whole == "0" {
+		whole = ""
+	}
+	if len(whole) > f.Width-f.Digits || math.IsInf(x, 0) || math.IsNaN(x) {
+		return e(x)
+	}
+	// A negative number rounding to 0 prints without its sign.
+	sign := " "
+	if x < 0 && strings.Trim(digits, "0.") != "" {
+		sign = "-"
+	}
+	return fmt.Sprintf(" %*s", f.Width+1, sign+digits)
+}
+
+// e formats x in E notation, Inf and NaN with the same sign column.
+func e(x float64) string {
+	sign := " "
+	if math.Signbit(x) && x != 0 && !math.IsNaN(x) {
+		sign = "-"
+	}
+	switch {
+	case math.IsInf(x, 0):
+		return " " + sign + "Inf"
+	case math.IsNaN(x):
+		return " " + sign + "NaN"
+	}
+	return " " + sign + fmt.Sprintf("%.6E", math.Abs(x))
+}
+
+// Writer types text and numbers under a sticky format.
+type Writer struct {
+	w   io.Writer
+	f   Format
+	err error
+}
+
+// NewWriter returns a writer to w starting with the Default format.
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{w: w, f: Default}
+}
+
+// Type types items like a TYPE statement: a string is text, a float64 a
+// number in the current format and a Format changes the format.
+func (t *Writer) Type(items ...any) {
+	for _, it := range items {
+		var s string
+		switch it := it.(type) {
+		case string:
+			s = it
+		case float64:
+			s = t.f.Sprint(it)
+		case Format:
+			t.f = it
+			continue
+		default:
+			panic(fmt.Sprintf("typefmt: cannot type %T", it))
+		}
+		if t.err == nil {
+			_, t.err = io.WriteString(t.w, s)
+		}
+	}
+}
+
+// Format returns the current format.
+func (t *Writer) Format() Format {
+	return t.f
+}
+
+// Err returns the first write error.
+func (t *Writer) Err() error {
+	return t.err
+}
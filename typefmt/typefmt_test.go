@@ -0,0 +1,104 @@
+package typefmt
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestSprint(t *testing.T) {
+	tests := []struct {
+		f    Format
+		x    float64
+		want string
+	}{
+		// From the retrofocal transcripts of lunar-lander.fc.
+		{Format{Width: 3}, 0, "    0"},
+		{Format{Width: 3}, 120, "  120"},
+		{Format{Width: 4}, 5016, "  5016"},
+		{Format{Width: 6, Digits: 2}, 3600, "  3600.00"},
+		{Format{Width: 6, Digits: 2}, 556.96, "  556.96"},
+		{Format{Width: 6, Digits: 2}, -21.2, "  -21.20"},
+		{Format{Width: 6, Digits: 1}, 16000, "  16000.0"},
+		{Format{Width: 7, Digits: 2}, 220.3, "   220.30"},
+		{Format{Width: 7, Digits: 2}, 0, "     0.00"},
+		{Format{Width: 7, Digits: 2}, 12345.678, "  12345.68"},
+		// The sign takes its own column.
+		{Format{Width: 3}, -120, " -120"},
+		{Format{Width: 3}, 4.6, "    5"},
+		{Format{Width: 6, Digits: 2}, -.001, "    0.00"},
+		{Format{Width: 2, Digits: 2}, .25, "  0.25"},
+		// No room before the point.
+		{Format{Width: 3}, 1000, "  1.000000E+03"},
+		{Format{Width: 5, Digits: 1}, 99999.96, "  9.999996E+04"},
+		{Format{Width: 6, Digits: 2}, -12345, " -1.234500E+04"},
+		{Format{Width: 2, Digits: 2}, 1, "  1.000000E+00"},
+		{Format{E: true}, 120, "  1.200000E+02"},
+		{Format{E: true}, -.5, " -5.000000E-01"},
+		// Non-finite numbers keep the one sign column.
+		{Format{Width: 3}, math.Inf(1), "  Inf"},
+		{Format{Width: 7, Digits: 2}, math.Inf(-1), " -Inf"},
+		{Format{E: true}, math.NaN(), "  NaN"},
+	}
+	for _, tt := range tests {
+		if got := tt.f.Sprint(tt.x); got != tt.want {
+			t.Errorf("%v %g: want %q, got %q", tt.f, tt.x, tt.want, got)
+		}
+	}
+}
+
+func TestString(t *testing.T) {
+	for f, want := range map[Format]string{
+		{Width: 3}:            "%3",
+		{Width: 7, Digits: 2}: "%7.02",
+		{E: true}:             "%",
+		Default:               "%8.04",
+	} {
+		if got := f.String(); got != want {
+			t.Errorf("want %s, got %s", want, got)
+		}
+	}
+}
+
+// TestWriter types lines 02.10 to 02.70 and 05.20 of lunar-lander.fc.
+func TestWriter(t *testing.T) {
+	var b strings.Builder
+	w := NewWriter(&b)
+	if w.Format() != Default {
+		t.Errorf("want %v, got %v", Default, w.Format())
+	}
+	w.Type("    ", Format{Width: 3}, 140.0, "       ", 0.0, "  ", Format{Width: 4}, 5040.0)
+	w.Type(Format{Width: 6, Digits: 2}, "       ", 556.96, "    ", Format{Width: 6, Digits: 1}, 2300.0, "      K=:")
+	w.Type(Format{Width: 7, Digits: 2})
+	w.Type("FUEL LEFT:", 277.6, " LBS\n")
+	want := "      140           0    5040         556.96      2300.0      K=:" +
+		"FUEL LEFT:   277.60 LBS\n"
+	if b.String() != want {
+		t.Errorf("want\n%q\ngot\n%q", want, b.String())
+	}
+	if w.Err() != nil {
+		t.Error(w.Err())
+	}
+}
+
+type failing struct{}
+
+func (failing) Write([]byte) (int, error) { return 0, errors.New("paper out") }
+
+func TestWriterErr(t *testing.T) {
+	w := NewWriter(failing{})
+	w.Type("A", 1.0, "B")
+	if w.Err() == nil || w.Err().Error() != "paper out" {
+		t.Errorf("want paper out, got %v", w.Err())
+	}
+}
+
+func TestWriterPanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("want a panic for an int")
+		}
+	}()
+	NewWriter(new(strings.Builder)).Type(1)
+}
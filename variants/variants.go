@@ -0,0 +1,242 @@
+// Package variants holds the listings of the 1969 lunar lander that float
+// around, each with where it comes from. Diff tells which of their
+// differences are mere spelling, which change what the teletype prints and
+// which change the computation; Run flies a listing in the interpreter of
+// package focal to see whether a change alters the landing.
+//
+// All listings share the line numbers of lunar-lander.fc.
+package variants
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"gitlab.com/jhinrichsen/lunar-lander/focal"
+)
+
+//go:embed listings/*.fc
+var listings embed.FS
+
+func listing(name string) string {
+	b, err := listings.ReadFile("listings/" + name)
+	if err != nil {
+		panic(err)
+	}
+	return string(b)
+}
+
+// Variant is a listing and its provenance.
+type Variant struct {
+	Name   string // short name for the command line
+	Source string // where the text was taken from
+	Notes  string
+	Text   string
+}
+
+// Program parses the listing.
+func (v *Variant) Program() (*focal.Program, error) {
+	p, err := focal.Parse(strings.NewReader(v.Text))
+	if err != nil {
+		return nil, fmt.Errorf("%s:%w", v.Name, err)
+	}
+	return p, nil
+}
+
+// All holds the known listings, the one the repository runs first.
+var All = []*Variant{
+	{
+		Name:   "original",
+		Source: "lunar-lander.fc",
+		Notes: "The listing the ports and tools of this repository run. Commas " +
+			"separate all TYPE items, 03.40 carries a redundant pair of " +
+			"parentheses and 08.10 divides by (Z*K).",
+		Text: listing("original.fc"),
+	},
+	{
+		Name:   "prompt",
+		Source: "PROMPT.md, Original FOCAL Source Code",
+		Notes: "The listing the agents were given. TIME=120 and WEIGHT=32500 in " +
+			"01.11, no ! after the verdicts of 05.50 to 05.81 and 08.10 " +
+			"computes M*G/Z*K, which package focal reads as (M*G/Z)*K: " +
+			"flown that way every landing ends in a crater.",
+		Text: listing("prompt.fc"),
+	},
+	{
+		Name:   "scan",
+		Source: "doc/LunarLanderListing.jpg",
+		Notes: "Transcribed from the scan of the teletype listing headed " +
+			"C-FOCAL,1969; ↑ is written ^. Like prompt but with TIME-120 " +
+			"and WEIGHT-32500.",
+		Text: listing("scan.fc"),
+	},
+}
+
+// Lookup returns the variant called name or nil.
+func Lookup(name string) *Variant {
+	for _, v := range All {
+		if v.Name == name {
+			return v
+		}
+	}
+	return nil
+}
+
+// Kind classifies a difference between two versions of a line.
+type Kind int
+
+const (
+	// Spelling: the same statements, written differently, such as
+	// redundant parentheses or commas between TYPE items.
+	Spelling Kind = iota
+	// Output: the line prints different text, numbers or formats but
+	// computes and branches the same.
+	Output
+	// Code: the line computes or branches differently, or exists in one
+	// listing only.
+	Code
+)
+
+func (k Kind) String() string {
+	switch k {
+	case Spelling:
+		return "spelling"
+	case Output:
+		return "output"
+	case Code:
+		return "code"
+	}
+	return fmt.Sprintf("Kind(%d)", int(k))
+}
+
+// Difference is a line that differs between two programs.
+type Difference struct {
+	Line focal.Num
+	Kind Kind
+	A, B *focal.Line // nil if the line is missing
+}
+
+// Diff returns the lines that differ between a and b in line order.
+func Diff(a, b *focal.Program) []Difference {
+	var ds []Difference
+	i, j := 0, 0
+	for i < len(a.Lines) || j < len(b.Lines) {
+		var la, lb *focal.Line
+		switch {
+		case j == len(b.Lines) || i < len(a.Lines) && a.Lines[i].Num < b.Lines[j].Num:
+			la = a.Lines[i]
+			i++
+		case i == len(a.Lines) || b.Lines[j].Num < a.Lines[i].Num:
+			lb = b.Lines[j]
+			j++
+		default:
+			la, lb = a.Lines[i], b.Lines[j]
+			i++
+			j++
+		}
+		if la == nil || lb == nil {
+			n := la
+			if n == nil {
+				n = lb
+			}
+			ds = append(ds, Difference{n.Num, Code, la, lb})
+			continue
+		}
+		if la.Source == lb.Source {
+			continue
+		}
+		ds = append(ds, Difference{la.Num, kind(la.Stmts, lb.Stmts), la, lb})
+	}
+	return ds
+}
+
+// kind compares two statement lists.
+func kind(a, b []focal.Stmt) Kind {
+	if len(a) != len(b) {
+		return Code
+	}
+	k := Spelling
+	for i := range a {
+		if a[i].String() == b[i].String() {
+			continue
+		}
+		if !sameCode(a[i], b[i]) {
+			return Code
+		}
+		k = Output
+	}
+	return k
+}
+
+// sameCode reports whether two TYPE or two ASK statements evaluate and set
+// the same things in the same order, whatever else they print.
+func sameCode(a, b focal.Stmt) bool {
+	switch a := a.(type) {
+	case *focal.Type:
+		b, ok := b.(*focal.Type)
+		return ok && code(a.Items) == code(b.Items)
+	case *focal.Ask:
+		b, ok := b.(*focal.Ask)
+		return ok && code(a.Items) == code(b.Items)
+	}
+	return false
+}
+
+// code returns the expressions of a TYPE or ASK list.
+func code(items []focal.Item) string {
+	var xs []string
+	for _, it := range items {
+		if x, ok := it.(focal.Expr); ok {
+			xs = append(xs, x.String())
+		}
+	}
+	return strings.Join(xs, ",")
+}
+
+// Landing is how a flight ended.
+type Landing struct {
+	Time     float64   // L, seconds
+	Velocity float64   // W, miles per hour
+	Fuel     float64   // M-N, lbs
+	Verdict  focal.Num // the line of 05.40 to 05.82 that judged it
+}
+
+// Result is a session of a listing.
+type Result struct {
+	Landings []Landing
+	Output   string
+}
+
+// firstVerdict and lastVerdict bound the lines 05.40–05.82 that judge a landing.
+const firstVerdict, lastVerdict = 540, 582
+
+// Run runs p on the ASK answers of in, recording every landing at 05.20.
+// Running out of answers ends the session without an error.
+func Run(p *focal.Program, in io.Reader) (*Result, error) {
+	var out strings.Builder
+	r := &Result{}
+	it := focal.NewInterp(p, focal.NewTerminal(in, &out))
+	for !it.Done() {
+		n, s, _ := it.Pos()
+		if n == 520 && it.AtLineStart() {
+			r.Landings = append(r.Landings, Landing{
+				Time:     it.Var("L"),
+				Velocity: it.Var("W"),
+				Fuel:     it.Var("M") - it.Var("N"),
+			})
+		}
+		if _, ok := s.(*focal.Type); ok && n >= firstVerdict && n <= lastVerdict && len(r.Landings) > 0 {
+			r.Landings[len(r.Landings)-1].Verdict = n
+		}
+		if err := it.Step(); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, err
+		}
+	}
+	r.Output = out.String()
+	return r, nil
+}
@@ -0,0 +1,219 @@
+package variants
+
+import (
+	"bufio"
+	"os"
+	"regexp"
+	"strings"
+	"testing"
+
+	"gitlab.com/jhinrichsen/lunar-lander/focal"
+)
+
+// numbered returns the numbered lines of a text.
+func numbered(text string) []string {
+	re := regexp.MustCompile(`^\d\d\.\d\d `)
+	var ls []string
+	sc := bufio.NewScanner(strings.NewReader(text))
+	for sc.Scan() {
+		if re.MatchString(sc.Text()) {
+			ls = append(ls, sc.Text())
+		}
+	}
+	return ls
+}
+
+func TestSources(t *testing.T) {
+	for name, file := range map[string]string{
+		"original": "../lunar-lander.fc",
+		"prompt":   "../PROMPT.md",
+	} {
+		b, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want, got := numbered(string(b)), numbered(Lookup(name).Text)
+		if strings.Join(got, "\n") != strings.Join(want, "\n") {
+			t.Errorf("%s: listing differs from %s", name, file)
+		}
+	}
+}
+
+func TestAll(t *testing.T) {
+	for _, v := range All {
+		if Lookup(v.Name) != v {
+			t.Errorf("Lookup(%q) = %v", v.Name, Lookup(v.Name))
+		}
+		if v.Source == "" || v.Notes == "" {
+			t.Errorf("%s: missing provenance", v.Name)
+		}
+		p, err := v.Program()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(p.Lines) != 39 {
+			t.Errorf("%s: want 39 lines, got %d", v.Name, len(p.Lines))
+		}
+	}
+	if Lookup("basic") != nil {
+		t.Error("want nil for an unknown variant")
+	}
+}
+
+func program(t *testing.T, name string) *focal.Program {
+	t.Helper()
+	p, err := Lookup(name).Program()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func TestDiff(t *testing.T) {
+	tests := []struct {
+		b    string
+		want map[focal.Num]Kind
+	}{
+		{"prompt", map[focal.Num]Kind{
+			111: Output, 210: Output, 220: Spelling, 340: Spelling,
+			410: Spelling, 510: Spelling, 520: Output, 550: Output,
+			560: Output, 570: Output, 581: Output, 583: Output, 810: Code,
+		}},
+		{"scan", map[focal.Num]Kind{
+			210: Output, 220: Spelling, 340: Spelling,
+			410: Spelling, 510: Spelling, 520: Output, 550: Output,
+			560: Output, 570: Output, 581: Output, 583: Output, 810: Code,
+		}},
+	}
+	for _, tt := range tests {
+		ds := Diff(program(t, "original"), program(t, tt.b))
+		got := map[focal.Num]Kind{}
+		for _, d := range ds {
+			got[d.Line] = d.Kind
+		}
+		if len(got) != len(tt.want) {
+			t.Errorf("%s: want %d differences, got %v", tt.b, len(tt.want), got)
+		}
+		for n, k := range tt.want {
+			if got[n] != k {
+				t.Errorf("%s: %s: want %s, got %s", tt.b, n, k, got[n])
+			}
+		}
+	}
+}
+
+func TestDiffLines(t *testing.T) {
+	parse := func(s string) *focal.Program {
+		p, err := focal.Parse(strings.NewReader(s))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return p
+	}
+	a := parse("01.10 S A=1\n01.20 T A\n01.30 Q\n")
+	b := parse("01.10 S A=(1)\n01.25 T A\n01.30 Q;T A\n")
+	ds := Diff(a, b)
+	want := []struct {
+		n    focal.Num
+		k    Kind
+		a, b bool
+	}{
+		{110, Spelling, true, true},
+		{120, Code, true, false},
+		{125, Code, false, true},
+		{130, Code, true, true},
+	}
+	if len(ds) != len(want) {
+		t.Fatalf("want %d differences, got %v", len(want), ds)
+	}
+	for i, w := range want {
+		d := ds[i]
+		if d.Line != w.n || d.Kind != w.k || (d.A != nil) != w.a || (d.B != nil) != w.b {
+			t.Errorf("%d: want %v, got %s %s %v %v", i, w, d.Line, d.Kind, d.A, d.B)
+		}
+	}
+	if len(Diff(a, a)) != 0 {
+		t.Error("want no differences of a program with itself")
+	}
+}
+
+func TestKindString(t *testing.T) {
+	for k, want := range map[Kind]string{Spelling: "spelling", Output: "output", Code: "code", 7: "Kind(7)"} {
+		if k.String() != want {
+			t.Errorf("want %q, got %q", want, k)
+		}
+	}
+}
+
+func run(t *testing.T, name, input string) *Result {
+	t.Helper()
+	f, err := os.Open(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	r, err := Run(program(t, name), f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
+
+func TestRun(t *testing.T) {
+	r := run(t, "original", "../lunar/testdata/perfect.in")
+	want, err := os.ReadFile("../lunar/testdata/perfect.out")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Output != string(want) {
+		t.Errorf("want\n%s\ngot\n%s", want, r.Output)
+	}
+	if len(r.Landings) != 1 {
+		t.Fatalf("want 1 landing, got %v", r.Landings)
+	}
+	l := r.Landings[0]
+	if l.Verdict != 540 || l.Velocity > 1 || l.Fuel < 277 || l.Time < 190 || l.Time > 191 {
+		t.Errorf("want a perfect landing at 190 secs with 277 lbs left, got %+v", l)
+	}
+}
+
+// TestRunVariants shows that the 08.10 of prompt and scan, M*G/Z*K, turns
+// every landing of the original into a crater.
+func TestRunVariants(t *testing.T) {
+	for _, in := range []string{"perfect", "good", "crash"} {
+		input := "../lunar/testdata/" + in + ".in"
+		o := run(t, "original", input)
+		for _, name := range []string{"prompt", "scan"} {
+			r := run(t, name, input)
+			if len(r.Landings) != len(o.Landings) {
+				t.Fatalf("%s %s: want %d landings, got %v", name, in, len(o.Landings), r.Landings)
+			}
+			for i, l := range r.Landings {
+				if l.Verdict != 582 || l.Fuel >= 0 || l.Velocity <= o.Landings[i].Velocity {
+					t.Errorf("%s %s: want a crater on negative fuel, got %+v", name, in, l)
+				}
+			}
+		}
+	}
+}
+
+func TestRunEnds(t *testing.T) {
+	p, err := focal.Parse(strings.NewReader("01.10 A K;T K\n01.20 G 1.1\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := Run(p, strings.NewReader("1\n2\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(r.Landings) != 0 {
+		t.Errorf("want no landings, got %v", r.Landings)
+	}
+	p, err = focal.Parse(strings.NewReader("01.10 G 2.1\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Run(p, strings.NewReader("")); err == nil {
+		t.Error("want an error for a missing line")
+	}
+}